@@ -0,0 +1,47 @@
+package format
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/grafana/jfr-parser/common/anonymize"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterAnonymize struct {
+	options anonymize.Options
+	mapping string
+}
+
+// NewFormatterAnonymize rewrites the recording with its proprietary names,
+// threads, variables, properties, paths and hosts hashed, and writes the
+// mapping of the hashes to mapping, or next to the recording when empty.
+func NewFormatterAnonymize(options anonymize.Options, mapping string) *formatterAnonymize {
+	return &formatterAnonymize{options: options, mapping: mapping}
+}
+
+// Format writes the anonymized recording, uncompressed, and the mapping.
+func (f *formatterAnonymize) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	rc, err := parser.Decompress(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rc.Close()
+	recording, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, mapping, err := anonymize.Anonymize(recording, f.options)
+	if err != nil {
+		return nil, nil, err
+	}
+	var js bytes.Buffer
+	if err := mapping.WriteJSON(&js); err != nil {
+		return nil, nil, err
+	}
+	mappingDest := f.mapping
+	if mappingDest == "" {
+		mappingDest = dest + ".mapping.json"
+	}
+	return []string{dest, mappingDest}, [][]byte{out, js.Bytes()}, nil
+}
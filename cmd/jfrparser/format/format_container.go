@@ -0,0 +1,37 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/container"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterContainer struct {
+	json bool
+}
+
+// NewFormatterContainer writes the container report as text, or as JSON.
+func NewFormatterContainer(json bool) *formatterContainer {
+	return &formatterContainer{json: json}
+}
+
+// Format writes the cgroup limits and usage of the container, and the CPU
+// samples taken while it was throttled.
+func (f *formatterContainer) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	p := parser.NewParser(buf, parser.Options{})
+	report, err := container.FromParser(p, container.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.json {
+		err = report.WriteJSON(&out)
+	} else {
+		err = report.WriteText(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
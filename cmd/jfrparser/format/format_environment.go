@@ -0,0 +1,34 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/environment"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterEnvironment struct{}
+
+// NewFormatterEnvironment writes the environment of the recording as JSON,
+// with the secrets redacted.
+func NewFormatterEnvironment() *formatterEnvironment {
+	return &formatterEnvironment{}
+}
+
+// Format writes the JVM, OS, CPU, flags, system properties and environment
+// variables of the recording.
+func (f *formatterEnvironment) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := environment.FromChunks(chunks, environment.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if err := env.WriteJSON(&out); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
@@ -0,0 +1,29 @@
+package format
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/exceptions"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterExceptions struct{}
+
+func NewFormatterExceptions() *formatterExceptions {
+	return &formatterExceptions{}
+}
+
+// Format writes the thrown exceptions per minute, by class and message prefix.
+func (f *formatterExceptions) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	p := parser.NewParser(buf, parser.Options{})
+	report, err := exceptions.FromParser(p, exceptions.Options{BucketDuration: time.Minute})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
@@ -0,0 +1,39 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/gc"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterGC struct {
+	json bool
+}
+
+// NewFormatterGC writes the GC report as text tables, or as JSON.
+func NewFormatterGC(json bool) *formatterGC {
+	return &formatterGC{json: json}
+}
+
+// Format writes the collectors, pauses and heap occupancy of the recording.
+func (f *formatterGC) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := gc.FromChunks(chunks, gc.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.json {
+		err = report.WriteJSON(&out)
+	} else {
+		err = report.WriteText(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
@@ -0,0 +1,40 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/jit"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterJIT struct {
+	json bool
+}
+
+// NewFormatterJIT writes the JIT report as text tables, or as JSON.
+func NewFormatterJIT(json bool) *formatterJIT {
+	return &formatterJIT{json: json}
+}
+
+// Format writes the compilations, deoptimizations and code cache occupancy
+// of the recording.
+func (f *formatterJIT) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := jit.FromChunks(chunks, jit.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.json {
+		err = report.WriteJSON(&out)
+	} else {
+		err = report.WriteText(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
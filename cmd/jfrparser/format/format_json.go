@@ -77,6 +77,10 @@ func (f *formatterJson) Format(buf []byte, dest string) ([]string, [][]byte, err
 			ir[chunkIdx].Recordings = append(ir[chunkIdx].Recordings, p.ThreadPark)
 		case p.TypeMap.T_LIVE_OBJECT:
 			ir[chunkIdx].Recordings = append(ir[chunkIdx].Recordings, p.LiveObject)
+		case p.TypeMap.T_MALLOC:
+			ir[chunkIdx].Recordings = append(ir[chunkIdx].Recordings, p.Malloc)
+		case p.TypeMap.T_FREE:
+			ir[chunkIdx].Recordings = append(ir[chunkIdx].Recordings, p.Free)
 		case p.TypeMap.T_ACTIVE_SETTING:
 			ir[chunkIdx].Recordings = append(ir[chunkIdx].Recordings, p.ActiveSetting)
 		}
@@ -0,0 +1,37 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/leaks"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterLeaks struct {
+	json bool
+}
+
+// NewFormatterLeaks writes the memory leak report as text, or as JSON.
+func NewFormatterLeaks(json bool) *formatterLeaks {
+	return &formatterLeaks{json: json}
+}
+
+// Format writes the old object samples by allocation site and GC root type,
+// with the reference chain of the oldest object of each group.
+func (f *formatterLeaks) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	p := parser.NewParser(buf, parser.Options{})
+	report, err := leaks.FromParser(p, leaks.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.json {
+		err = report.WriteJSON(&out)
+	} else {
+		err = report.WriteText(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
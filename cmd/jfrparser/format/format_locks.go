@@ -0,0 +1,28 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/locks"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterLocks struct{}
+
+func NewFormatterLocks() *formatterLocks {
+	return &formatterLocks{}
+}
+
+// Format writes the contended monitors and park blockers, by total wait.
+func (f *formatterLocks) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	p := parser.NewParser(buf, parser.Options{})
+	report, err := locks.FromParser(p, locks.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
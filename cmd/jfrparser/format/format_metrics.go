@@ -0,0 +1,41 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/metrics"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterMetrics struct {
+	csv bool
+}
+
+// NewFormatterMetrics writes the series of the periodic events in the
+// OpenMetrics text format, or as CSV.
+func NewFormatterMetrics(csv bool) *formatterMetrics {
+	return &formatterMetrics{csv: csv}
+}
+
+// Format writes the timestamped samples of the CPU load, heap, memory,
+// class loading, thread and network series of the recording.
+func (f *formatterMetrics) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	export, err := metrics.FromChunks(chunks, metrics.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.csv {
+		err = export.WriteCSV(&out)
+	} else {
+		err = export.WriteOpenMetrics(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
@@ -3,7 +3,6 @@ package format
 import (
 	"fmt"
 	"path/filepath"
-	"time"
 
 	"github.com/grafana/jfr-parser/pprof"
 )
@@ -15,12 +14,7 @@ func NewFormatterPprof() *formatterPprof {
 }
 
 func (f *formatterPprof) Format(buf []byte, dest string) ([]string, [][]byte, error) {
-	pi := &pprof.ParseInput{
-		StartTime:  time.Now(),
-		EndTime:    time.Now(),
-		SampleRate: 100,
-	}
-	profiles, err := pprof.ParseJFR(buf, pi, nil)
+	profiles, err := pprof.ParseJFR(buf, &pprof.ParseInput{}, nil)
 	if err != nil {
 		return nil, nil, err
 	}
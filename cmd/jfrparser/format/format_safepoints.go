@@ -0,0 +1,40 @@
+package format
+
+import (
+	"bytes"
+
+	"github.com/grafana/jfr-parser/common/safepoints"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type formatterSafepoints struct {
+	json bool
+}
+
+// NewFormatterSafepoints writes the safepoint report as text tables, or as JSON.
+func NewFormatterSafepoints(json bool) *formatterSafepoints {
+	return &formatterSafepoints{json: json}
+}
+
+// Format writes the time to safepoint and the VM operation time of the
+// safepoints, per operation type and as a timeline.
+func (f *formatterSafepoints) Format(buf []byte, dest string) ([]string, [][]byte, error) {
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := safepoints.FromChunks(chunks, safepoints.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var out bytes.Buffer
+	if f.json {
+		err = report.WriteJSON(&out)
+	} else {
+		err = report.WriteText(&out)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{dest}, [][]byte{out.Bytes()}, nil
+}
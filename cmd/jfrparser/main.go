@@ -7,11 +7,14 @@ import (
 	"strings"
 
 	"github.com/grafana/jfr-parser/cmd/jfrparser/format"
+	"github.com/grafana/jfr-parser/common/anonymize"
 )
 
 type command struct {
 	// Opts
-	format string
+	format    string
+	anonymize *anonymize.Options
+	mapping   string
 
 	// Args
 	src  string
@@ -19,11 +22,20 @@ type command struct {
 }
 
 func parseCommand(c *command) {
-	format := flag.String("format", "json", "output format. Supported formats: json, pprof")
+	if len(os.Args) > 1 && os.Args[1] == "anonymize" {
+		parseAnonymize(c, os.Args[2:])
+		return
+	}
+	format := flag.String("format", "json", "output format. Supported formats: json, pprof, exceptions, locks, gc, gc-json, jit, jit-json, safepoints, safepoints-json, leaks, leaks-json, environment, openmetrics, metrics-csv, container, container-json")
 	flag.Parse()
 	c.format = strings.ToLower(*format)
 
 	args := flag.Args()
+	if len(args) < 1 {
+		fmt.Fprintln(flag.CommandLine.Output(), "missing the path of the recording")
+		flag.Usage()
+		os.Exit(2)
+	}
 	c.src = args[0]
 	if len(args) < 2 {
 		c.dest = fmt.Sprintf("%s.%s", c.src, c.format)
@@ -32,12 +44,64 @@ func parseCommand(c *command) {
 	}
 }
 
+// parseAnonymize parses the options of the anonymize command.
+func parseAnonymize(c *command, args []string) {
+	flags := flag.NewFlagSet("anonymize", flag.ExitOnError)
+	prefixes := flags.String("prefixes", "", "comma separated packages of the proprietary code, such as com.example")
+	salt := flags.String("salt", "", "salt of the hashes, keep it to anonymize other recordings with the same hashes")
+	keep := flags.String("keep", "", "comma separated values to keep. Supported values: threads, environment, properties, paths, hosts")
+	mapping := flags.String("mapping", "", "destination of the mapping of the hashes, /path/to/dest.mapping.json by default")
+	flags.Parse(args)
+
+	o := &anonymize.Options{Salt: *salt}
+	for _, p := range strings.Split(*prefixes, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			o.Prefixes = append(o.Prefixes, p)
+		}
+	}
+	for _, k := range strings.Split(*keep, ",") {
+		switch strings.TrimSpace(k) {
+		case "threads":
+			o.KeepThreadNames = true
+		case "environment":
+			o.KeepEnvironment = true
+		case "properties":
+			o.KeepSystemProperties = true
+		case "paths":
+			o.KeepPaths = true
+		case "hosts":
+			o.KeepHosts = true
+		case "":
+		default:
+			panic(fmt.Errorf("unsupported value to keep: %s", k))
+		}
+	}
+	c.format = "anonymize"
+	c.anonymize = o
+	c.mapping = *mapping
+
+	args = flags.Args()
+	if len(args) < 1 {
+		fmt.Fprintln(flags.Output(), "missing the path of the recording")
+		flags.Usage()
+		os.Exit(2)
+	}
+	c.src = args[0]
+	if len(args) < 2 {
+		c.dest = fmt.Sprintf("%s.anonymized.jfr", c.src)
+	} else {
+		c.dest = args[1]
+	}
+}
+
 type formatter interface {
 	// Formats the given JFR
 	Format(buf []byte, dest string) ([]string, [][]byte, error)
 }
 
 // Usage: ./jfrparser [options] /path/to/jfr [/path/to/dest]
+//
+//	./jfrparser anonymize [options] /path/to/jfr [/path/to/dest]
 func main() {
 	c := new(command)
 	parseCommand(c)
@@ -53,6 +117,38 @@ func main() {
 		fmtr = format.NewFormatterJson()
 	case "pprof":
 		fmtr = format.NewFormatterPprof()
+	case "exceptions":
+		fmtr = format.NewFormatterExceptions()
+	case "locks":
+		fmtr = format.NewFormatterLocks()
+	case "gc":
+		fmtr = format.NewFormatterGC(false)
+	case "gc-json":
+		fmtr = format.NewFormatterGC(true)
+	case "jit":
+		fmtr = format.NewFormatterJIT(false)
+	case "jit-json":
+		fmtr = format.NewFormatterJIT(true)
+	case "safepoints":
+		fmtr = format.NewFormatterSafepoints(false)
+	case "safepoints-json":
+		fmtr = format.NewFormatterSafepoints(true)
+	case "leaks":
+		fmtr = format.NewFormatterLeaks(false)
+	case "leaks-json":
+		fmtr = format.NewFormatterLeaks(true)
+	case "environment":
+		fmtr = format.NewFormatterEnvironment()
+	case "openmetrics":
+		fmtr = format.NewFormatterMetrics(false)
+	case "metrics-csv":
+		fmtr = format.NewFormatterMetrics(true)
+	case "container":
+		fmtr = format.NewFormatterContainer(false)
+	case "container-json":
+		fmtr = format.NewFormatterContainer(true)
+	case "anonymize":
+		fmtr = format.NewFormatterAnonymize(*c.anonymize, c.mapping)
 	default:
 		panic("unsupported format")
 	}
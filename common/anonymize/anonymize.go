@@ -0,0 +1,209 @@
+// Package anonymize rewrites recordings to share them outside of the
+// company: the names of the proprietary classes, packages and methods, the
+// thread names, the environment variables, the system properties, the file
+// paths and the socket hosts are replaced by hashes. The structure of the
+// recording, the JDK frames and the timings are kept, and the mapping of the
+// hashes to the original values translates the findings on the anonymized
+// recording back.
+package anonymize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type Options struct {
+	// Prefixes are the packages of the proprietary code, such as
+	// com.example, in the dotted or the internal form.
+	Prefixes []string
+	// Salt is hashed with the values, the recordings anonymized with the
+	// same salt share their hashes.
+	Salt string
+
+	KeepThreadNames      bool
+	KeepEnvironment      bool
+	KeepSystemProperties bool
+	KeepPaths            bool
+	KeepHosts            bool
+}
+
+// Mapping maps the hashes of an anonymized recording to the original
+// values.
+type Mapping map[string]string
+
+// ReadMapping reads a mapping written by WriteJSON.
+func ReadMapping(r io.Reader) (Mapping, error) {
+	m := make(Mapping)
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m Mapping) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(m)
+}
+
+// hash matches the hashes of all the kinds of values: C and p for the
+// segments of class and package names, m for methods, t for threads, v for
+// the values of the variables and properties, f for the segments of file
+// paths and h for hosts.
+var hash = regexp.MustCompile(`\b[Cpmtvfh][0-9a-f]{10}\b`)
+
+// Translate replaces the hashes of s with the original values, to read a
+// stack trace or a report of the anonymized recording.
+func (m Mapping) Translate(s string) string {
+	return hash.ReplaceAllStringFunc(s, func(h string) string {
+		if v, ok := m[h]; ok {
+			return v
+		}
+		return h
+	})
+}
+
+type anonymizer struct {
+	options Options
+	// names matches the names of the proprietary classes and packages in
+	// any string, such as a descriptor or an exception message.
+	names   *regexp.Regexp
+	mapping Mapping
+	err     error
+}
+
+func newAnonymizer(options Options) *anonymizer {
+	a := &anonymizer{options: options, mapping: make(Mapping)}
+	var prefixes []string
+	for _, p := range options.Prefixes {
+		p = strings.Trim(p, "./")
+		if p == "" {
+			continue
+		}
+		// the dotted and the internal form
+		prefixes = append(prefixes, strings.ReplaceAll(regexp.QuoteMeta(strings.ReplaceAll(p, "/", ".")), `\.`, `[./]`))
+	}
+	if len(prefixes) > 0 {
+		// a name starts the string or follows a separator, or the L of an
+		// object type in a descriptor
+		a.names = regexp.MustCompile(`(?:^|[^\w$./]|[\[(;]L)((?:` + strings.Join(prefixes, "|") + `)(?:[./$][\w$./]*)?)`)
+	}
+	return a
+}
+
+// token returns the hash of s, prefixed by the kind of the value.
+func (a *anonymizer) token(kind, s string) string {
+	sum := sha256.Sum256([]byte(a.options.Salt + "\x00" + kind + "\x00" + s))
+	t := kind + hex.EncodeToString(sum[:5])
+	if v, ok := a.mapping[t]; ok && v != s && a.err == nil {
+		a.err = fmt.Errorf("hash collision between %q and %q, change the salt", v, s)
+	}
+	a.mapping[t] = s
+	return t
+}
+
+// proprietary tells if name is a class or package of the proprietary code.
+func (a *anonymizer) proprietary(name string) bool {
+	if a.names == nil {
+		return false
+	}
+	loc := a.names.FindStringSubmatchIndex(name)
+	return loc != nil && loc[2] == 0 && !identifier(name, loc[3])
+}
+
+// identifier tells if the character at i of s continues an identifier, the
+// name matched before it is then only a prefix of a longer name.
+func identifier(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// replaceNames hashes the segments of the proprietary class and package
+// names in s, keeping the separators.
+func (a *anonymizer) replaceNames(s string) string {
+	if a.names == nil {
+		return s
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range a.names.FindAllStringSubmatchIndex(s, -1) {
+		start, end := loc[2], loc[3]
+		if identifier(s, end) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(a.segments(s[start:end], "./$", a.nameSegment))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// nameSegment keeps the numbers of the anonymous classes and tells the
+// classes from the packages by the case.
+func (a *anonymizer) nameSegment(s string) string {
+	if strings.Trim(s, "0123456789") == "" {
+		return s
+	}
+	if s[0] >= 'A' && s[0] <= 'Z' {
+		return a.token("C", s)
+	}
+	return a.token("p", s)
+}
+
+func (a *anonymizer) path(s string) string {
+	return a.segments(s, `/\`, func(s string) string {
+		return a.token("f", s)
+	})
+}
+
+// segments replaces the segments of s between the separators.
+func (a *anonymizer) segments(s, separators string, replace func(string) string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexAny(s, separators)
+		if i < 0 {
+			break
+		}
+		if i > 0 {
+			b.WriteString(replace(s[:i]))
+		}
+		b.WriteByte(s[i])
+		s = s[i+1:]
+	}
+	if s != "" {
+		b.WriteString(replace(s))
+	}
+	return b.String()
+}
+
+// Anonymize rewrites a recording and returns the mapping of its hashes.
+func Anonymize(recording []byte, options Options) ([]byte, Mapping, error) {
+	a := newAnonymizer(options)
+	var out []byte
+	for pos := 0; pos < len(recording); {
+		chunk, size, err := a.chunk(recording[pos:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("chunk at %d: %w", pos, err)
+		}
+		out = append(out, chunk...)
+		pos += size
+	}
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+	return out, a.mapping, nil
+}
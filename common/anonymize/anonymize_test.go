@@ -0,0 +1,272 @@
+package anonymize
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymize(t *testing.T) {
+	buf, err := os.ReadFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	out, mapping, err := Anonymize(buf, Options{Prefixes: []string{"movies"}})
+	require.NoError(t, err)
+
+	assert.False(t, bytes.Contains(out, []byte("movies/Server")))
+	assert.False(t, bytes.Contains(out, []byte("loadMovies")))
+	assert.False(t, bytes.Contains(out, []byte("qtp640059078")))
+	assert.True(t, bytes.Contains(out, []byte("java/time/format/DateTimeFormatter")))
+	assert.Equal(t, "movies/Server.loadMovies", mapping.Translate(
+		mapping.token(t, "p", "movies")+"/"+mapping.token(t, "C", "Server")+"."+mapping.token(t, "m", "loadMovies")))
+
+	original, err := parser.Parse(bytes.NewReader(buf))
+	require.NoError(t, err)
+	anonymized, err := parser.Parse(bytes.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, anonymized, len(original))
+	for i := range original {
+		require.Len(t, anonymized[i].ChunkEvents, len(original[i].ChunkEvents))
+		for name, c := range original[i].ChunkEvents {
+			assert.Len(t, anonymized[i].ChunkEvents[name].Events, len(c.Events), name)
+		}
+		samples, anonymizedSamples := original[i].Apply(filters.DatadogExecutionSample), anonymized[i].Apply(filters.DatadogExecutionSample)
+		for j := range samples {
+			assert.Equal(t, samples[j].Attributes["startTime"], anonymizedSamples[j].Attributes["startTime"])
+		}
+	}
+
+	again, againMapping, err := Anonymize(buf, Options{Prefixes: []string{"movies"}})
+	require.NoError(t, err)
+	assert.Equal(t, out, again)
+	assert.Equal(t, mapping, againMapping)
+}
+
+// token finds the hash of a value in the mapping.
+func (m Mapping) token(t *testing.T, kind, value string) string {
+	for k, v := range m {
+		if v == value && k[:1] == kind {
+			return k
+		}
+	}
+	t.Fatalf("no hash of %s", value)
+	return ""
+}
+
+func TestKeepAll(t *testing.T) {
+	buf, err := os.ReadFile("../../parser/testdata/prof.jfr")
+	require.NoError(t, err)
+	// the testdata recording is compressed
+	rc, err := parser.Decompress(bytes.NewReader(buf))
+	require.NoError(t, err)
+	var raw bytes.Buffer
+	_, err = raw.ReadFrom(rc)
+	require.NoError(t, err)
+
+	out, mapping, err := Anonymize(raw.Bytes(), Options{KeepThreadNames: true, KeepEnvironment: true, KeepSystemProperties: true, KeepPaths: true, KeepHosts: true})
+	require.NoError(t, err)
+	assert.Empty(t, mapping)
+
+	original, err := parser.Parse(bytes.NewReader(raw.Bytes()))
+	require.NoError(t, err)
+	rewritten, err := parser.Parse(bytes.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, rewritten, len(original))
+	for i := range original {
+		// only the size and offsets of the chunk change, without the padding
+		// of the event sizes
+		assert.NotEqual(t, original[i].Header.ChunkSize, rewritten[i].Header.ChunkSize)
+		assert.Equal(t, original[i].Header.StartTicks, rewritten[i].Header.StartTicks)
+		for name, c := range original[i].ChunkEvents {
+			events := rewritten[i].ChunkEvents[name].Events
+			require.Len(t, events, len(c.Events), name)
+			for j, e := range c.Events {
+				expected, err := json.Marshal(e.Attributes)
+				require.NoError(t, err)
+				actual, err := json.Marshal(events[j].Attributes)
+				require.NoError(t, err)
+				require.Equal(t, string(expected), string(actual), name)
+			}
+		}
+	}
+}
+
+const (
+	fileRead        = 100
+	socketRead      = 101
+	environmentVar  = 102
+	systemProperty  = 103
+	errorThrow      = 104
+	executionSample = 105
+)
+
+func recording() []byte {
+	r := jfrtest.New()
+	r.EventClass(fileRead, types.FileRead,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "path", Class: jfrtest.String},
+		jfrtest.Field{Name: "bytesRead", Class: jfrtest.Long})
+	r.EventClass(socketRead, types.SocketRead,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "host", Class: jfrtest.String},
+		jfrtest.Field{Name: "address", Class: jfrtest.String},
+		jfrtest.Field{Name: "port", Class: jfrtest.Int})
+	r.EventClass(environmentVar, types.EnvironmentVariable,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "key", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	r.EventClass(systemProperty, types.SystemProperties,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "key", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	r.EventClass(errorThrow, types.ErrorsThrown,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "message", Class: jfrtest.String})
+	r.EventClass(executionSample, types.ExecutionSample,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "sampledThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true})
+
+	r.ClassConstant(1, "com/acme/billing/Invoice$1")
+	r.ClassConstant(2, "java/lang/Thread")
+	r.ClassConstant(3, "com/acmecorp/Client")
+	r.Constant(jfrtest.Symbol, 10, "charge")
+	r.Constant(jfrtest.Symbol, 11, "run")
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(1), uint64(11))
+	r.Constant(jfrtest.Method, 3, uint64(2), uint64(11))
+	r.Constant(jfrtest.Method, 4, uint64(3), uint64(11))
+	r.StackTrace(1, 1, 2, 3, 4)
+	r.Constant(jfrtest.Thread, 1, "billing-worker-1", uint64(11), "billing-worker-1", uint64(1), false)
+
+	start := uint64(jfrtest.StartTicks)
+	r.Event(fileRead, start+1, "/home/alice/invoices.csv", uint64(4096))
+	r.Event(socketRead, start+2, "db.acme.internal", "10.0.0.7", int32(5432))
+	r.Event(environmentVar, start+3, "DB_URL", "postgres://db.acme.internal/billing")
+	r.Event(systemProperty, start+4, "user.name", "alice")
+	r.Event(errorThrow, start+5, "com.acme.billing.Invoice not found in com.acmecorp.Client")
+	r.Event(executionSample, start+6, uint64(1), uint64(1))
+	return r.Bytes()
+}
+
+var (
+	path    = attributes.AttrSimple[string]("path", types.String)
+	host    = attributes.AttrSimple[string]("host", types.String)
+	address = attributes.AttrSimple[string]("address", types.String)
+	port    = attributes.AttrSimple[int64]("port", types.Int)
+	sampled = attributes.AttrSimple[*parser.Thread]("sampledThread", types.Thread)
+)
+
+func TestEvents(t *testing.T) {
+	out, mapping, err := Anonymize(recording(), Options{Prefixes: []string{"com.acme"}, Salt: "s3cr3t"})
+	require.NoError(t, err)
+	chunks, err := parser.Parse(bytes.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	chunk := chunks[0]
+	hashed := regexp.MustCompile(`^` + hash.String() + `$`)
+
+	for _, e := range chunk.Apply(filters.Types(types.FileRead)) {
+		p, err := path.GetValue(e)
+		require.NoError(t, err)
+		assert.Regexp(t, `^/f[0-9a-f]{10}/f[0-9a-f]{10}/f[0-9a-f]{10}$`, p)
+		assert.Equal(t, "/home/alice/invoices.csv", mapping.Translate(p))
+	}
+	for _, e := range chunk.Apply(filters.Types(types.SocketRead)) {
+		h, _ := host.GetValue(e)
+		a, _ := address.GetValue(e)
+		p, _ := port.GetValue(e)
+		assert.Regexp(t, hashed, h)
+		assert.Regexp(t, hashed, a)
+		assert.Equal(t, "db.acme.internal 10.0.0.7", mapping.Translate(h+" "+a))
+		assert.Equal(t, int64(5432), p)
+	}
+	for _, e := range chunk.Apply(filters.Types(types.EnvironmentVariable, types.SystemProperties)) {
+		k, _ := attributes.PropertyKey.GetValue(e)
+		v, _ := attributes.PropertyValue.GetValue(e)
+		assert.Contains(t, []string{"DB_URL", "user.name"}, k)
+		assert.Regexp(t, hashed, v)
+	}
+	for _, e := range chunk.Apply(filters.Types(types.ErrorsThrown)) {
+		m, _ := attributes.ThrowableMessage.GetValue(e)
+		assert.Regexp(t, `^p[0-9a-f]{10}\.p[0-9a-f]{10}\.p[0-9a-f]{10}\.C[0-9a-f]{10} not found in com\.acmecorp\.Client$`, m)
+		assert.Equal(t, "com.acme.billing.Invoice not found in com.acmecorp.Client", mapping.Translate(m))
+	}
+	samples := chunk.Apply(filters.Types(types.ExecutionSample))
+	require.Len(t, samples, 1)
+	thread, err := sampled.GetValue(samples[0])
+	require.NoError(t, err)
+	assert.Regexp(t, hashed, thread.JavaName)
+	assert.Equal(t, thread.JavaName, thread.OsName)
+	assert.Equal(t, "billing-worker-1", mapping[thread.JavaName])
+	stack, err := attributes.EventStacktrace.GetValue(samples[0])
+	require.NoError(t, err)
+	var frames []string
+	for _, f := range stack.Frames {
+		frames = append(frames, f.Method.Type.Name.String+"."+f.Method.Name.String)
+	}
+	require.Len(t, frames, 4)
+	// run is also a method of java.lang.Thread
+	assert.Regexp(t, `^p[0-9a-f]{10}/p[0-9a-f]{10}/p[0-9a-f]{10}/C[0-9a-f]{10}\$1\.m[0-9a-f]{10}$`, frames[0])
+	assert.Equal(t, "com/acme/billing/Invoice$1.charge", mapping.Translate(frames[0]))
+	assert.Equal(t, "com/acme/billing/Invoice$1.run", mapping.Translate(frames[1]))
+	assert.Equal(t, "java/lang/Thread.run", frames[2])
+	assert.Equal(t, "com/acmecorp/Client.run", frames[3])
+
+	original, err := parser.Parse(bytes.NewReader(recording()))
+	require.NoError(t, err)
+	for _, c := range original[0].ChunkEvents {
+		for i, e := range c.Events {
+			assert.Equal(t, e.Attributes["startTime"], chunk.ChunkEvents[c.ClassMetadata.Name].Events[i].Attributes["startTime"])
+		}
+	}
+
+	var js bytes.Buffer
+	require.NoError(t, mapping.WriteJSON(&js))
+	read, err := ReadMapping(&js)
+	require.NoError(t, err)
+	assert.Equal(t, mapping, read)
+
+	// the hashes depend on the salt
+	other, _, err := Anonymize(recording(), Options{Prefixes: []string{"com.acme"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, out, other)
+}
+
+func TestStringPool(t *testing.T) {
+	r := jfrtest.New()
+	r.EventClass(fileRead, types.FileRead,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "path", Class: jfrtest.String},
+		jfrtest.Field{Name: "bytesRead", Class: jfrtest.Long})
+	r.EventClass(socketRead, types.SocketRead,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "host", Class: jfrtest.String},
+		jfrtest.Field{Name: "address", Class: jfrtest.String},
+		jfrtest.Field{Name: "port", Class: jfrtest.Int})
+	r.Constant(jfrtest.String, 1, "/home/alice/invoices.csv")
+	r.Constant(jfrtest.String, 2, "db.acme.internal")
+	start := uint64(jfrtest.StartTicks)
+	r.Event(fileRead, start+1, jfrtest.PooledString(1), uint64(4096))
+	r.Event(socketRead, start+2, jfrtest.PooledString(2), "10.0.0.7", int32(5432))
+
+	out, mapping, err := Anonymize(r.Bytes(), Options{})
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(out, []byte("alice")))
+	assert.False(t, bytes.Contains(out, []byte("db.acme.internal")))
+	for _, token := range []string{
+		"/" + mapping.token(t, "f", "home") + "/" + mapping.token(t, "f", "alice") + "/" + mapping.token(t, "f", "invoices.csv"),
+		mapping.token(t, "h", "db.acme.internal"),
+	} {
+		assert.True(t, bytes.Contains(out, []byte(token)), token)
+	}
+}
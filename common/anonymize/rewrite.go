@@ -0,0 +1,601 @@
+package anonymize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+const (
+	chunkHeaderSize = 68
+
+	metadataEvent   = 0
+	checkpointEvent = 1
+)
+
+const threadDescription = "Thread Name: "
+
+var chunkMagic = []byte{'F', 'L', 'R', 0}
+
+// decoder reads the values of a chunk, the integers are varints in the
+// compressed chunks and big endian otherwise.
+type decoder struct {
+	buf        []byte
+	pos        int
+	compressed bool
+}
+
+func (d *decoder) skip(n int) error {
+	if n < 0 || n > len(d.buf)-d.pos {
+		return io.ErrUnexpectedEOF
+	}
+	d.pos += n
+	return nil
+}
+
+// varint reads an integer of size bytes.
+func (d *decoder) varint(size int) (uint64, error) {
+	if !d.compressed {
+		if err := d.skip(size); err != nil {
+			return 0, err
+		}
+		var v uint64
+		for _, b := range d.buf[d.pos-size : d.pos] {
+			v = v<<8 | uint64(b)
+		}
+		return v, nil
+	}
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if d.pos >= len(d.buf) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		b := d.buf[d.pos]
+		d.pos++
+		if i == 8 {
+			return v | uint64(b)<<56, nil
+		}
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, nil
+		}
+	}
+	return v, nil
+}
+
+func (d *decoder) length() (int, error) {
+	n, err := d.varint(4)
+	if err != nil {
+		return 0, err
+	}
+	if int32(n) < 0 || int(n) > len(d.buf)-d.pos {
+		return 0, def.ErrIntOverflow
+	}
+	return int(n), nil
+}
+
+func appendVarint(dst []byte, v uint64, size int, compressed bool) []byte {
+	if !compressed {
+		for i := size - 1; i >= 0; i-- {
+			dst = append(dst, byte(v>>(8*i)))
+		}
+		return dst
+	}
+	for i := 0; i < 8; i++ {
+		if v < 0x80 {
+			return append(dst, byte(v))
+		}
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+type method struct {
+	typ, name int64
+}
+
+// stringUse is a field referencing an entry of the string pool.
+type stringUse struct {
+	owner, field string
+	top          bool
+}
+
+// chunkRewriter rewrites the events of a chunk, walking their values with
+// the metadata of the chunk and copying everything but the strings
+// verbatim.
+type chunkRewriter struct {
+	*anonymizer
+	d       decoder
+	classes parser.ClassMap
+
+	// scan collects the symbols, classes and methods of the constant pools,
+	// to find the names of the proprietary methods before rewriting.
+	scan        bool
+	entry       int64
+	symbols     map[int64]string
+	classNames  map[int64]int64
+	methods     map[int64]*method
+	methodNames map[int64]bool
+
+	// scanEvents collects the fields referencing the entries of the string
+	// pool, pooled holds the entries rewritten like the strings of those
+	// fields.
+	stringPool map[int64]string
+	stringUses map[int64][]stringUse
+	pooled     map[int64]string
+}
+
+// chunk rewrites the chunk at the start of buf and returns it and the size
+// of the original.
+func (a *anonymizer) chunk(buf []byte) ([]byte, int, error) {
+	if len(buf) < chunkHeaderSize || !bytes.Equal(buf[:4], chunkMagic) {
+		return nil, 0, errors.New("invalid chunk header")
+	}
+	size := binary.BigEndian.Uint64(buf[8:])
+	cpOffset := binary.BigEndian.Uint64(buf[16:])
+	metaOffset := binary.BigEndian.Uint64(buf[24:])
+	if size < chunkHeaderSize || size > uint64(len(buf)) ||
+		cpOffset < chunkHeaderSize || cpOffset >= size || metaOffset < chunkHeaderSize || metaOffset >= size {
+		return nil, 0, fmt.Errorf("unfinished or corrupt chunk: size %d cp %d meta %d", size, cpOffset, metaOffset)
+	}
+	buf = buf[:size]
+
+	var header parser.Header
+	if err := header.Parse(parser.NewReader(bytes.NewReader(buf[8:chunkHeaderSize]), false)); err != nil {
+		return nil, 0, fmt.Errorf("unable to parse chunk header: %w", err)
+	}
+	compressed := header.Features&1 == 1
+	rd := parser.NewReader(bytes.NewReader(buf[metaOffset:]), compressed)
+	if _, err := rd.VarInt(); err != nil {
+		return nil, 0, fmt.Errorf("unable to parse chunk metadata size: %w", err)
+	}
+	metadata := parser.ChunkMetadata{Header: &header}
+	if err := metadata.Parse(rd); err != nil {
+		return nil, 0, fmt.Errorf("unable to parse chunk metadata: %w", err)
+	}
+
+	w := &chunkRewriter{
+		anonymizer:  a,
+		d:           decoder{buf: buf, compressed: compressed},
+		classes:     metadata.ClassMap,
+		symbols:     make(map[int64]string),
+		classNames:  make(map[int64]int64),
+		methods:     make(map[int64]*method),
+		methodNames: make(map[int64]bool),
+		stringPool:  make(map[int64]string),
+		stringUses:  make(map[int64][]stringUse),
+		pooled:      make(map[int64]string),
+	}
+	if err := w.scanConstantPools(int(cpOffset)); err != nil {
+		return nil, 0, err
+	}
+	if err := w.scanEvents(); err != nil {
+		return nil, 0, err
+	}
+
+	out := append([]byte(nil), buf[:chunkHeaderSize]...)
+	offsets := make(map[int]int)
+	err := w.events(func(pos, end int, typ uint64) error {
+		offsets[pos] = len(out)
+		switch {
+		case typ == metadataEvent:
+			out = append(out, buf[pos:end]...)
+		case typ == checkpointEvent:
+			body, _, err := w.checkpoint(appendVarint(nil, typ, 8, compressed), pos, offsets, len(out))
+			if err != nil {
+				return fmt.Errorf("checkpoint at %d: %w", pos, err)
+			}
+			out = w.appendEvent(out, body)
+		default:
+			class, ok := w.classes[int64(typ)]
+			if !ok {
+				return fmt.Errorf("unknown event type %d at %d", typ, pos)
+			}
+			body, err := w.fields(appendVarint(nil, typ, 8, compressed), class, false)
+			if err != nil {
+				return fmt.Errorf("%s at %d: %w", class.Name, pos, err)
+			}
+			// the fields added by a newer JDK
+			body = append(body, buf[w.d.pos:end]...)
+			out = w.appendEvent(out, body)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	binary.BigEndian.PutUint64(out[8:], uint64(len(out)))
+	binary.BigEndian.PutUint64(out[16:], uint64(offsets[int(cpOffset)]))
+	binary.BigEndian.PutUint64(out[24:], uint64(offsets[int(metaOffset)]))
+	return out, int(size), nil
+}
+
+// events calls each with the bounds and the type of every event of the
+// chunk, with the decoder after the type.
+func (w *chunkRewriter) events(each func(pos, end int, typ uint64) error) error {
+	buf := w.d.buf
+	defer func() { w.d.buf = buf }()
+	for pos := chunkHeaderSize; pos < len(buf); {
+		w.d.buf, w.d.pos = buf, pos
+		v, err := w.d.varint(4)
+		n := int(int32(v))
+		if err != nil || n <= 0 || n > len(buf)-pos {
+			return fmt.Errorf("invalid event size at %d", pos)
+		}
+		end := pos + n
+		w.d.buf = buf[:end]
+		typ, err := w.d.varint(8)
+		if err != nil {
+			return err
+		}
+		if err := each(pos, end, typ); err != nil {
+			return err
+		}
+		pos = end
+	}
+	return nil
+}
+
+// appendEvent appends an event of body, prefixed by its size.
+func (w *chunkRewriter) appendEvent(dst, body []byte) []byte {
+	size := len(body) + 1
+	for len(appendVarint(nil, uint64(size), 4, w.d.compressed)) != size-len(body) {
+		size++
+	}
+	dst = appendVarint(dst, uint64(size), 4, w.d.compressed)
+	return append(dst, body...)
+}
+
+// scanConstantPools walks the chain of checkpoints ending at offset.
+func (w *chunkRewriter) scanConstantPools(offset int) error {
+	w.scan = true
+	defer func() { w.scan = false }()
+	buf := w.d.buf
+	visited := make(map[int]bool)
+	for {
+		if visited[offset] {
+			return fmt.Errorf("constant pool cycle at %d", offset)
+		}
+		visited[offset] = true
+		w.d.buf, w.d.pos = buf, offset
+		v, err := w.d.varint(4)
+		n := int(int32(v))
+		if err != nil || n <= 0 || n > len(buf)-offset {
+			return fmt.Errorf("invalid checkpoint size at %d", offset)
+		}
+		w.d.buf = buf[:offset+n]
+		if typ, err := w.d.varint(8); err != nil || typ != checkpointEvent {
+			return fmt.Errorf("no checkpoint at %d", offset)
+		}
+		_, delta, err := w.checkpoint(nil, offset, nil, 0)
+		if err != nil {
+			return fmt.Errorf("checkpoint at %d: %w", offset, err)
+		}
+		if delta == 0 {
+			break
+		}
+		offset += int(delta)
+	}
+	w.d.buf = buf
+
+	// the names shared with the methods of other classes, such as run or
+	// toString, are kept
+	shared := make(map[int64]bool)
+	for _, m := range w.methods {
+		if w.proprietary(w.symbols[w.classNames[m.typ]]) {
+			w.methodNames[m.name] = true
+		} else {
+			shared[m.name] = true
+		}
+	}
+	for name := range w.methodNames {
+		if shared[name] || strings.HasPrefix(w.symbols[name], "<") {
+			delete(w.methodNames, name)
+		}
+	}
+	return nil
+}
+
+// scanEvents collects the references of the events to the string pool, and
+// rewrites the referenced entries like the strings of the fields, such as
+// the paths of the file events, instead of like the other strings.
+func (w *chunkRewriter) scanEvents() error {
+	w.scan = true
+	err := w.events(func(pos, end int, typ uint64) error {
+		if typ == metadataEvent || typ == checkpointEvent {
+			return nil
+		}
+		class, ok := w.classes[int64(typ)]
+		if !ok {
+			return fmt.Errorf("unknown event type %d at %d", typ, pos)
+		}
+		if _, err := w.fields(nil, class, false); err != nil {
+			return fmt.Errorf("%s at %d: %w", class.Name, pos, err)
+		}
+		return nil
+	})
+	w.scan = false
+	if err != nil {
+		return err
+	}
+
+	for ref, uses := range w.stringUses {
+		s := w.stringPool[ref]
+		for _, u := range uses {
+			if r := w.rewrite(u.owner, u.field, u.top, s); r != w.replaceNames(s) {
+				w.pooled[ref] = r
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// checkpoint rewrites the checkpoint event at pos, after its type, to
+// newPos. It returns the original delta to the previous checkpoint, the new
+// one points to its offset in offsets.
+func (w *chunkRewriter) checkpoint(dst []byte, pos int, offsets map[int]int, newPos int) ([]byte, int64, error) {
+	start := w.d.pos
+	// start time and duration
+	if _, err := w.d.varint(8); err != nil {
+		return nil, 0, err
+	}
+	if _, err := w.d.varint(8); err != nil {
+		return nil, 0, err
+	}
+	dst = append(dst, w.d.buf[start:w.d.pos]...)
+	v, err := w.d.varint(8)
+	if err != nil {
+		return nil, 0, err
+	}
+	delta, newDelta := int64(v), int64(0)
+	if delta != 0 && offsets != nil {
+		previous, ok := offsets[pos+int(delta)]
+		if !ok {
+			return nil, 0, fmt.Errorf("no checkpoint at delta %d", delta)
+		}
+		newDelta = int64(previous - newPos)
+	}
+	dst = appendVarint(dst, uint64(newDelta), 8, w.d.compressed)
+	start = w.d.pos
+	// type mask
+	if err := w.d.skip(1); err != nil {
+		return nil, 0, err
+	}
+	pools, err := w.d.length()
+	if err != nil {
+		return nil, 0, err
+	}
+	dst = append(dst, w.d.buf[start:w.d.pos]...)
+	for i := 0; i < pools; i++ {
+		start = w.d.pos
+		id, err := w.d.varint(8)
+		if err != nil {
+			return nil, 0, err
+		}
+		class, ok := w.classes[int64(id)]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown constant pool class %d", id)
+		}
+		n, err := w.d.length()
+		if err != nil {
+			return nil, 0, err
+		}
+		dst = append(dst, w.d.buf[start:w.d.pos]...)
+		for j := 0; j < n; j++ {
+			start = w.d.pos
+			entry, err := w.d.varint(8)
+			if err != nil {
+				return nil, 0, err
+			}
+			dst = append(dst, w.d.buf[start:w.d.pos]...)
+			w.entry = int64(entry)
+			if len(class.Fields) == 0 {
+				dst, err = w.value(dst, class.ID, class, "", true)
+			} else {
+				dst, err = w.fields(dst, class, true)
+			}
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s %d: %w", class.Name, entry, err)
+			}
+		}
+	}
+	return dst, delta, nil
+}
+
+// fields rewrites the fields of a value of class, top tells if it is a
+// constant pool entry rather than a value nested in one.
+func (w *chunkRewriter) fields(dst []byte, class *parser.ClassMetadata, top bool) ([]byte, error) {
+	for _, f := range class.Fields {
+		var err error
+		switch {
+		case f.ConstantPool:
+			start := w.d.pos
+			ref, err := w.d.varint(8)
+			if err != nil {
+				return nil, err
+			}
+			if w.scan && top {
+				w.ref(class.Name, f.Name, int64(ref))
+			}
+			dst = append(dst, w.d.buf[start:w.d.pos]...)
+		case f.Dimension == 1:
+			start := w.d.pos
+			n, err := w.d.length()
+			if err != nil {
+				return nil, err
+			}
+			dst = append(dst, w.d.buf[start:w.d.pos]...)
+			for i := 0; i < n && err == nil; i++ {
+				dst, err = w.value(dst, f.ClassID, class, f.Name, top)
+			}
+		default:
+			dst, err = w.value(dst, f.ClassID, class, f.Name, top)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+	return dst, nil
+}
+
+// value rewrites a value of the class classID, the field of owner.
+func (w *chunkRewriter) value(dst []byte, classID int64, owner *parser.ClassMetadata, field string, top bool) ([]byte, error) {
+	class, ok := w.classes[classID]
+	if !ok {
+		return nil, fmt.Errorf("unknown class %d", classID)
+	}
+	start := w.d.pos
+	var err error
+	switch types.FieldClass(class.Name) {
+	case types.Boolean, types.Byte:
+		err = w.d.skip(1)
+	case types.Short, types.Char:
+		_, err = w.d.varint(2)
+	case types.Int:
+		_, err = w.d.varint(4)
+	case types.Long:
+		_, err = w.d.varint(8)
+	case types.Float:
+		err = w.d.skip(4)
+	case types.Double:
+		err = w.d.skip(8)
+	case types.String:
+		return w.string(dst, owner, field, top)
+	default:
+		return w.fields(dst, class, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, w.d.buf[start:w.d.pos]...), nil
+}
+
+// string rewrites a string, re-encoded in UTF-8 when it changes.
+func (w *chunkRewriter) string(dst []byte, owner *parser.ClassMetadata, field string, top bool) ([]byte, error) {
+	start := w.d.pos
+	if err := w.d.skip(1); err != nil {
+		return nil, err
+	}
+	var s string
+	switch enc := w.d.buf[start]; enc {
+	case parser.StringEncodingNull, parser.StringEncodingEmptyString:
+	case parser.StringEncodingConstantPool:
+		ref, err := w.d.varint(8)
+		if err != nil {
+			return nil, err
+		}
+		if w.scan {
+			w.stringUse(int64(ref), stringUse{owner.Name, field, top})
+		}
+	case parser.StringEncodingUtf8ByteArray, parser.StringEncodingLatin1ByteArray:
+		n, err := w.d.length()
+		if err != nil {
+			return nil, err
+		}
+		b := w.d.buf[w.d.pos : w.d.pos+n]
+		w.d.pos += n
+		if enc == parser.StringEncodingUtf8ByteArray {
+			s = string(b)
+		} else {
+			s = def.DecodeLatin1(b)
+		}
+	case parser.StringEncodingCharArray:
+		n, err := w.d.length()
+		if err != nil {
+			return nil, err
+		}
+		chars := make([]uint16, n)
+		for i := range chars {
+			c, err := w.d.varint(4)
+			if err != nil {
+				return nil, err
+			}
+			chars[i] = uint16(c)
+		}
+		s = string(utf16.Decode(chars))
+	default:
+		return nil, fmt.Errorf("unsupported string encoding %d", enc)
+	}
+	if w.scan {
+		switch {
+		case top && owner.Name == string(types.Symbol):
+			w.symbols[w.entry] = s
+		case top && owner.Name == string(types.String):
+			w.stringPool[w.entry] = s
+		}
+		return dst, nil
+	}
+	r := w.rewrite(owner.Name, field, top, s)
+	if r == s {
+		return append(dst, w.d.buf[start:w.d.pos]...), nil
+	}
+	dst = append(dst, parser.StringEncodingUtf8ByteArray)
+	dst = appendVarint(dst, uint64(len(r)), 4, w.d.compressed)
+	return append(dst, r...), nil
+}
+
+// ref records a constant pool reference of a constant pool entry.
+func (w *chunkRewriter) ref(class, field string, ref int64) {
+	switch {
+	case class == string(types.Class) && field == "name":
+		w.classNames[w.entry] = ref
+	case class == string(types.Method) && (field == "type" || field == "name"):
+		m, ok := w.methods[w.entry]
+		if !ok {
+			m = new(method)
+			w.methods[w.entry] = m
+		}
+		if field == "type" {
+			m.typ = ref
+		} else {
+			m.name = ref
+		}
+	}
+}
+
+// stringUse records a reference to an entry of the string pool.
+func (w *chunkRewriter) stringUse(ref int64, use stringUse) {
+	for _, u := range w.stringUses[ref] {
+		if u == use {
+			return
+		}
+	}
+	w.stringUses[ref] = append(w.stringUses[ref], use)
+}
+
+// rewrite anonymizes a string, the field of a value of the class owner.
+func (w *chunkRewriter) rewrite(owner, field string, top bool, s string) string {
+	o := w.options
+	if r, ok := w.pooled[w.entry]; ok && top && owner == string(types.String) {
+		return r
+	}
+	switch {
+	case s == "":
+		return s
+	case owner == string(types.Symbol) && top && w.methodNames[w.entry]:
+		return w.token("m", s)
+	case owner == string(types.Thread) && (field == "osName" || field == "javaName") && !o.KeepThreadNames:
+		return w.token("t", s)
+	case owner == string(types.OldObject) && field == "description" && strings.HasPrefix(s, threadDescription) && !o.KeepThreadNames:
+		// the leak profiler describes the Thread objects by their names
+		return threadDescription + w.token("t", s[len(threadDescription):])
+	case owner == types.EnvironmentVariable && field == "value" && !o.KeepEnvironment,
+		owner == types.SystemProperties && field == "value" && !o.KeepSystemProperties:
+		return w.token("v", s)
+	case field == "path" && !o.KeepPaths:
+		return w.path(s)
+	case (owner == types.SocketRead || owner == types.SocketWrite) && (field == "host" || field == "address") && !o.KeepHosts:
+		return w.token("h", s)
+	}
+	return w.replaceNames(s)
+}
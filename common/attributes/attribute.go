@@ -17,7 +17,7 @@ var (
 	SwitchRate   = Attr[float64]("switchRate", "Switch Rate", types.Float, "Number of context switches per second")
 
 	StartTime           = AttrNoDesc[units.IQuantity]("startTime", "Start Time", types.Long)
-	GcWhen              = AttrNoDesc[string]("when", "When", types.String)
+	GcWhen              = AttrNoDesc[string]("when", "When", types.GCWhen)
 	EventStacktrace     = AttrNoDesc[*parser.StackTrace]("stackTrace", "Stack Trace", types.StackTrace)
 	ThreadStat          = AttrNoDesc[string]("state", "Thread State", types.ThreadState)
 	CpuSamplingInterval = AttrNoDesc[units.IQuantity]("cpuInterval", "CPU Sampling Interval", types.Long)
@@ -26,6 +26,108 @@ var (
 	SettingUnit         = AttrNoDesc[string]("unit", "Setting Unit", types.String)
 	DatadogEndpoint     = AttrNoDesc[string]("endpoint", "Endpoint", types.String)
 	Duration            = AttrNoDesc[units.IQuantity]("duration", "Duration", types.Long)
+	ThrownClass         = AttrNoDesc[*parser.Class]("thrownClass", "Class", types.Class)
+	ThrowableMessage    = AttrNoDesc[string]("message", "Message", types.String)
+	Throwables          = AttrNoDesc[int64]("throwables", "Throwables", types.Long)
+	GcId                = AttrNoDesc[int64]("gcId", "GC Identifier", types.Int)
+	GcName              = AttrNoDesc[string]("name", "Name", types.GCName)
+	GcCause             = AttrNoDesc[string]("cause", "Cause", types.GCCause)
+	GcPhaseName         = AttrNoDesc[string]("name", "Name", types.String)
+	SumOfPauses         = AttrNoDesc[units.IQuantity]("sumOfPauses", "Sum of Pauses", types.Long)
+	LongestPause        = AttrNoDesc[units.IQuantity]("longestPause", "Longest Pause", types.Long)
+	HeapUsed            = AttrNoDesc[units.IQuantity]("heapUsed", "Heap Used", types.Long)
+	CompileId           = AttrNoDesc[int64]("compileId", "Compilation Identifier", types.Int)
+	CompileLevel        = AttrNoDesc[int64]("compileLevel", "Compilation Level", types.Short)
+	CompiledMethod      = AttrNoDesc[*parser.Method]("method", "Method", types.Method)
+	CodeSize            = AttrNoDesc[int64]("codeSize", "Compiled Code Size", types.Long)
+	InlinedBytes        = AttrNoDesc[int64]("inlinedBytes", "Inlined Code Size", types.Long)
+	IsOsr               = AttrNoDesc[bool]("isOsr", "On Stack Replacement", types.Boolean)
+	CompileSucceeded    = AttrNoDesc[bool]("succeded", "Succeeded", types.Boolean)
+	FailureMessage      = AttrNoDesc[string]("failureMessage", "Failure Message", types.String)
+	CodeBlobType        = AttrNoDesc[string]("codeBlobType", "Code Heap", types.CodeBlobType)
+	StartAddress        = AttrNoDesc[int64]("startAddress", "Start Address", types.Long)
+	ReservedTopAddress  = AttrNoDesc[int64]("reservedTopAddress", "Reserved Top", types.Long)
+	UnallocatedCapacity = AttrNoDesc[int64]("unallocatedCapacity", "Unallocated", types.Long)
+	FullCount           = AttrNoDesc[int64]("fullCount", "Full Count", types.Int)
+	EntryCount          = AttrNoDesc[int64]("entryCount", "Entries", types.Int)
+	MethodCount         = AttrNoDesc[int64]("methodCount", "Methods", types.Int)
+	DeoptReason         = AttrNoDesc[string]("reason", "Reason", types.DeoptimizationReason)
+	DeoptAction         = AttrNoDesc[string]("action", "Action", types.DeoptimizationAction)
+	InvalidatedCount    = AttrNoDesc[int64]("invalidatedCount", "Invalidated Compilations", types.Int)
+	SafepointId         = AttrNoDesc[int64]("safepointId", "Safepoint Identifier", types.Int)
+	TotalThreadCount    = AttrNoDesc[int64]("totalThreadCount", "Total Threads", types.Int)
+	JniCriticalThreads  = AttrNoDesc[int64]("jniCriticalThreadCount", "JNI Critical Threads", types.Int)
+	RunningThreadCount  = AttrNoDesc[int64]("runningThreadCount", "Running Threads", types.Int)
+	SyncIterations      = AttrNoDesc[int64]("iterations", "Iterations", types.Int)
+	VmOperation         = AttrNoDesc[string]("operation", "Operation", types.VMOperationType)
+	AllocationTime      = AttrNoDesc[units.IQuantity]("allocationTime", "Allocation Time", types.Long)
+	ObjectSize          = AttrNoDesc[units.IQuantity]("objectSize", "Object Size", types.Long)
+	ObjectAge           = AttrNoDesc[units.IQuantity]("objectAge", "Object Age", types.Long)
+	LastKnownHeapUsage  = AttrNoDesc[units.IQuantity]("lastKnownHeapUsage", "Last Known Heap Usage", types.Long)
+	OldObject           = AttrNoDesc[*parser.OldObject]("object", "Object", types.OldObject)
+	ArrayElements       = AttrNoDesc[int64]("arrayElements", "Array Elements", types.Int)
+	OldObjectRoot       = AttrNoDesc[*parser.OldObjectGcRoot]("root", "GC Root", types.OldObjectGcRoot)
+	JvmName             = AttrNoDesc[string]("jvmName", "JVM Name", types.String)
+	JvmVersion          = AttrNoDesc[string]("jvmVersion", "JVM Version", types.String)
+	JvmArguments        = AttrNoDesc[string]("jvmArguments", "JVM Command Line Arguments", types.String)
+	JvmFlags            = AttrNoDesc[string]("jvmFlags", "JVM Settings File Arguments", types.String)
+	JavaArguments       = AttrNoDesc[string]("javaArguments", "Java Application Arguments", types.String)
+	Pid                 = AttrNoDesc[int64]("pid", "Process Identifier", types.Long)
+	OsVersion           = AttrNoDesc[string]("osVersion", "OS Version", types.String)
+	Cpu                 = AttrNoDesc[string]("cpu", "Type", types.String)
+	CpuDescription      = AttrNoDesc[string]("description", "Description", types.String)
+	Sockets             = AttrNoDesc[int64]("sockets", "Sockets", types.Int)
+	Cores               = AttrNoDesc[int64]("cores", "Cores", types.Int)
+	HwThreads           = AttrNoDesc[int64]("hwThreads", "Hardware Threads", types.Int)
+	VirtualizationName  = AttrNoDesc[string]("name", "Name", types.String)
+	ContainerType       = AttrNoDesc[string]("containerType", "Container Type", types.String)
+	CpuSlicePeriod      = AttrNoDesc[units.IQuantity]("cpuSlicePeriod", "CPU Slice Period", types.Long)
+	ContainerCpuQuota   = AttrNoDesc[units.IQuantity]("cpuQuota", "CPU Quota", types.Long)
+	ContainerCpuShares  = AttrNoDesc[int64]("cpuShares", "CPU Shares", types.Long)
+	EffectiveCpuCount   = AttrNoDesc[int64]("effectiveCpuCount", "Effective CPU Count", types.Long)
+	MemorySoftLimit     = AttrNoDesc[units.IQuantity]("memorySoftLimit", "Memory Soft Limit", types.Long)
+	MemoryLimit         = AttrNoDesc[units.IQuantity]("memoryLimit", "Memory Limit", types.Long)
+	SwapMemoryLimit     = AttrNoDesc[units.IQuantity]("swapMemoryLimit", "Memory and Swap Limit", types.Long)
+	HostTotalMemory     = AttrNoDesc[units.IQuantity]("hostTotalMemory", "Container Host Total Memory", types.Long)
+	CpuTime             = AttrNoDesc[units.IQuantity]("cpuTime", "CPU Time", types.Long)
+	CpuUserTime         = AttrNoDesc[units.IQuantity]("cpuUserTime", "CPU User Time", types.Long)
+	CpuSystemTime       = AttrNoDesc[units.IQuantity]("cpuSystemTime", "CPU System Time", types.Long)
+	CpuElapsedSlices    = AttrNoDesc[int64]("cpuElapsedSlices", "CPU Elapsed Slices", types.Long)
+	CpuThrottledSlices  = AttrNoDesc[int64]("cpuThrottledSlices", "CPU Throttled Slices", types.Long)
+	CpuThrottledTime    = AttrNoDesc[units.IQuantity]("cpuThrottledTime", "CPU Throttled Time", types.Long)
+	MemoryFailCount     = AttrNoDesc[int64]("memoryFailCount", "Memory Fail Count", types.Long)
+	MemoryUsage         = AttrNoDesc[units.IQuantity]("memoryUsage", "Memory Usage", types.Long)
+	SwapMemoryUsage     = AttrNoDesc[units.IQuantity]("swapMemoryUsage", "Swap Memory Usage", types.Long)
+	IoServiceRequests   = AttrNoDesc[int64]("serviceRequests", "Block IO Request Count", types.Long)
+	IoDataTransferred   = AttrNoDesc[units.IQuantity]("dataTransferred", "Block IO Transfer", types.Long)
+	PropertyKey         = AttrNoDesc[string]("key", "Key", types.String)
+	PropertyValue       = AttrNoDesc[string]("value", "Value", types.String)
+	FlagName            = AttrNoDesc[string]("name", "Name", types.String)
+	FlagOrigin          = AttrNoDesc[string]("origin", "Origin", types.FlagValueOrigin)
+	BooleanFlagValue    = AttrNoDesc[bool]("value", "Value", types.Boolean)
+	IntFlagValue        = AttrNoDesc[int64]("value", "Value", types.Long)
+	DoubleFlagValue     = AttrNoDesc[float64]("value", "Value", types.Double)
+	StringFlagValue     = AttrNoDesc[string]("value", "Value", types.String)
+	JvmUser             = AttrNoDesc[units.IQuantity]("jvmUser", "JVM User", types.Float)
+	JvmSystem           = AttrNoDesc[units.IQuantity]("jvmSystem", "JVM System", types.Float)
+	MachineTotal        = AttrNoDesc[units.IQuantity]("machineTotal", "Machine Total", types.Float)
+	UserLoad            = AttrNoDesc[units.IQuantity]("user", "User Mode CPU Load", types.Float)
+	SystemLoad          = AttrNoDesc[units.IQuantity]("system", "System Mode CPU Load", types.Float)
+	PhysicalTotalSize   = AttrNoDesc[units.IQuantity]("totalSize", "Total Size", types.Long)
+	PhysicalUsedSize    = AttrNoDesc[units.IQuantity]("usedSize", "Used Size", types.Long)
+	LoadedClassCount    = AttrNoDesc[int64]("loadedClassCount", "Loaded Class Count", types.Long)
+	UnloadedClassCount  = AttrNoDesc[int64]("unloadedClassCount", "Unloaded Class Count", types.Long)
+	ActiveThreads       = AttrNoDesc[int64]("activeCount", "Active Threads", types.Long)
+	DaemonThreads       = AttrNoDesc[int64]("daemonCount", "Daemon Threads", types.Long)
+	AccumulatedThreads  = AttrNoDesc[int64]("accumulatedCount", "Accumulated Threads", types.Long)
+	PeakThreads         = AttrNoDesc[int64]("peakCount", "Peak Threads", types.Long)
+	NetworkInterface    = AttrNoDesc[string]("networkInterface", "Interface", types.NetworkInterfaceName)
+	ReadRate            = AttrNoDesc[units.IQuantity]("readRate", "Read Rate", types.Long)
+	WriteRate           = AttrNoDesc[units.IQuantity]("writeRate", "Write Rate", types.Long)
+	BlockingOperation   = AttrNoDesc[string]("blockingOperation", "Blocking Operation", types.String)
+	PinnedReason        = AttrNoDesc[string]("pinnedReason", "Pinned Reason", types.String)
+	CarrierThread       = AttrNoDesc[*parser.Thread]("carrierThread", "Carrier Thread", types.Thread)
+	SubmitFailedMessage = AttrNoDesc[string]("exceptionMessage", "Exception Message", types.String)
 
 	JVMStartTime       = AttrSimple[units.IQuantity]("jvmStartTime", types.Long)
 	SampleWeight       = AttrSimple[int64]("weight", types.Long)
@@ -34,6 +136,7 @@ var (
 	WallSampleInterval = AttrSimple[units.IQuantity]("wallInterval", types.Long)
 	Allocated          = AttrSimple[units.IQuantity]("allocated", types.Long)
 	Size               = AttrSimple[units.IQuantity]("size", types.Long)
+	ContextSwitches    = AttrSimple[units.IQuantity]("switchRate", types.Float)
 	HeapWeight         = AttrSimple[float64]("weight", types.Long)
 )
 
@@ -68,6 +171,37 @@ func AttrNoDesc[T any](name, label string, className types.FieldClass) *Attribut
 	}
 }
 
+// Annotations returns the annotations of the attribute field in the event type,
+// or nil if the event type has no such field.
+func (a *Attribute[T]) Annotations(event *parser.GenericEvent) []*parser.Annotation {
+	fieldMeta := event.ClassMetadata.GetField(a.Name)
+	if fieldMeta == nil {
+		return nil
+	}
+	return fieldMeta.AllAnnotations(event.ClassMetadata.ClassMap)
+}
+
+// Annotation returns the annotation of the given type name on the attribute
+// field in the event type, or nil.
+func (a *Attribute[T]) Annotation(event *parser.GenericEvent, name string) *parser.Annotation {
+	for _, annotation := range a.Annotations(event) {
+		if annotation.Name == name {
+			return annotation
+		}
+	}
+	return nil
+}
+
+// ContentType returns the content type annotation of the attribute field in
+// the event type, such as jdk.jfr.Timespan, or "".
+func (a *Attribute[T]) ContentType(event *parser.GenericEvent) string {
+	fieldMeta := event.ClassMetadata.GetField(a.Name)
+	if fieldMeta == nil {
+		return ""
+	}
+	return fieldMeta.ContentType(event.ClassMetadata.ClassMap)
+}
+
 func (a *Attribute[T]) GetValue(event *parser.GenericEvent) (T, error) {
 	var t T
 	attr, ok := event.Attributes[a.Name]
@@ -134,7 +268,10 @@ func (a *Attribute[T]) GetValue(event *parser.GenericEvent) (T, error) {
 		}
 
 		if fieldMeta.TickTimestamp(event.ClassMetadata.ClassMap) {
-			ts := fieldMeta.ChunkHeader.StartTimeNanos + ((num.Int64() - fieldMeta.ChunkHeader.StartTicks) * 1e9 / fieldMeta.ChunkHeader.TicksPerSecond)
+			// split the conversion, ticks * 1e9 overflows after a few seconds
+			// of a chunk at a GHz tick rate
+			d, tps := num.Int64()-fieldMeta.ChunkHeader.StartTicks, fieldMeta.ChunkHeader.TicksPerSecond
+			ts := fieldMeta.ChunkHeader.StartTimeNanos + d/tps*1e9 + d%tps*1e9/tps
 			quantity = units.NewIntQuantity(ts, units.UnixNano)
 		} else {
 			if num.Float() {
@@ -151,7 +288,33 @@ func (a *Attribute[T]) GetValue(event *parser.GenericEvent) (T, error) {
 
 	switch any(t).(type) {
 	case string:
-		s, err := parser.ToString(attr)
+		var s string
+		var err error
+		switch v := attr.(type) {
+		// constant pool types holding a single string
+		case *parser.GCName:
+			s = v.String
+		case *parser.GCCause:
+			s = v.Cause
+		case *parser.GCWhen:
+			s = v.When
+		case *parser.CodeBlobType:
+			s = v.String
+		case *parser.DeoptimizationReason:
+			s = v.Reason
+		case *parser.DeoptimizationAction:
+			s = v.Action
+		case *parser.VMOperationType:
+			s = v.Type
+		case *parser.FlagValueOrigin:
+			s = v.String
+		case *parser.NetworkInterfaceName:
+			s = v.NetworkInterface
+		case *parser.ThreadState:
+			s = v.Name
+		default:
+			s, err = parser.ToString(attr)
+		}
 		if err != nil {
 			return t, fmt.Errorf("unable to resolve string: %w", err)
 		}
@@ -0,0 +1,592 @@
+// Package container reports the cgroup limits and usage of a containerized
+// JVM from the jdk.ContainerConfiguration, jdk.ContainerCPUUsage,
+// jdk.ContainerCPUThrottling, jdk.ContainerMemoryUsage and jdk.ContainerIOUsage
+// events of JDK 17 and later, and the CPU samples taken while the container
+// was throttled: a profile which looks slow but idle often waited for its CPU
+// quota.
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+	types2 "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+const defaultTopMethods = 10
+
+type Options struct {
+	// TopMethods is the number of top frames of the throttled samples the
+	// report keeps, 10 when zero.
+	TopMethods int
+}
+
+// Configuration is the last jdk.ContainerConfiguration of the recording. The
+// limits are negative when unlimited.
+type Configuration struct {
+	// Type is the cgroup version, such as cgroupv2.
+	Type string `json:"type"`
+	// CPUQuota is the CPU time the container may use every CPUPeriod.
+	CPUQuota          time.Duration `json:"cpuQuota"`
+	CPUPeriod         time.Duration `json:"cpuPeriod"`
+	CPUShares         int64         `json:"cpuShares"`
+	EffectiveCPUCount int64         `json:"effectiveCpuCount"`
+	MemoryLimit       int64         `json:"memoryLimit"`
+	MemorySoftLimit   int64         `json:"memorySoftLimit"`
+	// SwapMemoryLimit limits the memory and the swap together.
+	SwapMemoryLimit int64 `json:"swapMemoryLimit"`
+	// HostTotalMemory is zero before JDK 21.
+	HostTotalMemory int64 `json:"hostTotalMemory"`
+}
+
+// CPULimit is the number of CPUs the quota allows, zero when unlimited.
+func (c *Configuration) CPULimit() float64 {
+	if c.CPUQuota <= 0 || c.CPUPeriod <= 0 {
+		return 0
+	}
+	return float64(c.CPUQuota) / float64(c.CPUPeriod)
+}
+
+// CPUInterval is the CPU time the container used between two
+// jdk.ContainerCPUUsage events.
+type CPUInterval struct {
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Total  time.Duration `json:"total"`
+	User   time.Duration `json:"user"`
+	System time.Duration `json:"system"`
+	// CPUs is the average number of CPUs used.
+	CPUs float64 `json:"cpus"`
+}
+
+// ThrottlingInterval is the CFS bandwidth control between two
+// jdk.ContainerCPUThrottling events.
+type ThrottlingInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// ElapsedSlices is the number of CPU periods in which the container ran,
+	// ThrottledSlices of them it ran out of quota.
+	ElapsedSlices   int64         `json:"elapsedSlices"`
+	ThrottledSlices int64         `json:"throttledSlices"`
+	ThrottledTime   time.Duration `json:"throttledTime"`
+	// Samples is the number of CPU samples taken in the interval.
+	Samples int64 `json:"samples"`
+}
+
+type MemorySample struct {
+	Time  time.Time `json:"time"`
+	Usage int64     `json:"usage"`
+	// SwapUsage is the usage of the memory and the swap together.
+	SwapUsage int64 `json:"swapUsage"`
+	// Failures is the number of times the usage hit the limit since the
+	// previous sample.
+	Failures int64 `json:"failures"`
+}
+
+// IOInterval is the block I/O of the container between two
+// jdk.ContainerIOUsage events.
+type IOInterval struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Requests int64     `json:"requests"`
+	Bytes    int64     `json:"bytes"`
+}
+
+type Summary struct {
+	// AverageCPUs and PeakCPUs are the average and highest CPUs used over
+	// the CPU intervals.
+	AverageCPUs float64 `json:"averageCpus"`
+	PeakCPUs    float64 `json:"peakCpus"`
+	// ThrottledRatio is the share of the elapsed CPU periods which were
+	// throttled.
+	ElapsedSlices   int64         `json:"elapsedSlices"`
+	ThrottledSlices int64         `json:"throttledSlices"`
+	ThrottledRatio  float64       `json:"throttledRatio"`
+	ThrottledTime   time.Duration `json:"throttledTime"`
+	PeakMemoryUsage int64         `json:"peakMemoryUsage"`
+	MemoryFailures  int64         `json:"memoryFailures"`
+	IORequests      int64         `json:"ioRequests"`
+	IOBytes         int64         `json:"ioBytes"`
+}
+
+type Method struct {
+	Name    string `json:"name"`
+	Samples int64  `json:"samples"`
+}
+
+// Throttled tells which CPU samples coincided with the throttling of the
+// container: the samples of the throttling intervals with throttled slices.
+type Throttled struct {
+	// Samples is the number of CPU samples of all the throttling intervals,
+	// ThrottledSamples of the throttled ones.
+	Samples          int64   `json:"samples"`
+	ThrottledSamples int64   `json:"throttledSamples"`
+	Ratio            float64 `json:"ratio"`
+	// Methods are the top frames of the throttled samples, by decreasing
+	// count.
+	Methods []Method `json:"methods"`
+}
+
+type Report struct {
+	// Configuration is nil when the JVM did not run in a container.
+	Configuration *Configuration `json:"configuration,omitempty"`
+	Summary       Summary        `json:"summary"`
+	// The series are ordered by time.
+	CPU        []CPUInterval        `json:"cpu"`
+	Throttling []ThrottlingInterval `json:"throttling"`
+	Memory     []MemorySample       `json:"memory"`
+	IO         []IOInterval         `json:"io"`
+	Throttled  Throttled            `json:"throttled"`
+}
+
+// The cgroup values of the events are cumulative, but for the memory usage.
+// They are negative when the JVM could not read them.
+
+type cpuUsage struct {
+	time                time.Time
+	total, user, system time.Duration
+}
+
+type throttling struct {
+	time               time.Time
+	elapsed, throttled int64
+	throttledTime      time.Duration
+}
+
+type memoryUsage struct {
+	time                  time.Time
+	failures, usage, swap int64
+}
+
+type ioUsage struct {
+	time            time.Time
+	requests, bytes int64
+}
+
+type sample struct {
+	time   time.Time
+	method string
+}
+
+type builder struct {
+	options       Options
+	configuration *Configuration
+	configured    time.Time
+	cpu           []cpuUsage
+	throttling    []throttling
+	memory        []memoryUsage
+	io            []ioUsage
+	samples       []sample
+}
+
+func newBuilder(options Options) *builder {
+	if options.TopMethods <= 0 {
+		options.TopMethods = defaultTopMethods
+	}
+	return &builder{options: options}
+}
+
+func (b *builder) configure(t time.Time, c Configuration) {
+	if b.configuration == nil || !t.Before(b.configured) {
+		b.configuration, b.configured = &c, t
+	}
+}
+
+func (b *builder) report() *Report {
+	r := &Report{Configuration: b.configuration}
+
+	var elapsedTotal time.Duration
+	sort.SliceStable(b.cpu, func(i, j int) bool { return b.cpu[i].time.Before(b.cpu[j].time) })
+	for i := 1; i < len(b.cpu); i++ {
+		prev, cur := &b.cpu[i-1], &b.cpu[i]
+		elapsed := cur.time.Sub(prev.time)
+		total := cur.total - prev.total
+		if elapsed <= 0 || prev.total < 0 || total < 0 {
+			continue
+		}
+		c := CPUInterval{
+			Start:  prev.time,
+			End:    cur.time,
+			Total:  total,
+			User:   cur.user - prev.user,
+			System: cur.system - prev.system,
+			CPUs:   float64(total) / float64(elapsed),
+		}
+		r.CPU = append(r.CPU, c)
+		r.Summary.AverageCPUs += float64(total)
+		r.Summary.PeakCPUs = max(r.Summary.PeakCPUs, c.CPUs)
+		elapsedTotal += elapsed
+	}
+	if elapsedTotal > 0 {
+		r.Summary.AverageCPUs /= float64(elapsedTotal)
+	}
+
+	sort.SliceStable(b.throttling, func(i, j int) bool { return b.throttling[i].time.Before(b.throttling[j].time) })
+	for i := 1; i < len(b.throttling); i++ {
+		prev, cur := &b.throttling[i-1], &b.throttling[i]
+		t := ThrottlingInterval{
+			Start:           prev.time,
+			End:             cur.time,
+			ElapsedSlices:   cur.elapsed - prev.elapsed,
+			ThrottledSlices: cur.throttled - prev.throttled,
+			ThrottledTime:   cur.throttledTime - prev.throttledTime,
+		}
+		if !t.End.After(t.Start) || prev.elapsed < 0 || prev.throttled < 0 || t.ElapsedSlices < 0 || t.ThrottledSlices < 0 {
+			continue
+		}
+		r.Throttling = append(r.Throttling, t)
+		r.Summary.ElapsedSlices += t.ElapsedSlices
+		r.Summary.ThrottledSlices += t.ThrottledSlices
+		r.Summary.ThrottledTime += t.ThrottledTime
+	}
+	if r.Summary.ElapsedSlices > 0 {
+		r.Summary.ThrottledRatio = float64(r.Summary.ThrottledSlices) / float64(r.Summary.ElapsedSlices)
+	}
+
+	sort.SliceStable(b.memory, func(i, j int) bool { return b.memory[i].time.Before(b.memory[j].time) })
+	for i, m := range b.memory {
+		if m.usage < 0 {
+			continue
+		}
+		s := MemorySample{Time: m.time, Usage: m.usage, SwapUsage: m.swap}
+		if i > 0 && b.memory[i-1].failures >= 0 && m.failures > b.memory[i-1].failures {
+			s.Failures = m.failures - b.memory[i-1].failures
+		}
+		r.Memory = append(r.Memory, s)
+		r.Summary.PeakMemoryUsage = max(r.Summary.PeakMemoryUsage, s.Usage)
+		r.Summary.MemoryFailures += s.Failures
+	}
+
+	sort.SliceStable(b.io, func(i, j int) bool { return b.io[i].time.Before(b.io[j].time) })
+	for i := 1; i < len(b.io); i++ {
+		prev, cur := &b.io[i-1], &b.io[i]
+		io := IOInterval{Start: prev.time, End: cur.time, Requests: cur.requests - prev.requests, Bytes: cur.bytes - prev.bytes}
+		if !io.End.After(io.Start) || prev.requests < 0 || prev.bytes < 0 || io.Requests < 0 || io.Bytes < 0 {
+			continue
+		}
+		r.IO = append(r.IO, io)
+		r.Summary.IORequests += io.Requests
+		r.Summary.IOBytes += io.Bytes
+	}
+
+	b.correlate(r)
+	return r
+}
+
+// correlate counts the CPU samples of each throttling interval, and the top
+// frames of the samples of the throttled ones.
+func (b *builder) correlate(r *Report) {
+	methods := make(map[string]int64)
+	for _, s := range b.samples {
+		// the intervals include their end, the time the counters were read
+		i := sort.Search(len(r.Throttling), func(i int) bool { return !r.Throttling[i].End.Before(s.time) })
+		if i == len(r.Throttling) || !s.time.After(r.Throttling[i].Start) {
+			continue
+		}
+		t := &r.Throttling[i]
+		t.Samples++
+		r.Throttled.Samples++
+		if t.ThrottledSlices > 0 {
+			r.Throttled.ThrottledSamples++
+			methods[s.method]++
+		}
+	}
+	if r.Throttled.Samples > 0 {
+		r.Throttled.Ratio = float64(r.Throttled.ThrottledSamples) / float64(r.Throttled.Samples)
+	}
+	for name, n := range methods {
+		r.Throttled.Methods = append(r.Throttled.Methods, Method{Name: name, Samples: n})
+	}
+	sort.Slice(r.Throttled.Methods, func(i, j int) bool {
+		a, b := &r.Throttled.Methods[i], &r.Throttled.Methods[j]
+		if a.Samples != b.Samples {
+			return a.Samples > b.Samples
+		}
+		return a.Name < b.Name
+	})
+	if len(r.Throttled.Methods) > b.options.TopMethods {
+		r.Throttled.Methods = r.Throttled.Methods[:b.options.TopMethods]
+	}
+}
+
+// FromParser reads the remaining events of the parser into a report.
+func FromParser(p *parser.Parser, options Options) (*Report, error) {
+	b := newBuilder(options)
+	err := report.ParseEvents(p, func(typ def.TypeID) {
+		switch typ {
+		case p.TypeMap.T_CONTAINER_CONFIGURATION:
+			e := &p.ContainerConfiguration
+			b.configure(p.TicksToTime(e.StartTime), Configuration{
+				Type: e.ContainerType,
+				// microseconds
+				CPUQuota:          time.Duration(int64(e.CpuQuota)) * time.Microsecond,
+				CPUPeriod:         time.Duration(int64(e.CpuSlicePeriod)) * time.Microsecond,
+				CPUShares:         int64(e.CpuShares),
+				EffectiveCPUCount: int64(e.EffectiveCpuCount),
+				MemoryLimit:       int64(e.MemoryLimit),
+				MemorySoftLimit:   int64(e.MemorySoftLimit),
+				SwapMemoryLimit:   int64(e.SwapMemoryLimit),
+				HostTotalMemory:   int64(e.HostTotalMemory),
+			})
+		case p.TypeMap.T_CONTAINER_CPU_USAGE:
+			e := &p.ContainerCPUUsage
+			b.cpu = append(b.cpu, cpuUsage{p.TicksToTime(e.StartTime), time.Duration(e.CpuTime), time.Duration(e.CpuUserTime), time.Duration(e.CpuSystemTime)})
+		case p.TypeMap.T_CONTAINER_CPU_THROTTLING:
+			e := &p.ContainerCPUThrottling
+			b.throttling = append(b.throttling, throttling{p.TicksToTime(e.StartTime), int64(e.CpuElapsedSlices), int64(e.CpuThrottledSlices), time.Duration(e.CpuThrottledTime)})
+		case p.TypeMap.T_CONTAINER_MEMORY_USAGE:
+			e := &p.ContainerMemoryUsage
+			b.memory = append(b.memory, memoryUsage{p.TicksToTime(e.StartTime), int64(e.MemoryFailCount), int64(e.MemoryUsage), int64(e.SwapMemoryUsage)})
+		case p.TypeMap.T_CONTAINER_IO_USAGE:
+			e := &p.ContainerIOUsage
+			b.io = append(b.io, ioUsage{p.TicksToTime(e.StartTime), int64(e.ServiceRequests), int64(e.DataTransferred)})
+		case p.TypeMap.T_EXECUTION_SAMPLE:
+			e := &p.ExecutionSample
+			if ts := p.GetThreadState(e.State); ts != nil && ts.Name == "STATE_SLEEPING" {
+				// wall clock samples of async-profiler
+				return
+			}
+			b.samples = append(b.samples, sample{p.TicksToTime(e.StartTime), topMethod(p, e.StackTrace)})
+		case p.TypeMap.T_DD_EXECUTION_SAMPLE:
+			e := &p.DatadogExecutionSample
+			b.samples = append(b.samples, sample{p.TicksToTime(e.StartTime), topMethod(p, e.StackTrace)})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.report(), nil
+}
+
+func topMethod(p *parser.Parser, ref types2.StackTraceRef) string {
+	st := p.GetStacktrace(ref)
+	if st == nil || len(st.Frames) == 0 {
+		return ""
+	}
+	m := p.GetMethod(st.Frames[0].Method)
+	if m == nil {
+		return ""
+	}
+	name := p.GetSymbolString(m.Name)
+	if cls := p.GetClass(m.Type); cls != nil {
+		return report.JavaName(p.GetSymbolString(cls.Name)) + "." + name
+	}
+	return name
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.ContainerConfiguration) {
+			t, err := report.StartTime(event)
+			if err != nil {
+				return nil, err
+			}
+			c, err := readConfiguration(event)
+			if err != nil {
+				return nil, err
+			}
+			b.configure(t, c)
+		}
+		for _, event := range chunk.Apply(filters.ContainerUsage) {
+			if err := b.addUsage(event); err != nil {
+				return nil, err
+			}
+		}
+		for _, event := range chunk.Apply(filters.Types(types.ExecutionSample, types.DatadogExecutionSample)) {
+			if state, err := attributes.ThreadStat.GetValue(event); err == nil && state == "STATE_SLEEPING" {
+				continue
+			}
+			t, err := report.StartTime(event)
+			if err != nil {
+				return nil, err
+			}
+			s := sample{time: t}
+			if st, err := attributes.EventStacktrace.GetValue(event); err == nil && st != nil && len(st.Frames) > 0 {
+				s.method = report.MethodName(st.Frames[0].Method)
+			}
+			b.samples = append(b.samples, s)
+		}
+	}
+	return b.report(), nil
+}
+
+func readConfiguration(event *parser.GenericEvent) (Configuration, error) {
+	var (
+		c   Configuration
+		err error
+	)
+	if c.Type, err = attributes.ContainerType.GetValue(event); err != nil {
+		return c, err
+	}
+	if c.CPUQuota, err = durationValue(event, attributes.ContainerCpuQuota); err != nil {
+		return c, err
+	}
+	if c.CPUPeriod, err = durationValue(event, attributes.CpuSlicePeriod); err != nil {
+		return c, err
+	}
+	c.CPUShares, _ = attributes.ContainerCpuShares.GetValue(event)
+	c.EffectiveCPUCount, _ = attributes.EffectiveCpuCount.GetValue(event)
+	for _, v := range []struct {
+		dst  *int64
+		attr *attributes.Attribute[units.IQuantity]
+	}{
+		{&c.MemoryLimit, attributes.MemoryLimit},
+		{&c.MemorySoftLimit, attributes.MemorySoftLimit},
+		{&c.SwapMemoryLimit, attributes.SwapMemoryLimit},
+		{&c.HostTotalMemory, attributes.HostTotalMemory},
+	} {
+		if *v.dst, err = bytesValue(event, v.attr); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func (b *builder) addUsage(event *parser.GenericEvent) error {
+	t, err := report.StartTime(event)
+	if err != nil {
+		return err
+	}
+	switch event.ClassMetadata.Name {
+	case types.ContainerCPUUsage:
+		c := cpuUsage{time: t}
+		if c.total, err = durationValue(event, attributes.CpuTime); err != nil {
+			return err
+		}
+		if c.user, err = durationValue(event, attributes.CpuUserTime); err != nil {
+			return err
+		}
+		if c.system, err = durationValue(event, attributes.CpuSystemTime); err != nil {
+			return err
+		}
+		b.cpu = append(b.cpu, c)
+	case types.ContainerCPUThrottling:
+		c := throttling{time: t}
+		if c.elapsed, err = attributes.CpuElapsedSlices.GetValue(event); err != nil {
+			return err
+		}
+		if c.throttled, err = attributes.CpuThrottledSlices.GetValue(event); err != nil {
+			return err
+		}
+		if c.throttledTime, err = durationValue(event, attributes.CpuThrottledTime); err != nil {
+			return err
+		}
+		b.throttling = append(b.throttling, c)
+	case types.ContainerMemoryUsage:
+		m := memoryUsage{time: t}
+		if m.failures, err = attributes.MemoryFailCount.GetValue(event); err != nil {
+			return err
+		}
+		if m.usage, err = bytesValue(event, attributes.MemoryUsage); err != nil {
+			return err
+		}
+		if m.swap, err = bytesValue(event, attributes.SwapMemoryUsage); err != nil {
+			return err
+		}
+		b.memory = append(b.memory, m)
+	case types.ContainerIOUsage:
+		io := ioUsage{time: t}
+		if io.requests, err = attributes.IoServiceRequests.GetValue(event); err != nil {
+			return err
+		}
+		if io.bytes, err = bytesValue(event, attributes.IoDataTransferred); err != nil {
+			return err
+		}
+		b.io = append(b.io, io)
+	}
+	return nil
+}
+
+func durationValue(event *parser.GenericEvent, attr *attributes.Attribute[units.IQuantity]) (time.Duration, error) {
+	q, err := attr.GetValue(event)
+	if err != nil {
+		return 0, err
+	}
+	return units.ToDuration(q)
+}
+
+// bytesValue is zero when the field is missing, as hostTotalMemory before
+// JDK 21.
+func bytesValue(event *parser.GenericEvent, attr *attributes.Attribute[units.IQuantity]) (int64, error) {
+	q, err := attr.GetValue(event)
+	if err != nil {
+		return 0, nil
+	}
+	if q, err = q.In(units.Byte); err != nil {
+		return 0, err
+	}
+	return q.IntValue(), nil
+}
+
+// WriteJSON writes the report as indented JSON, with durations in
+// nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the configuration, the summary, the throttling intervals
+// and the top frames of the throttled samples as tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if c := r.Configuration; c != nil {
+		fmt.Fprintf(tw, "container\t%s\n", c.Type)
+		if limit := c.CPULimit(); limit > 0 {
+			fmt.Fprintf(tw, "cpu limit\t%.2f (%s every %s)\n", limit, c.CPUQuota, c.CPUPeriod)
+		} else {
+			fmt.Fprintf(tw, "cpu limit\tunlimited\n")
+		}
+		fmt.Fprintf(tw, "cpu shares\t%d\n", c.CPUShares)
+		fmt.Fprintf(tw, "effective cpus\t%d\n", c.EffectiveCPUCount)
+		fmt.Fprintf(tw, "memory limit\t%s\n", formatLimit(c.MemoryLimit))
+		fmt.Fprintf(tw, "memory soft limit\t%s\n", formatLimit(c.MemorySoftLimit))
+		fmt.Fprintf(tw, "memory and swap limit\t%s\n", formatLimit(c.SwapMemoryLimit))
+	} else {
+		fmt.Fprintf(tw, "container\tnone\n")
+	}
+	fmt.Fprintln(tw)
+	s := &r.Summary
+	fmt.Fprintf(tw, "average cpus\t%.2f\n", s.AverageCPUs)
+	fmt.Fprintf(tw, "peak cpus\t%.2f\n", s.PeakCPUs)
+	fmt.Fprintf(tw, "throttled periods\t%d of %d (%.1f%%)\n", s.ThrottledSlices, s.ElapsedSlices, 100*s.ThrottledRatio)
+	fmt.Fprintf(tw, "throttled time\t%s\n", s.ThrottledTime)
+	fmt.Fprintf(tw, "peak memory usage\t%s\n", report.FormatBytes(s.PeakMemoryUsage))
+	fmt.Fprintf(tw, "memory failures\t%d\n", s.MemoryFailures)
+	fmt.Fprintf(tw, "block io\t%d requests, %s\n", s.IORequests, report.FormatBytes(s.IOBytes))
+	fmt.Fprintf(tw, "throttled samples\t%d of %d (%.1f%%)\n", r.Throttled.ThrottledSamples, r.Throttled.Samples, 100*r.Throttled.Ratio)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "START\tEND\tPERIODS\tTHROTTLED\tTHROTTLED TIME\tSAMPLES")
+	for _, t := range r.Throttling {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%d\n", t.Start.UTC().Format(time.RFC3339Nano), t.End.UTC().Format(time.RFC3339Nano),
+			t.ElapsedSlices, t.ThrottledSlices, t.ThrottledTime, t.Samples)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "THROTTLED SAMPLES\tMETHOD")
+	for _, m := range r.Throttled.Methods {
+		fmt.Fprintf(tw, "%d\t%s\n", m.Samples, report.OrDash(m.Name))
+	}
+	return tw.Flush()
+}
+
+func formatLimit(n int64) string {
+	if n < 0 {
+		return "unlimited"
+	}
+	return report.FormatBytes(n)
+}
@@ -0,0 +1,188 @@
+package container
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	containerConfiguration = 100
+	containerCPUUsage      = 101
+	containerCPUThrottling = 102
+	containerMemoryUsage   = 103
+	containerIOUsage       = 104
+	executionSample        = 105
+)
+
+func containerRecording() []byte {
+	r := jfrtest.New()
+	start := jfrtest.Field{Name: "startTime", Class: jfrtest.Long}
+	micros := func(name string) jfrtest.Field {
+		return jfrtest.Field{Name: name, Class: jfrtest.Long, Annotation: jfrtest.Timespan, Value: "MICROSECONDS"}
+	}
+	nanos := func(name string) jfrtest.Field {
+		return jfrtest.Field{Name: name, Class: jfrtest.Long, Annotation: jfrtest.Timespan, Value: "NANOSECONDS"}
+	}
+	bytes := func(name string) jfrtest.Field {
+		return jfrtest.Field{Name: name, Class: jfrtest.Long, Annotation: jfrtest.DataAmount, Value: "BYTES"}
+	}
+	count := func(name string) jfrtest.Field {
+		return jfrtest.Field{Name: name, Class: jfrtest.Long}
+	}
+	// before JDK 21, without hostTotalMemory
+	r.EventClass(containerConfiguration, types.ContainerConfiguration, start,
+		jfrtest.Field{Name: "containerType", Class: jfrtest.String},
+		micros("cpuSlicePeriod"), micros("cpuQuota"), count("cpuShares"), count("effectiveCpuCount"),
+		bytes("memorySoftLimit"), bytes("memoryLimit"), bytes("swapMemoryLimit"))
+	r.EventClass(containerCPUUsage, types.ContainerCPUUsage, start,
+		nanos("cpuTime"), nanos("cpuUserTime"), nanos("cpuSystemTime"))
+	r.EventClass(containerCPUThrottling, types.ContainerCPUThrottling, start,
+		count("cpuElapsedSlices"), count("cpuThrottledSlices"), nanos("cpuThrottledTime"))
+	r.EventClass(containerMemoryUsage, types.ContainerMemoryUsage, start,
+		count("memoryFailCount"), bytes("memoryUsage"), bytes("swapMemoryUsage"))
+	r.EventClass(containerIOUsage, types.ContainerIOUsage, start,
+		count("serviceRequests"), bytes("dataTransferred"))
+	r.EventClass(executionSample, types.ExecutionSample, start,
+		jfrtest.Field{Name: "sampledThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "state", Class: jfrtest.ThreadState, CPool: true})
+
+	r.ClassConstant(1, "com/foo/Codec")
+	r.Constant(jfrtest.Symbol, 10, "encode")
+	r.Constant(jfrtest.Symbol, 11, "decode")
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(1), uint64(11))
+	r.StackTrace(1, 1)
+	r.StackTrace(2, 2)
+	r.Constant(jfrtest.Thread, 1, "worker-1", uint64(11), "worker-1", uint64(1), false)
+	r.Constant(jfrtest.ThreadState, 1, "STATE_RUNNABLE")
+	r.Constant(jfrtest.ThreadState, 2, "STATE_SLEEPING")
+
+	second := uint64(time.Second)
+	at := func(seconds uint64) uint64 { return jfrtest.StartTicks + seconds*second }
+	unlimited := uint64(1<<64 - 1)
+	r.Event(containerConfiguration, at(0), "cgroupv2", uint64(100_000), uint64(200_000), uint64(1024), uint64(2),
+		unlimited, uint64(512<<20), uint64(1<<30))
+	for i, v := range [][3]uint64{{0, 0, 0}, {10, 8, 2}, {14, 11, 3}} {
+		r.Event(containerCPUUsage, at(uint64(10*i)), v[0]*second, v[1]*second, v[2]*second)
+	}
+	for i, v := range [][3]uint64{{1000, 0, 0}, {1100, 0, 0}, {1200, 40, 2 * second}} {
+		r.Event(containerCPUThrottling, at(uint64(10*i)), v[0], v[1], v[2])
+	}
+	r.Event(containerMemoryUsage, at(0), uint64(0), uint64(100<<20), uint64(100<<20))
+	r.Event(containerMemoryUsage, at(10), uint64(3), uint64(500<<20), uint64(600<<20))
+	r.Event(containerIOUsage, at(0), uint64(100), uint64(4096))
+	r.Event(containerIOUsage, at(20), uint64(150), uint64(4096+8192))
+
+	r.Event(executionSample, at(5), uint64(1), uint64(2), uint64(1))
+	r.Event(executionSample, at(15), uint64(1), uint64(1), uint64(1))
+	r.Event(executionSample, at(16), uint64(1), uint64(1), uint64(1))
+	r.Event(executionSample, at(17), uint64(1), uint64(2), uint64(1))
+	// wall clock samples of async-profiler are left out
+	r.Event(executionSample, at(18), uint64(1), uint64(2), uint64(2))
+	// after the last throttling event
+	r.Event(executionSample, at(25), uint64(1), uint64(2), uint64(1))
+	return r.Bytes()
+}
+
+func TestReport(t *testing.T) {
+	buf := containerRecording()
+	report, err := FromParser(parser.NewParser(buf, parser.Options{}), Options{TopMethods: 1})
+	require.NoError(t, err)
+	chunks, err := parser.Parse(bytes.NewReader(buf))
+	require.NoError(t, err)
+	legacy, err := FromChunks(chunks, Options{TopMethods: 1})
+	require.NoError(t, err)
+	assert.Equal(t, report, legacy)
+
+	at := func(seconds int64) time.Time {
+		return time.Unix(0, jfrtest.StartNanos+seconds*int64(time.Second))
+	}
+	assert.Equal(t, &Configuration{
+		Type:              "cgroupv2",
+		CPUQuota:          200 * time.Millisecond,
+		CPUPeriod:         100 * time.Millisecond,
+		CPUShares:         1024,
+		EffectiveCPUCount: 2,
+		MemoryLimit:       512 << 20,
+		MemorySoftLimit:   -1,
+		SwapMemoryLimit:   1 << 30,
+	}, report.Configuration)
+	assert.Equal(t, 2.0, report.Configuration.CPULimit())
+	assert.Equal(t, []CPUInterval{
+		{Start: at(0), End: at(10), Total: 10 * time.Second, User: 8 * time.Second, System: 2 * time.Second, CPUs: 1},
+		{Start: at(10), End: at(20), Total: 4 * time.Second, User: 3 * time.Second, System: time.Second, CPUs: 0.4},
+	}, report.CPU)
+	assert.Equal(t, []ThrottlingInterval{
+		{Start: at(0), End: at(10), ElapsedSlices: 100, Samples: 1},
+		{Start: at(10), End: at(20), ElapsedSlices: 100, ThrottledSlices: 40, ThrottledTime: 2 * time.Second, Samples: 3},
+	}, report.Throttling)
+	assert.Equal(t, []MemorySample{
+		{Time: at(0), Usage: 100 << 20, SwapUsage: 100 << 20},
+		{Time: at(10), Usage: 500 << 20, SwapUsage: 600 << 20, Failures: 3},
+	}, report.Memory)
+	assert.Equal(t, []IOInterval{{Start: at(0), End: at(20), Requests: 50, Bytes: 8192}}, report.IO)
+	assert.Equal(t, Summary{
+		AverageCPUs:     0.7,
+		PeakCPUs:        1,
+		ElapsedSlices:   200,
+		ThrottledSlices: 40,
+		ThrottledRatio:  0.2,
+		ThrottledTime:   2 * time.Second,
+		PeakMemoryUsage: 500 << 20,
+		MemoryFailures:  3,
+		IORequests:      50,
+		IOBytes:         8192,
+	}, report.Summary)
+	assert.Equal(t, Throttled{
+		Samples:          4,
+		ThrottledSamples: 3,
+		Ratio:            0.75,
+		Methods:          []Method{{Name: "com.foo.Codec.encode", Samples: 2}},
+	}, report.Throttled)
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	assert.Equal(t, `container              cgroupv2
+cpu limit              2.00 (200ms every 100ms)
+cpu shares             1024
+effective cpus         2
+memory limit           512.0MiB
+memory soft limit      unlimited
+memory and swap limit  1024.0MiB
+
+average cpus       0.70
+peak cpus          1.00
+throttled periods  40 of 200 (20.0%)
+throttled time     2s
+peak memory usage  500.0MiB
+memory failures    3
+block io           50 requests, 0.0MiB
+throttled samples  3 of 4 (75.0%)
+
+START                 END                   PERIODS  THROTTLED  THROTTLED TIME  SAMPLES
+2023-11-14T22:13:20Z  2023-11-14T22:13:30Z  100      0          0s              1
+2023-11-14T22:13:30Z  2023-11-14T22:13:40Z  100      40         2s              3
+
+THROTTLED SAMPLES  METHOD
+2                  com.foo.Codec.encode
+`, text.String())
+}
+
+func TestNotContainerized(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+	assert.Nil(t, report.Configuration)
+	assert.Empty(t, report.Throttling)
+	assert.Zero(t, report.Throttled.Samples)
+}
@@ -0,0 +1,292 @@
+// Package environment summarizes the environment a recording was taken in:
+// the JVM, the operating system, the hardware, the effective flags, the
+// system properties and the environment variables, with the values of the
+// secret looking keys redacted.
+package environment
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+const DefaultMask = "****"
+
+// DefaultSecretKeys are the key fragments redacted when Options.SecretKeys
+// is nil.
+var DefaultSecretKeys = []string{"password", "passwd", "secret", "token", "key", "credential"}
+
+type Options struct {
+	// SecretKeys are matched case insensitively against the names of the
+	// properties, variables, flags and arguments; the values of the names
+	// containing one of them are masked. Nil means DefaultSecretKeys, an
+	// empty slice disables the redaction.
+	SecretKeys []string
+	// Mask replaces the redacted values, DefaultMask when empty.
+	Mask string
+}
+
+type JVM struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Arguments are the JVM options of the command line, Flags the ones of
+	// the flags file and JavaArguments the main class or jar and its
+	// arguments.
+	Arguments     string    `json:"arguments"`
+	Flags         string    `json:"flags"`
+	JavaArguments string    `json:"javaArguments"`
+	StartTime     time.Time `json:"startTime"`
+	PID           int64     `json:"pid"`
+}
+
+type CPU struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Sockets         int64  `json:"sockets"`
+	Cores           int64  `json:"cores"`
+	HardwareThreads int64  `json:"hardwareThreads"`
+}
+
+type Flag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Origin tells where the value comes from, such as Default, Ergonomic
+	// or Command line.
+	Origin string `json:"origin"`
+}
+
+type RecordingEnvironment struct {
+	JVM JVM    `json:"jvm"`
+	OS  string `json:"os"`
+	CPU CPU    `json:"cpu"`
+	// Virtualization is the hypervisor or No virtualization detected, empty
+	// when the recording does not tell.
+	Virtualization string `json:"virtualization"`
+	// Container is the type of the container the JVM detected, such as
+	// cgroupv2, empty outside of a container.
+	Container string `json:"container"`
+	// Flags are ordered by name.
+	Flags                []Flag            `json:"flags"`
+	SystemProperties     map[string]string `json:"systemProperties"`
+	EnvironmentVariables map[string]string `json:"environmentVariables"`
+}
+
+// FromChunks summarizes the environment of chunks read by parser.Parse. The
+// later chunks override the values of the earlier ones.
+func FromChunks(chunks []*parser.Chunk, options Options) (*RecordingEnvironment, error) {
+	r := newRedactor(options)
+	env := &RecordingEnvironment{
+		SystemProperties:     make(map[string]string),
+		EnvironmentVariables: make(map[string]string),
+	}
+	flags := make(map[string]Flag)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.VmInfo) {
+			jvm, err := readJVM(event)
+			if err != nil {
+				return nil, err
+			}
+			jvm.Arguments = r.arguments(jvm.Arguments)
+			jvm.Flags = r.arguments(jvm.Flags)
+			jvm.JavaArguments = r.arguments(jvm.JavaArguments)
+			env.JVM = jvm
+		}
+		for _, event := range chunk.Apply(filters.OsInformation) {
+			env.OS, _ = attributes.OsVersion.GetValue(event)
+		}
+		for _, event := range chunk.Apply(filters.CpuInformation) {
+			env.CPU.Name, _ = attributes.Cpu.GetValue(event)
+			env.CPU.Description, _ = attributes.CpuDescription.GetValue(event)
+			env.CPU.Sockets, _ = attributes.Sockets.GetValue(event)
+			env.CPU.Cores, _ = attributes.Cores.GetValue(event)
+			env.CPU.HardwareThreads, _ = attributes.HwThreads.GetValue(event)
+		}
+		for _, event := range chunk.Apply(filters.Virtualization) {
+			env.Virtualization, _ = attributes.VirtualizationName.GetValue(event)
+		}
+		for _, event := range chunk.Apply(filters.ContainerConfiguration) {
+			env.Container, _ = attributes.ContainerType.GetValue(event)
+		}
+		for _, event := range chunk.Apply(filters.JvmFlags) {
+			f, err := readFlag(event)
+			if err != nil {
+				return nil, err
+			}
+			// the numbers and booleans, such as VerifyHashTableKeys, are
+			// no secrets
+			if event.ClassMetadata.Name == types.StringFlag {
+				f.Value = r.value(f.Name, f.Value)
+			}
+			flags[f.Name] = f
+		}
+		for _, event := range chunk.Apply(filters.SystemProperties) {
+			key, value, err := readKeyValue(event)
+			if err != nil {
+				return nil, err
+			}
+			env.SystemProperties[key] = r.value(key, value)
+		}
+		for _, event := range chunk.Apply(filters.EnvironmentVariable) {
+			key, value, err := readKeyValue(event)
+			if err != nil {
+				return nil, err
+			}
+			env.EnvironmentVariables[key] = r.value(key, value)
+		}
+	}
+	for _, f := range flags {
+		env.Flags = append(env.Flags, f)
+	}
+	sort.Slice(env.Flags, func(i, j int) bool {
+		return env.Flags[i].Name < env.Flags[j].Name
+	})
+	return env, nil
+}
+
+func readJVM(event *parser.GenericEvent) (JVM, error) {
+	var (
+		jvm JVM
+		err error
+	)
+	if jvm.Name, err = attributes.JvmName.GetValue(event); err != nil {
+		return jvm, err
+	}
+	if jvm.Version, err = attributes.JvmVersion.GetValue(event); err != nil {
+		return jvm, err
+	}
+	jvm.Arguments, _ = attributes.JvmArguments.GetValue(event)
+	jvm.Flags, _ = attributes.JvmFlags.GetValue(event)
+	jvm.JavaArguments, _ = attributes.JavaArguments.GetValue(event)
+	jvm.PID, _ = attributes.Pid.GetValue(event)
+	if start, err := attributes.JVMStartTime.GetValue(event); err == nil {
+		if jvm.StartTime, err = units.ToTime(start); err != nil {
+			return jvm, err
+		}
+	}
+	return jvm, nil
+}
+
+func readFlag(event *parser.GenericEvent) (Flag, error) {
+	var (
+		f   Flag
+		err error
+	)
+	if f.Name, err = attributes.FlagName.GetValue(event); err != nil {
+		return f, err
+	}
+	f.Origin, _ = attributes.FlagOrigin.GetValue(event)
+	switch event.ClassMetadata.Name {
+	case types.BooleanFlag:
+		var v bool
+		v, err = attributes.BooleanFlagValue.GetValue(event)
+		f.Value = strconv.FormatBool(v)
+	case types.DoubleFlag:
+		var v float64
+		v, err = attributes.DoubleFlagValue.GetValue(event)
+		f.Value = strconv.FormatFloat(v, 'g', -1, 64)
+	case types.StringFlag:
+		f.Value, err = attributes.StringFlagValue.GetValue(event)
+	default:
+		var v int64
+		v, err = attributes.IntFlagValue.GetValue(event)
+		// the values are read signed
+		switch event.ClassMetadata.Name {
+		case types.UintFlag:
+			f.Value = strconv.FormatUint(uint64(uint32(v)), 10)
+		case types.UlongFlag:
+			f.Value = strconv.FormatUint(uint64(v), 10)
+		default:
+			f.Value = strconv.FormatInt(v, 10)
+		}
+	}
+	return f, err
+}
+
+func readKeyValue(event *parser.GenericEvent) (string, string, error) {
+	key, err := attributes.PropertyKey.GetValue(event)
+	if err != nil {
+		return "", "", err
+	}
+	value, _ := attributes.PropertyValue.GetValue(event)
+	return key, value, nil
+}
+
+var (
+	argument = regexp.MustCompile(`\S+`)
+	option   = regexp.MustCompile(`^--?[A-Za-z][A-Za-z0-9._-]*$`)
+)
+
+type redactor struct {
+	keys []string
+	mask string
+}
+
+func newRedactor(options Options) *redactor {
+	keys := options.SecretKeys
+	if keys == nil {
+		keys = DefaultSecretKeys
+	}
+	r := &redactor{mask: options.Mask}
+	for _, k := range keys {
+		if k != "" {
+			r.keys = append(r.keys, strings.ToLower(k))
+		}
+	}
+	if r.mask == "" {
+		r.mask = DefaultMask
+	}
+	return r
+}
+
+func (r *redactor) secret(name string) bool {
+	name = strings.ToLower(name)
+	for _, k := range r.keys {
+		if strings.Contains(name, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) value(name, value string) string {
+	if value != "" && r.secret(name) {
+		return r.mask
+	}
+	return value
+}
+
+// arguments masks the values of the secret arguments of a command line,
+// given as -Dname=value, --name=value, name=value or --name value.
+func (r *redactor) arguments(args string) string {
+	next := false
+	return argument.ReplaceAllStringFunc(args, func(arg string) string {
+		if next {
+			next = false
+			return r.mask
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			if value == "" || !r.secret(strings.TrimPrefix(name, "-D")) {
+				return arg
+			}
+			return name + "=" + r.mask
+		}
+		next = option.MatchString(arg) && r.secret(arg)
+		return arg
+	})
+}
+
+func (env *RecordingEnvironment) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(env)
+}
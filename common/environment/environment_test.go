@@ -0,0 +1,122 @@
+package environment
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromChunks(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	env, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "OpenJDK 64-Bit Server VM", env.JVM.Name)
+	assert.Equal(t, "movies.Server", env.JVM.JavaArguments)
+	assert.Equal(t, int64(70305), env.JVM.PID)
+	assert.Equal(t, time.UnixMilli(1673597210661), env.JVM.StartTime)
+	assert.Contains(t, env.JVM.Arguments, "-Ddd.agent.host=127.0.0.1")
+	assert.Equal(t, "AArch64", env.CPU.Name)
+	assert.Contains(t, env.OS, "Darwin")
+	assert.Equal(t, "Eclipse Adoptium", env.SystemProperties["java.vm.vendor"])
+
+	assert.Len(t, env.Flags, 1096)
+	assert.Contains(t, env.Flags, Flag{Name: "MaxHeapSize", Value: "4294967296", Origin: "Ergonomic"})
+	assert.Contains(t, env.Flags, Flag{Name: "UseG1GC", Value: "true", Origin: "Ergonomic"})
+	// not a secret, despite its name
+	assert.Contains(t, env.Flags, Flag{Name: "VerifyHashTableKeys", Value: "true", Origin: "Default"})
+}
+
+const (
+	jvmInformation  = 100
+	systemProperty  = 101
+	environmentVar  = 102
+	flagValueOrigin = 103
+	stringFlag      = 104
+)
+
+func secretRecording() []byte {
+	r := jfrtest.New()
+	r.Class(flagValueOrigin, "jdk.types.FlagValueOrigin",
+		jfrtest.Field{Name: "description", Class: jfrtest.String})
+	r.EventClass(jvmInformation, "jdk.JVMInformation",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "jvmName", Class: jfrtest.String},
+		jfrtest.Field{Name: "jvmVersion", Class: jfrtest.String},
+		jfrtest.Field{Name: "jvmArguments", Class: jfrtest.String},
+		jfrtest.Field{Name: "jvmFlags", Class: jfrtest.String},
+		jfrtest.Field{Name: "javaArguments", Class: jfrtest.String},
+		jfrtest.Field{Name: "jvmStartTime", Class: jfrtest.Long, Annotation: jfrtest.Timestamp, Value: "MILLISECONDS_SINCE_EPOCH"},
+		jfrtest.Field{Name: "pid", Class: jfrtest.Long})
+	r.EventClass(systemProperty, "jdk.InitialSystemProperty",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "key", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	r.EventClass(environmentVar, "jdk.InitialEnvironmentVariable",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "key", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	r.EventClass(stringFlag, "jdk.StringFlag",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "name", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String},
+		jfrtest.Field{Name: "origin", Class: flagValueOrigin, CPool: true})
+
+	r.Constant(flagValueOrigin, 1, "Command line")
+
+	start := uint64(jfrtest.StartTicks)
+	r.Event(jvmInformation, start, "OpenJDK 64-Bit Server VM", "21.0.1",
+		"-Xmx1g -Ddb.user=app -Ddb.password=hunter2 -Dapi.Token= --trust-store-password changeit -XX:+UseG1GC",
+		"", "app.jar --apiKey=abc --port 8080", uint64(1_700_000_000_000), uint64(42))
+	r.Event(systemProperty, start, "db.password", "hunter2")
+	r.Event(systemProperty, start, "javax.net.ssl.keyStore", "/etc/keystore.p12")
+	r.Event(systemProperty, start, "user.name", "app")
+	r.Event(environmentVar, start, "AWS_SECRET_ACCESS_KEY", "wJalrXUtnFEMI")
+	r.Event(environmentVar, start, "HOME", "/home/app")
+	r.Event(stringFlag, start, "OnOutOfMemoryError", "kill -9 %p", uint64(1))
+	return r.Bytes()
+}
+
+func TestRedaction(t *testing.T) {
+	chunks, err := parser.Parse(bytes.NewReader(secretRecording()))
+	require.NoError(t, err)
+	env, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, JVM{
+		Name:          "OpenJDK 64-Bit Server VM",
+		Version:       "21.0.1",
+		Arguments:     "-Xmx1g -Ddb.user=app -Ddb.password=**** -Dapi.Token= --trust-store-password **** -XX:+UseG1GC",
+		JavaArguments: "app.jar --apiKey=**** --port 8080",
+		StartTime:     time.UnixMilli(1_700_000_000_000),
+		PID:           42,
+	}, env.JVM)
+	assert.Equal(t, map[string]string{
+		"db.password":            "****",
+		"javax.net.ssl.keyStore": "****",
+		"user.name":              "app",
+	}, env.SystemProperties)
+	assert.Equal(t, map[string]string{
+		"AWS_SECRET_ACCESS_KEY": "****",
+		"HOME":                  "/home/app",
+	}, env.EnvironmentVariables)
+	assert.Equal(t, []Flag{{Name: "OnOutOfMemoryError", Value: "kill -9 %p", Origin: "Command line"}}, env.Flags)
+
+	env, err = FromChunks(chunks, Options{SecretKeys: []string{"PASSWORD"}, Mask: "<redacted>"})
+	require.NoError(t, err)
+	assert.Equal(t, "-Xmx1g -Ddb.user=app -Ddb.password=<redacted> -Dapi.Token= --trust-store-password <redacted> -XX:+UseG1GC", env.JVM.Arguments)
+	assert.Equal(t, "app.jar --apiKey=abc --port 8080", env.JVM.JavaArguments)
+	assert.Equal(t, "/etc/keystore.p12", env.SystemProperties["javax.net.ssl.keyStore"])
+	assert.Equal(t, "wJalrXUtnFEMI", env.EnvironmentVariables["AWS_SECRET_ACCESS_KEY"])
+
+	env, err = FromChunks(chunks, Options{SecretKeys: []string{}})
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", env.SystemProperties["db.password"])
+	assert.Equal(t, "app.jar --apiKey=abc --port 8080", env.JVM.JavaArguments)
+}
@@ -0,0 +1,273 @@
+// Package exceptions reports the exceptions and errors thrown during a
+// recording, from the jdk.JavaExceptionThrow, jdk.JavaErrorThrow and
+// jdk.ExceptionStatistics events.
+package exceptions
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+	types2 "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// DefaultMessagePrefix is the number of characters of the messages the
+// exceptions are grouped by, unless Options.MessagePrefix says otherwise.
+const DefaultMessagePrefix = 40
+
+type Options struct {
+	// BucketDuration counts the exceptions in time buckets of this length,
+	// aligned to the Unix epoch. Zero counts the whole recording at once.
+	BucketDuration time.Duration
+	// MessagePrefix is the number of characters of the messages the exceptions
+	// are grouped by, DefaultMessagePrefix when zero. Negative values group
+	// the exceptions by class only.
+	MessagePrefix int
+}
+
+// Key groups the thrown exceptions.
+type Key struct {
+	// Class is the Java name of the thrown class, such as java.io.IOException.
+	Class string
+	// Message is the prefix of the message, if any.
+	Message string
+	// Error is set for jdk.JavaErrorThrow events.
+	Error bool
+}
+
+type Count struct {
+	// Start of the time bucket, zero without buckets.
+	Start time.Time
+	Key
+	Count int64
+}
+
+// Statistic is the number of throwables created by the JVM since it started,
+// including those the throw events are not recorded for.
+type Statistic struct {
+	Time       time.Time
+	Throwables int64
+}
+
+type Report struct {
+	BucketDuration time.Duration
+	// Counts are ordered by time bucket, then by decreasing count.
+	Counts     []Count
+	Statistics []Statistic
+}
+
+type bucketKey struct {
+	start int64
+	Key
+}
+
+type builder struct {
+	options    Options
+	counts     map[bucketKey]int64
+	statistics []Statistic
+}
+
+func newBuilder(options Options) *builder {
+	if options.MessagePrefix == 0 {
+		options.MessagePrefix = DefaultMessagePrefix
+	}
+	return &builder{
+		options: options,
+		counts:  make(map[bucketKey]int64),
+	}
+}
+
+// add counts a throw. The class name may be in the internal form of the JVM,
+// java/io/IOException.
+func (b *builder) add(t time.Time, class, message string, isError bool) {
+	key := bucketKey{Key: Key{
+		Class:   strings.ReplaceAll(class, "/", "."),
+		Message: b.prefix(message),
+		Error:   isError,
+	}}
+	if b.options.BucketDuration > 0 {
+		key.start = t.Truncate(b.options.BucketDuration).UnixNano()
+	}
+	b.counts[key]++
+}
+
+func (b *builder) prefix(message string) string {
+	n := b.options.MessagePrefix
+	if n < 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(message) <= n {
+		return message
+	}
+	for i := range message {
+		if n == 0 {
+			return message[:i]
+		}
+		n--
+	}
+	return message
+}
+
+func (b *builder) addStatistic(t time.Time, throwables int64) {
+	b.statistics = append(b.statistics, Statistic{Time: t, Throwables: throwables})
+}
+
+func (b *builder) report() *Report {
+	r := &Report{
+		BucketDuration: b.options.BucketDuration,
+		Counts:         make([]Count, 0, len(b.counts)),
+		Statistics:     b.statistics,
+	}
+	for key, n := range b.counts {
+		c := Count{Key: key.Key, Count: n}
+		if b.options.BucketDuration > 0 {
+			c.Start = time.Unix(0, key.start)
+		}
+		r.Counts = append(r.Counts, c)
+	}
+	sort.Slice(r.Counts, func(i, j int) bool {
+		a, b := &r.Counts[i], &r.Counts[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		if a.Message != b.Message {
+			return a.Message < b.Message
+		}
+		return !a.Error && b.Error
+	})
+	sort.SliceStable(r.Statistics, func(i, j int) bool {
+		return r.Statistics[i].Time.Before(r.Statistics[j].Time)
+	})
+	return r
+}
+
+// FromParser reads the remaining events of the parser into a report.
+func FromParser(p *parser.Parser, options Options) (*Report, error) {
+	b := newBuilder(options)
+	err := report.ParseEvents(p, func(typ def.TypeID) {
+		switch typ {
+		case p.TypeMap.T_EXCEPTION_THROW:
+			e := &p.JavaExceptionThrow
+			if constructsError(p, e.StackTrace) {
+				return
+			}
+			b.add(p.TicksToTime(e.StartTime), report.ClassName(p, e.ThrownClass), e.Message, false)
+		case p.TypeMap.T_ERROR_THROW:
+			e := &p.JavaErrorThrow
+			b.add(p.TicksToTime(e.StartTime), report.ClassName(p, e.ThrownClass), e.Message, true)
+		case p.TypeMap.T_EXCEPTION_STATISTICS:
+			e := &p.ExceptionStatistics
+			b.addStatistic(p.TicksToTime(e.StartTime), int64(e.Throwables))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.report(), nil
+}
+
+// constructsError reports whether a stack trace starts in the constructor of
+// java.lang.Error: errors are recorded twice, as jdk.JavaErrorThrow and as the
+// jdk.JavaExceptionThrow of the Throwable constructor.
+func constructsError(p *parser.Parser, ref types2.StackTraceRef) bool {
+	st := p.GetStacktrace(ref)
+	if st == nil {
+		return false
+	}
+	for _, f := range st.Frames {
+		m := p.GetMethod(f.Method)
+		if m == nil || p.GetSymbolString(m.Name) != "<init>" {
+			return false
+		}
+		if report.ClassName(p, m.Type) == "java/lang/Error" {
+			return true
+		}
+	}
+	return false
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.Throwables) {
+			isError := event.ClassMetadata.Name == types.ErrorsThrown
+			stackTrace, err := attributes.EventStacktrace.GetValue(event)
+			if err == nil && !isError && constructsErrorLegacy(stackTrace) {
+				continue
+			}
+			startTime, err := attributes.StartTime.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			var class string
+			if cls, err := attributes.ThrownClass.GetValue(event); err == nil && cls != nil && cls.Name != nil {
+				class = cls.Name.String
+			}
+			// the message is null for most exceptions
+			message, _ := attributes.ThrowableMessage.GetValue(event)
+			b.add(time.Unix(0, startTime.IntValue()), class, message, isError)
+		}
+		for _, event := range chunk.Apply(filters.ThrowablesStatistics) {
+			startTime, err := attributes.StartTime.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			throwables, err := attributes.Throwables.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			b.addStatistic(time.Unix(0, startTime.IntValue()), throwables)
+		}
+	}
+	return b.report(), nil
+}
+
+func constructsErrorLegacy(st *parser.StackTrace) bool {
+	if st == nil {
+		return false
+	}
+	for _, f := range st.Frames {
+		if f.Method == nil || f.Method.Name == nil || f.Method.Name.String != "<init>" {
+			return false
+		}
+		if f.Method.Type != nil && f.Method.Type.Name != nil && f.Method.Type.Name.String == "java/lang/Error" {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes the counts as a table.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "START\tCOUNT\tCLASS\tMESSAGE")
+	for _, c := range r.Counts {
+		start := "-"
+		if !c.Start.IsZero() {
+			start = c.Start.UTC().Format(time.RFC3339)
+		}
+		class := c.Class
+		if c.Error {
+			class += " (error)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%q\n", start, c.Count, class, c.Message)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,131 @@
+package exceptions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	exceptionThrow = 120 + iota
+	errorThrow
+	exceptionStatistics
+)
+
+func throwRecording() []byte {
+	r := jfrtest.New()
+	throw := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "duration", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		{Name: "message", Class: jfrtest.String},
+		{Name: "thrownClass", Class: jfrtest.Class, CPool: true},
+	}
+	r.EventClass(exceptionThrow, "jdk.JavaExceptionThrow", throw...)
+	r.EventClass(errorThrow, "jdk.JavaErrorThrow", throw...)
+	r.EventClass(exceptionStatistics, "jdk.ExceptionStatistics",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "throwables", Class: jfrtest.Long})
+
+	r.ClassConstant(1, "java/io/IOException")
+	r.ClassConstant(2, "java/lang/OutOfMemoryError")
+	r.ClassConstant(3, "java/lang/Throwable")
+	r.ClassConstant(4, "java/lang/Error")
+	r.ClassConstant(5, "Client")
+	r.Constant(jfrtest.Symbol, 10, "<init>")
+	r.Constant(jfrtest.Symbol, 11, "connect")
+	r.Constant(jfrtest.Method, 1, uint64(3), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(4), uint64(10))
+	r.Constant(jfrtest.Method, 3, uint64(5), uint64(11))
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.StackTrace(1, 1, 3)
+	r.StackTrace(2, 1, 2, 3)
+
+	const second = uint64(time.Second)
+	start := uint64(jfrtest.StartTicks)
+	r.Event(exceptionThrow, start, uint64(0), uint64(1), uint64(1), "connection refused: host-a", uint64(1))
+	r.Event(exceptionThrow, start+second/2, uint64(0), uint64(1), uint64(1), "connection refused: host-b", uint64(1))
+	r.Event(exceptionThrow, start+second, uint64(0), uint64(1), uint64(1), "connection refused: host-a", uint64(1))
+	// an error is thrown once and recorded twice
+	r.Event(exceptionThrow, start+second, uint64(0), uint64(1), uint64(2), "Java heap space", uint64(2))
+	r.Event(errorThrow, start+second, uint64(0), uint64(1), uint64(2), "Java heap space", uint64(2))
+	r.Event(exceptionStatistics, start+2*second, uint64(0), uint64(1), uint64(0), uint64(42))
+	return r.Bytes()
+}
+
+func TestReport(t *testing.T) {
+	options := Options{BucketDuration: time.Second, MessagePrefix: len("connection refused")}
+	start := time.Unix(0, jfrtest.StartNanos)
+	expected := &Report{
+		BucketDuration: time.Second,
+		Counts: []Count{
+			{Start: start, Key: Key{Class: "java.io.IOException", Message: "connection refused"}, Count: 2},
+			{Start: start.Add(time.Second), Key: Key{Class: "java.io.IOException", Message: "connection refused"}, Count: 1},
+			{Start: start.Add(time.Second), Key: Key{Class: "java.lang.OutOfMemoryError", Message: "Java heap space", Error: true}, Count: 1},
+		},
+		Statistics: []Statistic{{Time: start.Add(2 * time.Second), Throwables: 42}},
+	}
+
+	jfr := throwRecording()
+	t.Run("parser", func(t *testing.T) {
+		report, err := FromParser(parser.NewParser(jfr, parser.Options{}), options)
+		require.NoError(t, err)
+		assertReport(t, expected, report)
+	})
+	t.Run("chunks", func(t *testing.T) {
+		chunks, err := parser.Parse(bytes.NewReader(jfr))
+		require.NoError(t, err)
+		report, err := FromChunks(chunks, options)
+		require.NoError(t, err)
+		assertReport(t, expected, report)
+	})
+}
+
+func TestReportClasses(t *testing.T) {
+	report, err := FromParser(parser.NewParser(throwRecording(), parser.Options{}), Options{MessagePrefix: -1})
+	require.NoError(t, err)
+	assert.Equal(t, []Count{
+		{Key: Key{Class: "java.io.IOException"}, Count: 3},
+		{Key: Key{Class: "java.lang.OutOfMemoryError", Error: true}, Count: 1},
+	}, report.Counts)
+
+	var buf strings.Builder
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `START  COUNT  CLASS                               MESSAGE
+-      3      java.io.IOException                 ""
+-      1      java.lang.OutOfMemoryError (error)  ""
+`, buf.String())
+}
+
+func TestMessagePrefix(t *testing.T) {
+	b := newBuilder(Options{MessagePrefix: 3})
+	assert.Equal(t, "", b.prefix(""))
+	assert.Equal(t, "abc", b.prefix("abc"))
+	assert.Equal(t, "abc", b.prefix("abcd"))
+	assert.Equal(t, "äöü", b.prefix("äöüß"))
+}
+
+func assertReport(t *testing.T, expected, actual *Report) {
+	t.Helper()
+	require.Equal(t, len(expected.Counts), len(actual.Counts))
+	for i := range expected.Counts {
+		assert.True(t, expected.Counts[i].Start.Equal(actual.Counts[i].Start), "start of %d", i)
+		assert.Equal(t, expected.Counts[i].Key, actual.Counts[i].Key)
+		assert.Equal(t, expected.Counts[i].Count, actual.Counts[i].Count)
+	}
+	require.Equal(t, len(expected.Statistics), len(actual.Statistics))
+	for i := range expected.Statistics {
+		assert.True(t, expected.Statistics[i].Time.Equal(actual.Statistics[i].Time))
+		assert.Equal(t, expected.Statistics[i].Throwables, actual.Statistics[i].Throwables)
+	}
+}
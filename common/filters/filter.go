@@ -42,6 +42,9 @@ var (
 	DatadogProfilerConfig       = Types(types.DatadogProfilerConfig)
 	DatadogAllocationSample     = Types(types.DatadogAllocationSample)
 	DatadogHeapLiveObject       = Types(types.HeapLiveObject)
+	Malloc                      = Types(types.Malloc)
+	Free                        = Types(types.Free)
+	NativeMemory                = Types(types.Malloc, types.Free)
 )
 
 var (
@@ -62,6 +65,15 @@ var (
 	SweepCodeCache          = Types(types.SweepCodeCache)
 	CodeCache               = OrFilters(CodeCacheFull, CodeCacheStatistics, SweepCodeCache, CodeCacheConfig)
 	CpuInformation          = Types(types.CPUInformation)
+	OsInformation           = Types(types.OSInformation)
+	Virtualization          = Types(types.VirtualizationInformation)
+	ContainerConfiguration  = Types(types.ContainerConfiguration)
+	ContainerCPUUsage       = Types(types.ContainerCPUUsage)
+	ContainerCPUThrottling  = Types(types.ContainerCPUThrottling)
+	ContainerMemoryUsage    = Types(types.ContainerMemoryUsage)
+	ContainerIOUsage        = Types(types.ContainerIOUsage)
+	ContainerUsage          = Types(types.ContainerCPUUsage, types.ContainerCPUThrottling, types.ContainerMemoryUsage, types.ContainerIOUsage)
+	JvmFlags                = Types(types.BooleanFlag, types.StringFlag, types.DoubleFlag, types.LongFlag, types.IntFlag, types.UintFlag, types.UlongFlag)
 	GcConfig                = Types(types.GcConf)
 	HeapConfig              = Types(types.HeapConf)
 	BeforeGc                = AttributeEqual(attributes.GcWhen, "Before GC") //$NON-NLS-1$
@@ -92,6 +104,8 @@ var (
 	FilterExecutionSample   = Types(types.ExecutionSample)
 	DatadogExecutionSample  = Types(types.DatadogExecutionSample)
 	ContextSwitchRate       = Types(types.ContextSwitchRate)
+	ThreadStatistics        = Types(types.ThreadStatistics)
+	NetworkUtilization      = Types(types.NetworkUtilization)
 	CpuLoad                 = Types(types.CpuLoad)
 	GcPause                 = Types(types.GcPause)
 	GcPausePhase            = Types(types.GcPauseL1, types.GcPauseL2, types.GcPauseL3, types.GcPauseL4)
@@ -106,6 +120,7 @@ var (
 	Compilation             = Types(types.Compilation)
 	CompilerFailure         = Types(types.CompilerFailure)
 	CompilerStats           = Types(types.CompilerStats)
+	Deoptimization          = Types(types.Deoptimization)
 	OsMemorySummary         = Types(types.OSMemorySummary)
 	HeapSummary             = Types(types.HeapSummary)
 	HeapSummaryBeforeGc     = AndFilters(HeapSummary, BeforeGc)
@@ -128,6 +143,10 @@ var (
 	NativeMethodSample           = Types(types.NativeMethodSample)
 	ThreadStart                  = Types(types.JavaThreadStart)
 	ThreadEnd                    = Types(types.JavaThreadEnd)
+	VirtualThreadStart           = Types(types.VirtualThreadStart)
+	VirtualThreadEnd             = Types(types.VirtualThreadEnd)
+	VirtualThreadPinned          = Types(types.VirtualThreadPinned)
+	VirtualThreadSubmitFailed    = Types(types.VirtualThreadSubmitFailed)
 	DatadogDirectAllocationTotal = Types(types.DatadogDirectAllocationTotal)
 	DatadogHeapUsage             = Types(types.DatadogHeapUsage)
 	DatadogDeadlockedThread      = Types(types.DatadogDeadlockedThread)
@@ -0,0 +1,405 @@
+// Package gc reports the garbage collections of a recording: the pauses of
+// each collector, the heap occupancy around the collections and the longest
+// pauses with their phases.
+package gc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+// DefaultTopPauses is the number of longest pauses reported, unless
+// Options.TopPauses says otherwise.
+const DefaultTopPauses = 10
+
+type Options struct {
+	// TopPauses is the number of longest pauses reported with their phases,
+	// DefaultTopPauses when zero.
+	TopPauses int
+}
+
+// Pauses summarizes the stop-the-world pauses of a collector or of all of
+// them.
+type Pauses struct {
+	Count int64         `json:"count"`
+	Total time.Duration `json:"total"`
+	Max   time.Duration `json:"max"`
+	P50   time.Duration `json:"p50"`
+	P99   time.Duration `json:"p99"`
+}
+
+type Collector struct {
+	// Name of the collector, such as G1New or G1Old.
+	Name        string `json:"name"`
+	Collections int64  `json:"collections"`
+	Pauses      Pauses `json:"pauses"`
+}
+
+// HeapUsage is the heap occupancy around a collection, zero when not
+// recorded.
+type HeapUsage struct {
+	GcID   int64     `json:"gcId"`
+	Time   time.Time `json:"time"`
+	Before int64     `json:"before"`
+	After  int64     `json:"after"`
+}
+
+// Phase is a jdk.GCPhasePauseLevel1 to jdk.GCPhasePauseLevel4 event of a
+// pause.
+type Phase struct {
+	Level    int           `json:"level"`
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+type Pause struct {
+	GcID      int64         `json:"gcId"`
+	Collector string        `json:"collector"`
+	Cause     string        `json:"cause"`
+	Start     time.Time     `json:"start"`
+	Duration  time.Duration `json:"duration"`
+	// Phases in the order they started.
+	Phases []Phase `json:"phases,omitempty"`
+}
+
+type Report struct {
+	// Collectors by name.
+	Collectors []Collector `json:"collectors"`
+	Pauses     Pauses      `json:"pauses"`
+	// Heap is ordered by collection.
+	Heap []HeapUsage `json:"heap"`
+	// AllocationRate in bytes per second is the growth of the heap between
+	// the end of a collection and the start of the next one.
+	AllocationRate float64 `json:"allocationRate"`
+	// PromotionRate in bytes per second is the growth of the heap occupancy
+	// after collections, an estimate of the objects promoted to the old
+	// generation without per generation data.
+	PromotionRate float64 `json:"promotionRate"`
+	// LongestPauses are ordered by decreasing duration.
+	LongestPauses []Pause `json:"longestPauses"`
+}
+
+type collection struct {
+	name        string
+	cause       string
+	start       time.Time
+	sumOfPauses time.Duration
+	pauses      []Pause
+	phases      []timedPhase
+}
+
+type timedPhase struct {
+	start time.Time
+	Phase
+}
+
+type builder struct {
+	options     Options
+	collections map[int64]*collection
+	heap        map[int64]*HeapUsage
+}
+
+func newBuilder(options Options) *builder {
+	if options.TopPauses == 0 {
+		options.TopPauses = DefaultTopPauses
+	}
+	return &builder{
+		options:     options,
+		collections: make(map[int64]*collection),
+		heap:        make(map[int64]*HeapUsage),
+	}
+}
+
+func (b *builder) collection(gcID int64) *collection {
+	c, ok := b.collections[gcID]
+	if !ok {
+		c = &collection{}
+		b.collections[gcID] = c
+	}
+	return c
+}
+
+func (b *builder) heapUsage(gcID int64, t time.Time) *HeapUsage {
+	h, ok := b.heap[gcID]
+	if !ok {
+		h = &HeapUsage{GcID: gcID, Time: t}
+		b.heap[gcID] = h
+	}
+	return h
+}
+
+func (b *builder) report() *Report {
+	r := &Report{}
+	collectors := make(map[string]*Collector)
+	pausesByCollector := make(map[string][]time.Duration)
+	var all []time.Duration
+	for gcID, c := range b.collections {
+		if c.name == "" {
+			// phases of a collection which started before the recording
+			continue
+		}
+		col, ok := collectors[c.name]
+		if !ok {
+			col = &Collector{Name: c.name}
+			collectors[c.name] = col
+		}
+		col.Collections++
+		pauses := c.pauses
+		if len(pauses) == 0 && c.sumOfPauses > 0 {
+			pauses = []Pause{{Start: c.start, Duration: c.sumOfPauses}}
+		}
+		sort.Slice(c.phases, func(i, j int) bool {
+			a, b := &c.phases[i], &c.phases[j]
+			if !a.start.Equal(b.start) {
+				return a.start.Before(b.start)
+			}
+			return a.Level < b.Level
+		})
+		for _, p := range pauses {
+			p.GcID, p.Collector, p.Cause = gcID, c.name, c.cause
+			for _, ph := range c.phases {
+				if !ph.start.Before(p.Start) && !ph.start.After(p.Start.Add(p.Duration)) {
+					p.Phases = append(p.Phases, ph.Phase)
+				}
+			}
+			r.LongestPauses = append(r.LongestPauses, p)
+			pausesByCollector[c.name] = append(pausesByCollector[c.name], p.Duration)
+			all = append(all, p.Duration)
+		}
+	}
+	for name, col := range collectors {
+		col.Pauses = summarize(pausesByCollector[name])
+		r.Collectors = append(r.Collectors, *col)
+	}
+	sort.Slice(r.Collectors, func(i, j int) bool { return r.Collectors[i].Name < r.Collectors[j].Name })
+	r.Pauses = summarize(all)
+
+	sort.Slice(r.LongestPauses, func(i, j int) bool {
+		a, b := &r.LongestPauses[i], &r.LongestPauses[j]
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.GcID < b.GcID
+	})
+	if len(r.LongestPauses) > b.options.TopPauses {
+		r.LongestPauses = r.LongestPauses[:b.options.TopPauses]
+	}
+
+	for _, h := range b.heap {
+		r.Heap = append(r.Heap, *h)
+	}
+	sort.Slice(r.Heap, func(i, j int) bool { return r.Heap[i].GcID < r.Heap[j].GcID })
+	r.AllocationRate, r.PromotionRate = rates(r.Heap)
+	return r
+}
+
+func summarize(pauses []time.Duration) Pauses {
+	if len(pauses) == 0 {
+		return Pauses{}
+	}
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+	s := Pauses{
+		Count: int64(len(pauses)),
+		Max:   pauses[len(pauses)-1],
+		P50:   report.Percentile(pauses, 50),
+		P99:   report.Percentile(pauses, 99),
+	}
+	for _, p := range pauses {
+		s.Total += p
+	}
+	return s
+}
+
+// rates returns the allocation and promotion rates between the first and the
+// last collection with both heap summaries.
+func rates(heap []HeapUsage) (float64, float64) {
+	var (
+		prev                *HeapUsage
+		first               time.Time
+		allocated, promoted int64
+		elapsed             time.Duration
+	)
+	for i := range heap {
+		h := &heap[i]
+		if h.Before == 0 || h.After == 0 {
+			continue
+		}
+		if prev == nil {
+			first = h.Time
+		} else {
+			if d := h.Before - prev.After; d > 0 {
+				allocated += d
+			}
+			if d := h.After - prev.After; d > 0 {
+				promoted += d
+			}
+			elapsed = h.Time.Sub(first)
+		}
+		prev = h
+	}
+	if elapsed <= 0 {
+		return 0, 0
+	}
+	seconds := elapsed.Seconds()
+	return float64(allocated) / seconds, float64(promoted) / seconds
+}
+
+var phaseLevels = map[string]int{
+	types.GcPauseL1: 1,
+	types.GcPauseL2: 2,
+	types.GcPauseL3: 3,
+	types.GcPauseL4: 4,
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.GarbageCollection) {
+			gcID, err := attributes.GcId.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			start, err := report.StartTime(event)
+			if err != nil {
+				return nil, err
+			}
+			c := b.collection(gcID)
+			c.start = start
+			if c.name, err = attributes.GcName.GetValue(event); err != nil {
+				return nil, err
+			}
+			// the cause may be missing from old recordings
+			c.cause, _ = attributes.GcCause.GetValue(event)
+			if sum, err := attributes.SumOfPauses.GetValue(event); err == nil {
+				c.sumOfPauses, _ = units.ToDuration(sum)
+			}
+		}
+		for _, event := range chunk.Apply(filters.GcPause) {
+			gcID, start, duration, err := timing(event)
+			if err != nil {
+				return nil, err
+			}
+			c := b.collection(gcID)
+			c.pauses = append(c.pauses, Pause{Start: start, Duration: duration})
+		}
+		for _, event := range chunk.Apply(filters.GcPausePhase) {
+			gcID, start, duration, err := timing(event)
+			if err != nil {
+				return nil, err
+			}
+			name, err := attributes.GcPhaseName.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			c := b.collection(gcID)
+			level := phaseLevels[event.ClassMetadata.Name]
+			c.phases = append(c.phases, timedPhase{start: start, Phase: Phase{Level: level, Name: name, Duration: duration}})
+		}
+		for _, when := range []struct {
+			filter parser.EventFilter
+			before bool
+		}{{filters.HeapSummaryBeforeGc, true}, {filters.HeapSummaryAfterGc, false}} {
+			for _, event := range chunk.Apply(when.filter) {
+				gcID, err := attributes.GcId.GetValue(event)
+				if err != nil {
+					return nil, err
+				}
+				t, err := report.StartTime(event)
+				if err != nil {
+					return nil, err
+				}
+				used, err := attributes.HeapUsed.GetValue(event)
+				if err != nil {
+					return nil, err
+				}
+				if used, err = used.In(units.Byte); err != nil {
+					return nil, err
+				}
+				h := b.heapUsage(gcID, t)
+				if when.before {
+					h.Time = t
+					h.Before = used.IntValue()
+				} else {
+					h.After = used.IntValue()
+				}
+			}
+		}
+	}
+	return b.report(), nil
+}
+
+// timing returns the GC identifier, the start and the duration of an event.
+func timing(event *parser.GenericEvent) (int64, time.Time, time.Duration, error) {
+	gcID, err := attributes.GcId.GetValue(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	start, err := report.StartTime(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	d, err := attributes.Duration.GetValue(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	duration, err := units.ToDuration(d)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	return gcID, start, duration, nil
+}
+
+// WriteJSON writes the report as indented JSON, with durations in
+// nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTOR\tCOLLECTIONS\tPAUSES\tTOTAL\tMAX\tP50\tP99")
+	for _, c := range r.Collectors {
+		writePauses(tw, c.Name, fmt.Sprint(c.Collections), c.Pauses)
+	}
+	writePauses(tw, "all", "-", r.Pauses)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "allocation rate\t%s/s\n", report.FormatBytes(int64(r.AllocationRate)))
+	fmt.Fprintf(tw, "promotion rate\t%s/s\n", report.FormatBytes(int64(r.PromotionRate)))
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "GC ID\tTIME\tHEAP BEFORE\tHEAP AFTER")
+	for _, h := range r.Heap {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", h.GcID, h.Time.UTC().Format(time.RFC3339Nano), report.FormatBytes(h.Before), report.FormatBytes(h.After))
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "GC ID\tCOLLECTOR\tCAUSE\tSTART\tPAUSE")
+	for _, p := range r.LongestPauses {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", p.GcID, p.Collector, p.Cause, p.Start.UTC().Format(time.RFC3339Nano), p.Duration)
+		for _, ph := range p.Phases {
+			fmt.Fprintf(tw, "\t%s%s\t\t\t%s\n", strings.Repeat("  ", ph.Level), ph.Name, ph.Duration)
+		}
+	}
+	return tw.Flush()
+}
+
+func writePauses(w io.Writer, name, collections string, p Pauses) {
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", name, collections, p.Count, p.Total, p.Max, p.P50, p.P99)
+}
@@ -0,0 +1,67 @@
+package gc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/prof.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{TopPauses: 1})
+	require.NoError(t, err)
+
+	pauses := Pauses{Count: 2, Total: 6552279, Max: 4014632, P50: 2537647, P99: 4014632}
+	assert.Equal(t, []Collector{{Name: "G1New", Collections: 2, Pauses: pauses}}, report.Collectors)
+	assert.Equal(t, pauses, report.Pauses)
+
+	require.Len(t, report.Heap, 2)
+	assert.Equal(t, int64(18), report.Heap[0].GcID)
+	assert.Equal(t, int64(61476928), report.Heap[0].Before)
+	assert.Equal(t, int64(32936736), report.Heap[0].After)
+	assert.Equal(t, int64(61248288), report.Heap[1].Before)
+	assert.Equal(t, int64(30957008), report.Heap[1].After)
+	assert.InDelta(t, 6e8, report.AllocationRate, 1e6)
+	assert.Zero(t, report.PromotionRate)
+
+	require.Len(t, report.LongestPauses, 1)
+	p := report.LongestPauses[0]
+	assert.Equal(t, int64(18), p.GcID)
+	assert.Equal(t, "G1New", p.Collector)
+	assert.Equal(t, "G1 Evacuation Pause", p.Cause)
+	assert.Equal(t, 4014632*time.Nanosecond, p.Duration)
+	require.Len(t, p.Phases, 8)
+	assert.Equal(t, Phase{Level: 1, Name: "Reconsider SoftReferences", Duration: 920}, p.Phases[0])
+	assert.Equal(t, Phase{Level: 2, Name: "Notify Soft/WeakReferences", Duration: 8324}, p.Phases[2])
+
+	var text strings.Builder
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "G1New      2            2       6.552279ms  4.014632ms  2.537647ms  4.014632ms\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report.Collectors, decoded.Collectors)
+}
+
+func TestRates(t *testing.T) {
+	start := time.Unix(0, 0)
+	allocation, promotion := rates([]HeapUsage{
+		{GcID: 1, Time: start, Before: 100, After: 20},
+		// missing summaries are skipped
+		{GcID: 2, Time: start.Add(time.Second), After: 25},
+		{GcID: 3, Time: start.Add(2 * time.Second), Before: 120, After: 30},
+		{GcID: 4, Time: start.Add(4 * time.Second), Before: 110, After: 20},
+	})
+	// (120-20) + (110-30) allocated and 30-20 promoted in 4s
+	assert.Equal(t, 45.0, allocation)
+	assert.Equal(t, 2.5, promotion)
+}
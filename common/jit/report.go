@@ -0,0 +1,390 @@
+// Package jit reports the work of the JIT compilers of a recording: the
+// longest compilations per method and tier, the failed compilations, the
+// deoptimizations and the occupancy of the code cache.
+package jit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+// DefaultTopCompilations is the number of longest compilations reported,
+// unless Options.TopCompilations says otherwise.
+const DefaultTopCompilations = 20
+
+type Options struct {
+	// TopCompilations is the number of longest compilations reported,
+	// DefaultTopCompilations when zero.
+	TopCompilations int
+}
+
+// Compilation is a jdk.Compilation event.
+type Compilation struct {
+	CompileID int64 `json:"compileId"`
+	// Method is the Java name of the compiled method, such as
+	// java.lang.String.hashCode.
+	Method string `json:"method"`
+	// Tier is the compilation level, 1 to 3 for C1 and 4 for C2.
+	Tier         int64         `json:"tier"`
+	Start        time.Time     `json:"start"`
+	Duration     time.Duration `json:"duration"`
+	CodeSize     int64         `json:"codeSize"`
+	InlinedBytes int64         `json:"inlinedBytes"`
+	// OSR tells an on stack replacement compilation of a loop.
+	OSR       bool `json:"osr"`
+	Succeeded bool `json:"succeeded"`
+}
+
+// Tier summarizes the compilations of a compilation level.
+type Tier struct {
+	Tier     int64         `json:"tier"`
+	Count    int64         `json:"count"`
+	Failures int64         `json:"failures"`
+	Total    time.Duration `json:"total"`
+	Max      time.Duration `json:"max"`
+}
+
+// Failure is a jdk.CompilationFailure event, with the method and tier of its
+// compilation when the recording has it.
+type Failure struct {
+	CompileID int64     `json:"compileId"`
+	Method    string    `json:"method,omitempty"`
+	Tier      int64     `json:"tier,omitempty"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+}
+
+// Deoptimization counts the jdk.Deoptimization events of a reason and action.
+type Deoptimization struct {
+	Reason string `json:"reason"`
+	Action string `json:"action"`
+	Count  int64  `json:"count"`
+}
+
+// CodeCacheUsage is the occupancy of a code heap, from a
+// jdk.CodeCacheStatistics or jdk.CodeCacheFull event.
+type CodeCacheUsage struct {
+	Time time.Time `json:"time"`
+	// CodeHeap is the segment of the code cache, such as
+	// CodeHeap 'non-profiled nmethods', or CodeCache without segments.
+	CodeHeap string `json:"codeHeap"`
+	Used     int64  `json:"used"`
+	Reserved int64  `json:"reserved"`
+	Entries  int64  `json:"entries"`
+	Methods  int64  `json:"methods"`
+	// FullCount is the number of times the code heap was full since the
+	// start of the JVM.
+	FullCount int64 `json:"fullCount"`
+}
+
+type Report struct {
+	Compilations int64 `json:"compilations"`
+	// Tiers are ordered by level.
+	Tiers []Tier `json:"tiers"`
+	// LongestCompilations are ordered by decreasing duration.
+	LongestCompilations []Compilation `json:"longestCompilations"`
+	// Failures are ordered by time.
+	Failures []Failure `json:"failures"`
+	// Deoptimizations are ordered by decreasing count. The events exist
+	// since JDK 14.
+	Deoptimizations []Deoptimization `json:"deoptimizations"`
+	// Invalidated is the number of compiled methods the JVM discarded since
+	// its start, from jdk.CompilerStatistics, which older JDKs also record.
+	Invalidated int64 `json:"invalidated"`
+	// CodeCache is ordered by time and code heap.
+	CodeCache []CodeCacheUsage `json:"codeCache"`
+	// CodeCacheFull are the code cache full incidents, ordered by time.
+	CodeCacheFull []CodeCacheUsage `json:"codeCacheFull"`
+}
+
+type builder struct {
+	options         Options
+	compilations    map[int64]*Compilation
+	failures        []Failure
+	deoptimizations map[Deoptimization]int64
+	invalidated     int64
+	codeCache       []CodeCacheUsage
+	codeCacheFull   []CodeCacheUsage
+}
+
+func newBuilder(options Options) *builder {
+	if options.TopCompilations == 0 {
+		options.TopCompilations = DefaultTopCompilations
+	}
+	return &builder{
+		options:         options,
+		compilations:    make(map[int64]*Compilation),
+		deoptimizations: make(map[Deoptimization]int64),
+	}
+}
+
+func (b *builder) report() *Report {
+	r := &Report{Invalidated: b.invalidated}
+	tiers := make(map[int64]*Tier)
+	for _, c := range b.compilations {
+		r.Compilations++
+		t, ok := tiers[c.Tier]
+		if !ok {
+			t = &Tier{Tier: c.Tier}
+			tiers[c.Tier] = t
+		}
+		t.Count++
+		if !c.Succeeded {
+			t.Failures++
+		}
+		t.Total += c.Duration
+		if c.Duration > t.Max {
+			t.Max = c.Duration
+		}
+		r.LongestCompilations = append(r.LongestCompilations, *c)
+	}
+	for _, t := range tiers {
+		r.Tiers = append(r.Tiers, *t)
+	}
+	sort.Slice(r.Tiers, func(i, j int) bool { return r.Tiers[i].Tier < r.Tiers[j].Tier })
+	sort.Slice(r.LongestCompilations, func(i, j int) bool {
+		a, b := &r.LongestCompilations[i], &r.LongestCompilations[j]
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.CompileID < b.CompileID
+	})
+	if len(r.LongestCompilations) > b.options.TopCompilations {
+		r.LongestCompilations = r.LongestCompilations[:b.options.TopCompilations]
+	}
+
+	for _, f := range b.failures {
+		if c, ok := b.compilations[f.CompileID]; ok {
+			f.Method, f.Tier = c.Method, c.Tier
+		}
+		r.Failures = append(r.Failures, f)
+	}
+	sort.SliceStable(r.Failures, func(i, j int) bool { return r.Failures[i].Time.Before(r.Failures[j].Time) })
+
+	for d, count := range b.deoptimizations {
+		d.Count = count
+		r.Deoptimizations = append(r.Deoptimizations, d)
+	}
+	sort.Slice(r.Deoptimizations, func(i, j int) bool {
+		a, b := &r.Deoptimizations[i], &r.Deoptimizations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Reason != b.Reason {
+			return a.Reason < b.Reason
+		}
+		return a.Action < b.Action
+	})
+
+	r.CodeCache, r.CodeCacheFull = b.codeCache, b.codeCacheFull
+	for _, usage := range [][]CodeCacheUsage{r.CodeCache, r.CodeCacheFull} {
+		sort.SliceStable(usage, func(i, j int) bool {
+			a, b := &usage[i], &usage[j]
+			if !a.Time.Equal(b.Time) {
+				return a.Time.Before(b.Time)
+			}
+			return a.CodeHeap < b.CodeHeap
+		})
+	}
+	return r
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.Compilation) {
+			c, err := compilation(event)
+			if err != nil {
+				return nil, err
+			}
+			b.compilations[c.CompileID] = c
+		}
+		for _, event := range chunk.Apply(filters.CompilerFailure) {
+			id, err := attributes.CompileId.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			t, err := report.StartTime(event)
+			if err != nil {
+				return nil, err
+			}
+			msg, err := attributes.FailureMessage.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			b.failures = append(b.failures, Failure{CompileID: id, Time: t, Message: msg})
+		}
+		for _, event := range chunk.Apply(filters.Deoptimization) {
+			reason, err := attributes.DeoptReason.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			action, err := attributes.DeoptAction.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			b.deoptimizations[Deoptimization{Reason: reason, Action: action}]++
+		}
+		for _, event := range chunk.Apply(filters.CompilerStats) {
+			// the statistics are totals since the start of the JVM
+			invalidated, err := attributes.InvalidatedCount.GetValue(event)
+			if err != nil {
+				return nil, err
+			}
+			if invalidated > b.invalidated {
+				b.invalidated = invalidated
+			}
+		}
+		for _, event := range chunk.Apply(filters.CodeCacheStatistics) {
+			usage, err := codeCacheUsage(event)
+			if err != nil {
+				return nil, err
+			}
+			b.codeCache = append(b.codeCache, usage)
+		}
+		for _, event := range chunk.Apply(filters.CodeCacheFull) {
+			usage, err := codeCacheUsage(event)
+			if err != nil {
+				return nil, err
+			}
+			b.codeCacheFull = append(b.codeCacheFull, usage)
+		}
+	}
+	return b.report(), nil
+}
+
+func compilation(event *parser.GenericEvent) (*Compilation, error) {
+	c := &Compilation{}
+	var err error
+	if c.CompileID, err = attributes.CompileId.GetValue(event); err != nil {
+		return nil, err
+	}
+	if c.Start, err = report.StartTime(event); err != nil {
+		return nil, err
+	}
+	d, err := attributes.Duration.GetValue(event)
+	if err != nil {
+		return nil, err
+	}
+	if c.Duration, err = units.ToDuration(d); err != nil {
+		return nil, err
+	}
+	if c.Tier, err = attributes.CompileLevel.GetValue(event); err != nil {
+		return nil, err
+	}
+	m, err := attributes.CompiledMethod.GetValue(event)
+	if err != nil {
+		return nil, err
+	}
+	c.Method = report.MethodName(m)
+	if c.Succeeded, err = attributes.CompileSucceeded.GetValue(event); err != nil {
+		return nil, err
+	}
+	// the sizes and the OSR flag are missing from old recordings
+	c.CodeSize, _ = attributes.CodeSize.GetValue(event)
+	c.InlinedBytes, _ = attributes.InlinedBytes.GetValue(event)
+	c.OSR, _ = attributes.IsOsr.GetValue(event)
+	return c, nil
+}
+
+// codeCacheUsage reads the fields jdk.CodeCacheStatistics and
+// jdk.CodeCacheFull share.
+func codeCacheUsage(event *parser.GenericEvent) (CodeCacheUsage, error) {
+	var (
+		u                       CodeCacheUsage
+		start, top, unallocated int64
+		err                     error
+	)
+	if u.Time, err = report.StartTime(event); err != nil {
+		return u, err
+	}
+	if u.CodeHeap, err = attributes.CodeBlobType.GetValue(event); err != nil {
+		return u, err
+	}
+	if start, err = attributes.StartAddress.GetValue(event); err != nil {
+		return u, err
+	}
+	if top, err = attributes.ReservedTopAddress.GetValue(event); err != nil {
+		return u, err
+	}
+	if unallocated, err = attributes.UnallocatedCapacity.GetValue(event); err != nil {
+		return u, err
+	}
+	u.Reserved = top - start
+	u.Used = u.Reserved - unallocated
+	u.Entries, _ = attributes.EntryCount.GetValue(event)
+	u.Methods, _ = attributes.MethodCount.GetValue(event)
+	u.FullCount, _ = attributes.FullCount.GetValue(event)
+	return u, nil
+}
+
+// WriteJSON writes the report as indented JSON, with durations in
+// nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIER\tCOMPILATIONS\tFAILURES\tTOTAL\tMAX")
+	for _, t := range r.Tiers {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\n", t.Tier, t.Count, t.Failures, t.Total, t.Max)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "DURATION\tTIER\tID\tMETHOD\tCODE SIZE\tOSR\tSUCCEEDED")
+	for _, c := range r.LongestCompilations {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%t\t%t\n", c.Duration, c.Tier, c.CompileID, report.OrDash(c.Method), c.CodeSize, c.OSR, c.Succeeded)
+	}
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "FAILURE\tTIER\tID\tMETHOD\tMESSAGE")
+		for _, f := range r.Failures {
+			tier := "-"
+			if f.Method != "" {
+				tier = fmt.Sprint(f.Tier)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", f.Time.UTC().Format(time.RFC3339Nano), tier, f.CompileID, report.OrDash(f.Method), f.Message)
+		}
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintf(tw, "invalidated\t%d\n", r.Invalidated)
+	if len(r.Deoptimizations) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "REASON\tACTION\tDEOPTIMIZATIONS")
+		for _, d := range r.Deoptimizations {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", d.Reason, d.Action, d.Count)
+		}
+	}
+
+	for _, usage := range []struct {
+		title string
+		usage []CodeCacheUsage
+	}{{"CODE CACHE", r.CodeCache}, {"CODE CACHE FULL", r.CodeCacheFull}} {
+		if len(usage.usage) == 0 {
+			continue
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintf(tw, "%s\tCODE HEAP\tUSED\tRESERVED\tMETHODS\tFULL COUNT\n", usage.title)
+		for _, u := range usage.usage {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n", u.Time.UTC().Format(time.RFC3339Nano), report.OrDash(u.CodeHeap), report.FormatBytes(u.Used), report.FormatBytes(u.Reserved), u.Methods, u.FullCount)
+		}
+	}
+	return tw.Flush()
+}
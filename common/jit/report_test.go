@@ -0,0 +1,134 @@
+package jit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{TopCompilations: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(10), report.Compilations)
+	assert.Equal(t, []Tier{
+		{Tier: 3, Count: 1, Total: 691546750, Max: 691546750},
+		{Tier: 4, Count: 9, Total: 1284126165, Max: 212324791},
+	}, report.Tiers)
+
+	require.Len(t, report.LongestCompilations, 2)
+	c := report.LongestCompilations[0]
+	assert.Equal(t, int64(4600), c.CompileID)
+	assert.Equal(t, "jdk.internal.org.objectweb.asm.ClassReader.readCode", c.Method)
+	assert.Equal(t, int64(3), c.Tier)
+	assert.Equal(t, 691546750*time.Nanosecond, c.Duration)
+	assert.Equal(t, int64(105256), c.CodeSize)
+	assert.True(t, c.Succeeded)
+	assert.Equal(t, "java.lang.Throwable.<init>", report.LongestCompilations[1].Method)
+
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, int64(5154), report.Failures[0].CompileID)
+	assert.Equal(t, "failed spill-split-recycle sanity check", report.Failures[0].Message)
+	assert.Empty(t, report.Failures[0].Method)
+
+	require.Len(t, report.CodeCache, 6)
+	u := report.CodeCache[0]
+	assert.Equal(t, "CodeHeap 'non-profiled nmethods'", u.CodeHeap)
+	assert.Equal(t, int64(1199), u.Methods)
+	assert.Less(t, u.Used, u.Reserved)
+	assert.Empty(t, report.CodeCacheFull)
+	assert.Empty(t, report.Deoptimizations)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report.Tiers, decoded.Tiers)
+}
+
+const (
+	codeBlobType         = 100
+	deoptimizationReason = 101
+	deoptimizationAction = 102
+	deoptimization       = 103
+	codeCacheFull        = 104
+)
+
+func deoptRecording() []byte {
+	r := jfrtest.New()
+	r.Class(codeBlobType, "jdk.types.CodeBlobType",
+		jfrtest.Field{Name: "type", Class: jfrtest.String})
+	r.Class(deoptimizationReason, "jdk.types.DeoptimizationReason",
+		jfrtest.Field{Name: "reason", Class: jfrtest.String})
+	r.Class(deoptimizationAction, "jdk.types.DeoptimizationAction",
+		jfrtest.Field{Name: "action", Class: jfrtest.String})
+	r.EventClass(deoptimization, "jdk.Deoptimization",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "compileId", Class: jfrtest.Int},
+		jfrtest.Field{Name: "reason", Class: deoptimizationReason, CPool: true},
+		jfrtest.Field{Name: "action", Class: deoptimizationAction, CPool: true})
+	r.EventClass(codeCacheFull, "jdk.CodeCacheFull",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "codeBlobType", Class: codeBlobType, CPool: true},
+		jfrtest.Field{Name: "startAddress", Class: jfrtest.Long},
+		jfrtest.Field{Name: "reservedTopAddress", Class: jfrtest.Long},
+		jfrtest.Field{Name: "unallocatedCapacity", Class: jfrtest.Long},
+		jfrtest.Field{Name: "fullCount", Class: jfrtest.Int})
+
+	r.Constant(codeBlobType, 1, "CodeHeap 'profiled nmethods'")
+	r.Constant(deoptimizationReason, 1, "null_check")
+	r.Constant(deoptimizationReason, 2, "unstable_if")
+	r.Constant(deoptimizationAction, 1, "reinterpret")
+	r.Constant(deoptimizationAction, 2, "make_not_entrant")
+
+	start := uint64(jfrtest.StartTicks)
+	r.Event(deoptimization, start, 1, uint64(2), uint64(2))
+	r.Event(deoptimization, start+1, 2, uint64(1), uint64(1))
+	r.Event(deoptimization, start+2, 3, uint64(2), uint64(2))
+	r.Event(codeCacheFull, start+3, uint64(1), uint64(0x1000), uint64(0x1000+8<<20), uint64(0), 1)
+	return r.Bytes()
+}
+
+func TestDeoptimizations(t *testing.T) {
+	chunks, err := parser.Parse(bytes.NewReader(deoptRecording()))
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Deoptimization{
+		{Reason: "unstable_if", Action: "make_not_entrant", Count: 2},
+		{Reason: "null_check", Action: "reinterpret", Count: 1},
+	}, report.Deoptimizations)
+	assert.Equal(t, []CodeCacheUsage{{
+		Time:      time.Unix(0, jfrtest.StartNanos+3),
+		CodeHeap:  "CodeHeap 'profiled nmethods'",
+		Used:      8 << 20,
+		Reserved:  8 << 20,
+		FullCount: 1,
+	}}, report.CodeCacheFull)
+
+	var buf strings.Builder
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `TIER  COMPILATIONS  FAILURES  TOTAL  MAX
+
+DURATION  TIER  ID  METHOD  CODE SIZE  OSR  SUCCEEDED
+
+invalidated  0
+
+REASON       ACTION            DEOPTIMIZATIONS
+unstable_if  make_not_entrant  2
+null_check   reinterpret       1
+
+CODE CACHE FULL                 CODE HEAP                     USED    RESERVED  METHODS  FULL COUNT
+2023-11-14T22:13:20.000000003Z  CodeHeap 'profiled nmethods'  8.0MiB  8.0MiB    0        1
+`, buf.String())
+}
@@ -0,0 +1,452 @@
+// Package leaks reports the jdk.OldObjectSample events of a recording, the
+// objects sampled at allocation that were still alive when the recording
+// ended, grouped by allocation site and GC root type with the reference chain
+// from the GC root to the oldest object of each group, like the Memory Leak
+// page of JDK Mission Control.
+package leaks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+	types2 "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+type Options struct {
+	// MinAge leaves the objects younger than it out of the report.
+	MinAge time.Duration
+}
+
+// Link is an object of a reference chain.
+type Link struct {
+	// Class is the Java name of the class of the object, such as
+	// java.util.HashMap$Node[].
+	Class       string `json:"class"`
+	Address     uint64 `json:"address"`
+	Description string `json:"description,omitempty"`
+	// Field is the field of the object referring to the previous link of the
+	// chain, empty for the sampled object and for arrays.
+	Field string `json:"field,omitempty"`
+	// ArraySize is the length of the array referring to the previous link by
+	// its element Index, zero when the object is not such an array.
+	ArraySize int64 `json:"arraySize,omitempty"`
+	Index     int64 `json:"index,omitempty"`
+	// Skip is the number of objects the JVM left out of the chain between the
+	// previous link and this one.
+	Skip int64 `json:"skip,omitempty"`
+}
+
+// Root is the GC root a reference chain starts from.
+type Root struct {
+	Description string `json:"description,omitempty"`
+	// System is the subsystem holding the root, such as Universe or
+	// Threads, Type the kind of root, such as Stack Variable or Global JNI
+	// Handle.
+	System string `json:"system,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+type Sample struct {
+	Time           time.Time     `json:"time"`
+	AllocationTime time.Time     `json:"allocationTime"`
+	Age            time.Duration `json:"age"`
+	// Size is the size of the object in bytes, zero when the JVM does not
+	// record it.
+	Size               int64  `json:"size"`
+	LastKnownHeapUsage int64  `json:"lastKnownHeapUsage"`
+	Class              string `json:"class"`
+	// ArrayElements is the length of arrays, -1 for other objects.
+	ArrayElements int64  `json:"arrayElements"`
+	Thread        string `json:"thread"`
+	// Stack is the allocation stack, the top frame first.
+	Stack []string `json:"stack"`
+	// Chain starts with the sampled object and ends with the object held by
+	// the root. The JVM only records it when the recording was dumped with
+	// path-to-gc-roots.
+	Chain []Link `json:"chain,omitempty"`
+	Root  *Root  `json:"root,omitempty"`
+}
+
+// Key is a group of samples.
+type Key struct {
+	Class string `json:"class"`
+	// Site is the top frame of the allocation stack.
+	Site string `json:"site"`
+	// RootType is empty when the samples have no reference chain.
+	RootType string `json:"rootType"`
+}
+
+type Leak struct {
+	Key
+	Count int64 `json:"count"`
+	// Size is the total size of the objects.
+	Size      int64         `json:"size"`
+	OldestAge time.Duration `json:"oldestAge"`
+	// Oldest is the oldest sample of the group.
+	Oldest Sample `json:"oldest"`
+}
+
+type Report struct {
+	// Leaks are ordered by decreasing count, then by decreasing age.
+	Leaks []Leak `json:"leaks"`
+}
+
+type builder struct {
+	options Options
+	leaks   map[Key]*Leak
+}
+
+func newBuilder(options Options) *builder {
+	return &builder{
+		options: options,
+		leaks:   make(map[Key]*Leak),
+	}
+}
+
+func (b *builder) add(s Sample) {
+	if s.Age < b.options.MinAge {
+		return
+	}
+	key := Key{Class: s.Class}
+	if len(s.Stack) > 0 {
+		key.Site = s.Stack[0]
+	}
+	if s.Root != nil {
+		key.RootType = s.Root.Type
+	}
+	l, ok := b.leaks[key]
+	if !ok {
+		l = &Leak{Key: key}
+		b.leaks[key] = l
+	}
+	l.Count++
+	l.Size += s.Size
+	if l.Count == 1 || s.Age > l.OldestAge {
+		l.OldestAge = s.Age
+		l.Oldest = s
+	}
+}
+
+func (b *builder) report() *Report {
+	r := &Report{Leaks: make([]Leak, 0, len(b.leaks))}
+	for _, l := range b.leaks {
+		r.Leaks = append(r.Leaks, *l)
+	}
+	sort.Slice(r.Leaks, func(i, j int) bool {
+		a, b := &r.Leaks[i], &r.Leaks[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.OldestAge != b.OldestAge {
+			return a.OldestAge > b.OldestAge
+		}
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		if a.Site != b.Site {
+			return a.Site < b.Site
+		}
+		return a.RootType < b.RootType
+	})
+	return r
+}
+
+func frameName(class, method string, line int64) string {
+	return report.JavaName(class) + "." + method + ":" + strconv.FormatInt(line, 10)
+}
+
+// FromParser reads the remaining events of the parser into a report.
+func FromParser(p *parser.Parser, options Options) (*Report, error) {
+	b := newBuilder(options)
+	err := report.ParseEvents(p, func(typ def.TypeID) {
+		if typ == p.TypeMap.T_OLD_OBJECT_SAMPLE {
+			b.add(ReadSample(p))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.report(), nil
+}
+
+// ReadSample resolves the jdk.OldObjectSample event the parser has just
+// parsed.
+func ReadSample(p *parser.Parser) Sample {
+	e := &p.OldObjectSample
+	s := Sample{
+		Time:               p.TicksToTime(e.StartTime),
+		AllocationTime:     p.TicksToTime(e.AllocationTime),
+		Size:               int64(e.ObjectSize),
+		LastKnownHeapUsage: int64(e.LastKnownHeapUsage),
+		ArrayElements:      -1,
+		Thread:             report.ThreadName(p, e.EventThread),
+	}
+	if e.ObjectAge != 0 {
+		s.Age = p.TicksToDuration(e.ObjectAge)
+	} else if e.StartTime > e.AllocationTime {
+		// objectAge is missing before JDK 14
+		s.Age = p.TicksToDuration(e.StartTime - e.AllocationTime)
+	}
+	// Integer.MIN_VALUE for objects which are not arrays
+	if n := int32(e.ArrayElements); n >= 0 {
+		s.ArrayElements = int64(n)
+	}
+	if st := p.GetStacktrace(e.StackTrace); st != nil {
+		for _, f := range st.Frames {
+			m := p.GetMethod(f.Method)
+			if m == nil {
+				continue
+			}
+			// -1 when unknown
+			line := int64(int32(f.LineNumber))
+			s.Stack = append(s.Stack, frameName(report.ClassName(p, m.Type), p.GetSymbolString(m.Name), line))
+		}
+	}
+	seen := make(map[types2.OldObjectRef]bool)
+	ref := e.Object
+	var from *types2.Reference
+	for {
+		o := p.GetOldObject(ref)
+		if o == nil || seen[ref] {
+			break
+		}
+		seen[ref] = true
+		link := Link{Class: report.JavaName(report.ClassName(p, o.Type)), Address: o.Address, Description: o.Description}
+		if from != nil {
+			if f := p.GetOldObjectField(from.Field); f != nil {
+				link.Field = f.Name
+			}
+			if a := p.GetOldObjectArray(from.Array); a != nil {
+				link.ArraySize, link.Index = int64(a.Size), int64(a.Index)
+			}
+			link.Skip = int64(from.Skip)
+		}
+		s.Chain = append(s.Chain, link)
+		from = p.GetReference(o.Referrer)
+		if from == nil {
+			break
+		}
+		ref = from.Object
+	}
+	if len(s.Chain) > 0 {
+		s.Class = s.Chain[0].Class
+	}
+	if len(s.Chain) == 1 {
+		// the sampled object alone is not a chain
+		s.Chain = nil
+	}
+	if root := p.GetOldObjectGcRoot(e.Root); root != nil {
+		s.Root = &Root{Description: root.Description}
+		if system := p.GetOldObjectRootSystem(root.System); system != nil {
+			s.Root.System = system.System
+		}
+		if typ := p.GetOldObjectRootType(root.Type); typ != nil {
+			s.Root.Type = typ.Type
+		}
+	}
+	return s
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.OldObjectSample) {
+			s, err := readSampleLegacy(event)
+			if err != nil {
+				return nil, err
+			}
+			b.add(s)
+		}
+	}
+	return b.report(), nil
+}
+
+func readSampleLegacy(event *parser.GenericEvent) (Sample, error) {
+	s := Sample{ArrayElements: -1}
+	startTime, err := attributes.StartTime.GetValue(event)
+	if err != nil {
+		return s, err
+	}
+	s.Time = time.Unix(0, startTime.IntValue())
+	allocationTime, err := attributes.AllocationTime.GetValue(event)
+	if err != nil {
+		return s, err
+	}
+	s.AllocationTime = time.Unix(0, allocationTime.IntValue())
+	if age, err := attributes.ObjectAge.GetValue(event); err == nil {
+		if s.Age, err = units.ToDuration(age); err != nil {
+			return s, err
+		}
+	} else if s.Time.After(s.AllocationTime) {
+		// objectAge is missing before JDK 14
+		s.Age = s.Time.Sub(s.AllocationTime)
+	}
+	if size, err := attributes.ObjectSize.GetValue(event); err == nil {
+		if size, err = size.In(units.Byte); err != nil {
+			return s, err
+		}
+		s.Size = size.IntValue()
+	}
+	used, err := attributes.LastKnownHeapUsage.GetValue(event)
+	if err != nil {
+		return s, err
+	}
+	if used, err = used.In(units.Byte); err != nil {
+		return s, err
+	}
+	s.LastKnownHeapUsage = used.IntValue()
+	if n, err := attributes.ArrayElements.GetValue(event); err == nil && n >= 0 {
+		s.ArrayElements = n
+	}
+	if t, err := attributes.EventThread.GetValue(event); err == nil && t != nil {
+		s.Thread = threadNameLegacy(t)
+	}
+	if st, err := attributes.EventStacktrace.GetValue(event); err == nil && st != nil {
+		for _, f := range st.Frames {
+			if f.Method == nil {
+				continue
+			}
+			s.Stack = append(s.Stack, frameName(classNameLegacy(f.Method.Type), symbolString(f.Method.Name), int64(f.LineNumber)))
+		}
+	}
+	o, err := attributes.OldObject.GetValue(event)
+	if err != nil {
+		return s, err
+	}
+	seen := make(map[*parser.OldObject]bool)
+	var from *parser.Reference
+	for o != nil && !seen[o] {
+		seen[o] = true
+		link := Link{Class: report.JavaName(classNameLegacy(o.Type)), Address: uint64(o.Address), Description: o.Description}
+		if from != nil {
+			if from.Field != nil {
+				link.Field = from.Field.Name
+			}
+			if from.Array != nil {
+				link.ArraySize, link.Index = int64(from.Array.Size), int64(from.Array.Index)
+			}
+			link.Skip = int64(from.Skip)
+		}
+		s.Chain = append(s.Chain, link)
+		if from = o.Referrer; from == nil {
+			break
+		}
+		o = from.Object
+	}
+	if len(s.Chain) > 0 {
+		s.Class = s.Chain[0].Class
+	}
+	if len(s.Chain) == 1 {
+		// the sampled object alone is not a chain
+		s.Chain = nil
+	}
+	if root, err := attributes.OldObjectRoot.GetValue(event); err == nil && root != nil {
+		s.Root = &Root{Description: root.Description}
+		if root.System != nil {
+			s.Root.System = root.System.System
+		}
+		if root.Type != nil {
+			s.Root.Type = root.Type.Type
+		}
+	}
+	return s, nil
+}
+
+func classNameLegacy(cls *parser.Class) string {
+	if cls == nil {
+		return ""
+	}
+	return symbolString(cls.Name)
+}
+
+func symbolString(s *parser.Symbol) string {
+	if s == nil {
+		return ""
+	}
+	return s.String
+}
+
+func threadNameLegacy(t *parser.Thread) string {
+	if t.JavaName != "" {
+		return t.JavaName
+	}
+	if t.OsName != "" {
+		return t.OsName
+	}
+	return "tid " + strconv.FormatInt(t.OsThreadID, 10)
+}
+
+// WriteJSON writes the report as indented JSON, with durations in
+// nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the groups as a table, then the reference chain of the
+// oldest object of each group, from the GC root down to the object.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tSIZE\tOLDEST\tCLASS\tALLOCATION SITE\tROOT")
+	for _, l := range r.Leaks {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n", l.Count, l.Size, l.OldestAge, report.OrDash(l.Class), report.OrDash(l.Site), report.OrDash(l.RootType))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, l := range r.Leaks {
+		s := &l.Oldest
+		if len(s.Chain) == 0 && s.Root == nil {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s allocated at %s, %s old:\n", report.OrDash(l.Class), report.OrDash(l.Site), s.Age)
+		if s.Root != nil {
+			fmt.Fprintf(w, "  root: %s\n", rootName(s.Root))
+		}
+		for i := len(s.Chain) - 1; i >= 0; i-- {
+			link := &s.Chain[i]
+			name := link.Class
+			switch {
+			case link.Field != "":
+				name += "." + link.Field
+			case link.ArraySize > 0:
+				name += fmt.Sprintf("[%d] of %d", link.Index, link.ArraySize)
+			}
+			if link.Description != "" {
+				name += " (" + link.Description + ")"
+			}
+			fmt.Fprintf(w, "  %s\n", name)
+			if link.Skip > 0 {
+				fmt.Fprintf(w, "  ... %d objects skipped\n", link.Skip)
+			}
+		}
+	}
+	return nil
+}
+
+func rootName(root *Root) string {
+	var parts []string
+	for _, s := range []string{root.Type, root.System, root.Description} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,199 @@
+package leaks
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Leaks, 13)
+	var count int64
+	for _, l := range report.Leaks {
+		count += l.Count
+	}
+	assert.Equal(t, int64(103), count)
+	l := report.Leaks[0]
+	assert.Equal(t, Key{Class: "[B", Site: "java.util.Arrays.copyOf:3745"}, l.Key)
+	assert.Equal(t, int64(43), l.Count)
+	assert.Equal(t, "qtp640059078-31", l.Oldest.Thread)
+	assert.Equal(t, int64(1179644), l.Oldest.ArrayElements)
+	assert.Equal(t, int64(196887184), l.Oldest.LastKnownHeapUsage)
+	assert.Equal(t, "movies.Server.replyJSON:80", l.Oldest.Stack[21])
+	// the recording was not dumped with path-to-gc-roots
+	assert.Empty(t, l.Oldest.Chain)
+	assert.Nil(t, l.Oldest.Root)
+
+	// the leak profiler writes the threads and stack traces of the samples
+	// in a checkpoint of its own
+	buf, err := os.ReadFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	fast, err := FromParser(parser.NewParser(buf, parser.Options{}), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, report, fast)
+}
+
+const (
+	short          = 9
+	oldObject      = 100
+	reference      = 101
+	oldObjectArray = 102
+	oldObjectField = 103
+	gcRoot         = 104
+	rootSystem     = 105
+	rootType       = 106
+	oldObjectEvent = 107
+)
+
+func leakRecording() []byte {
+	r := jfrtest.New()
+	r.Class(short, "short")
+	r.Class(oldObject, "jdk.types.OldObject",
+		jfrtest.Field{Name: "address", Class: jfrtest.Long},
+		jfrtest.Field{Name: "type", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "description", Class: jfrtest.String},
+		jfrtest.Field{Name: "referrer", Class: reference, CPool: true})
+	r.Class(reference, "jdk.types.Reference",
+		jfrtest.Field{Name: "array", Class: oldObjectArray, CPool: true},
+		jfrtest.Field{Name: "field", Class: oldObjectField, CPool: true},
+		jfrtest.Field{Name: "object", Class: oldObject, CPool: true},
+		jfrtest.Field{Name: "skip", Class: jfrtest.Int})
+	r.Class(oldObjectArray, "jdk.types.OldObjectArray",
+		jfrtest.Field{Name: "size", Class: jfrtest.Int},
+		jfrtest.Field{Name: "index", Class: jfrtest.Int})
+	r.Class(oldObjectField, "jdk.types.OldObjectField",
+		jfrtest.Field{Name: "name", Class: jfrtest.String},
+		jfrtest.Field{Name: "modifiers", Class: short})
+	r.Class(gcRoot, "jdk.types.OldObjectGcRoot",
+		jfrtest.Field{Name: "description", Class: jfrtest.String},
+		jfrtest.Field{Name: "system", Class: rootSystem, CPool: true},
+		jfrtest.Field{Name: "type", Class: rootType, CPool: true})
+	r.Class(rootSystem, "jdk.types.OldObjectRootSystem",
+		jfrtest.Field{Name: "system", Class: jfrtest.String})
+	r.Class(rootType, "jdk.types.OldObjectRootType",
+		jfrtest.Field{Name: "type", Class: jfrtest.String})
+	r.EventClass(oldObjectEvent, "jdk.OldObjectSample",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long, Annotation: jfrtest.Timespan, Value: "TICKS"},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "allocationTime", Class: jfrtest.Long, Annotation: jfrtest.Timestamp, Value: "TICKS"},
+		jfrtest.Field{Name: "objectSize", Class: jfrtest.Long, Annotation: jfrtest.DataAmount, Value: "BYTES"},
+		jfrtest.Field{Name: "objectAge", Class: jfrtest.Long, Annotation: jfrtest.Timespan, Value: "TICKS"},
+		jfrtest.Field{Name: "lastKnownHeapUsage", Class: jfrtest.Long, Annotation: jfrtest.DataAmount, Value: "BYTES"},
+		jfrtest.Field{Name: "object", Class: oldObject, CPool: true},
+		jfrtest.Field{Name: "arrayElements", Class: jfrtest.Int},
+		jfrtest.Field{Name: "root", Class: gcRoot, CPool: true})
+
+	r.ClassConstant(1, "com/foo/Entry")
+	r.ClassConstant(2, "[Lcom/foo/Entry;")
+	r.ClassConstant(3, "com/foo/Cache")
+	r.ClassConstant(4, "[B")
+	r.Constant(jfrtest.Symbol, 10, "put")
+	r.Constant(jfrtest.Symbol, 11, "load")
+	r.Constant(jfrtest.Method, 1, uint64(3), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(3), uint64(11))
+	r.StackTrace(1, 1, 2)
+	r.StackTrace(2, 2)
+	r.Constant(jfrtest.Thread, 1, "", uint64(11), "worker-1", uint64(1), false)
+
+	// Cache.table -> (2 skipped) -> Entry[3] -> Entry
+	r.Constant(oldObject, 1, uint64(0x1000), uint64(1), "", uint64(1))
+	r.Constant(oldObject, 2, uint64(0x2000), uint64(2), "", uint64(2))
+	r.Constant(oldObject, 3, uint64(0x3000), uint64(3), "", uint64(0))
+	r.Constant(oldObject, 4, uint64(0x4000), uint64(1), "", uint64(0))
+	r.Constant(oldObject, 5, uint64(0x5000), uint64(4), "", uint64(0))
+	r.Constant(reference, 1, uint64(1), uint64(0), uint64(2), 0)
+	r.Constant(reference, 2, uint64(0), uint64(1), uint64(3), 2)
+	r.Constant(oldObjectArray, 1, 16, 3)
+	r.Constant(oldObjectField, 1, "table", 2)
+	r.Constant(gcRoot, 1, "", uint64(1), uint64(1))
+	r.Constant(rootSystem, 1, "Class Loader Data")
+	r.Constant(rootType, 1, "Global Object Handle")
+
+	s := uint64(time.Second)
+	start := uint64(jfrtest.StartTicks)
+	notArray := int32(math.MinInt32)
+	r.Event(oldObjectEvent, start+10*s, uint64(0), uint64(1), uint64(1), start+5*s, uint64(32), 5*s, uint64(100<<20), uint64(1), notArray, uint64(1))
+	r.Event(oldObjectEvent, start+10*s, uint64(0), uint64(1), uint64(1), start+8*s, uint64(32), 2*s, uint64(100<<20), uint64(4), notArray, uint64(1))
+	r.Event(oldObjectEvent, start+10*s, uint64(0), uint64(1), uint64(2), start+9*s, uint64(1040), 1*s, uint64(100<<20), uint64(5), int32(1024), uint64(0))
+	return r.Bytes()
+}
+
+func TestReferenceChain(t *testing.T) {
+	report, err := FromParser(parser.NewParser(leakRecording(), parser.Options{}), Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Leaks, 2)
+	assert.Equal(t, Leak{
+		Key:       Key{Class: "com.foo.Entry", Site: "com.foo.Cache.put:10", RootType: "Global Object Handle"},
+		Count:     2,
+		Size:      64,
+		OldestAge: 5 * time.Second,
+		Oldest: Sample{
+			Time:               time.Unix(0, jfrtest.StartNanos+10*int64(time.Second)),
+			AllocationTime:     time.Unix(0, jfrtest.StartNanos+5*int64(time.Second)),
+			Age:                5 * time.Second,
+			Size:               32,
+			LastKnownHeapUsage: 100 << 20,
+			Class:              "com.foo.Entry",
+			ArrayElements:      -1,
+			Thread:             "worker-1",
+			Stack:              []string{"com.foo.Cache.put:10", "com.foo.Cache.load:20"},
+			Chain: []Link{
+				{Class: "com.foo.Entry", Address: 0x1000},
+				{Class: "[Lcom.foo.Entry;", Address: 0x2000, ArraySize: 16, Index: 3},
+				{Class: "com.foo.Cache", Address: 0x3000, Field: "table", Skip: 2},
+			},
+			Root: &Root{System: "Class Loader Data", Type: "Global Object Handle"},
+		},
+	}, report.Leaks[0])
+	assert.Equal(t, Key{Class: "[B", Site: "com.foo.Cache.load:10"}, report.Leaks[1].Key)
+	assert.Equal(t, int64(1024), report.Leaks[1].Oldest.ArrayElements)
+
+	chunks, err := parser.Parse(bytes.NewReader(leakRecording()))
+	require.NoError(t, err)
+	legacy, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, report, legacy)
+
+	var buf strings.Builder
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `COUNT  SIZE  OLDEST  CLASS          ALLOCATION SITE        ROOT
+2      64    5s      com.foo.Entry  com.foo.Cache.put:10   Global Object Handle
+1      1040  1s      [B             com.foo.Cache.load:10  -
+
+com.foo.Entry allocated at com.foo.Cache.put:10, 5s old:
+  root: Global Object Handle, Class Loader Data
+  com.foo.Cache.table
+  ... 2 objects skipped
+  [Lcom.foo.Entry;[3] of 16
+  com.foo.Entry
+`, buf.String())
+
+	var js bytes.Buffer
+	require.NoError(t, report.WriteJSON(&js))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(t, report.Leaks[0].Oldest.Chain, decoded.Leaks[0].Oldest.Chain)
+}
+
+func TestMinAge(t *testing.T) {
+	report, err := FromParser(parser.NewParser(leakRecording(), parser.Options{}), Options{MinAge: 2 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, report.Leaks, 1)
+	assert.Equal(t, int64(2), report.Leaks[0].Count)
+}
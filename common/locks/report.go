@@ -0,0 +1,221 @@
+// Package locks reports the lock contention of a recording, from the
+// jdk.JavaMonitorEnter and jdk.ThreadPark events, per lock class and
+// instance, with the threads that held the monitors.
+package locks
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// DefaultTopOwners is the number of owner threads reported per lock, unless
+// Options.TopOwners says otherwise.
+const DefaultTopOwners = 3
+
+type Options struct {
+	// TopOwners is the number of owner threads reported per lock,
+	// DefaultTopOwners when zero.
+	TopOwners int
+}
+
+type Kind int
+
+const (
+	// Monitor is the contention of synchronized blocks and methods, from
+	// jdk.JavaMonitorEnter events.
+	Monitor Kind = iota
+	// Park is the parking of threads in java.util.concurrent locks, from
+	// jdk.ThreadPark events.
+	Park
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Monitor:
+		return "monitor"
+	case Park:
+		return "park"
+	}
+	return "unknown"
+}
+
+// Key is a lock instance.
+type Key struct {
+	Kind Kind
+	// Class is the Java name of the monitor class or of the park blocker, such
+	// as java.util.concurrent.locks.ReentrantLock$NonfairSync. It is empty for
+	// parks without blocker.
+	Class string
+	// Address of the lock object, which tells instances of a class apart while
+	// the garbage collector does not move them.
+	Address uint64
+}
+
+// Owner is a thread that held a monitor while others waited for it.
+type Owner struct {
+	Thread string
+	// Count is the number of waits the thread caused.
+	Count int64
+	// Wait is the total time the other threads waited.
+	Wait time.Duration
+}
+
+type Lock struct {
+	Key
+	Count int64
+	Total time.Duration
+	P99   time.Duration
+	Max   time.Duration
+	// Timed is the number of parks with a timeout or deadline.
+	Timed int64
+	// Owners of monitors, by decreasing wait. Parks do not record them.
+	Owners []Owner
+}
+
+type Report struct {
+	// Locks are ordered by decreasing total wait.
+	Locks []Lock
+}
+
+type lock struct {
+	waits  []time.Duration
+	timed  int64
+	owners map[string]*Owner
+}
+
+type builder struct {
+	options Options
+	locks   map[Key]*lock
+}
+
+func newBuilder(options Options) *builder {
+	if options.TopOwners == 0 {
+		options.TopOwners = DefaultTopOwners
+	}
+	return &builder{
+		options: options,
+		locks:   make(map[Key]*lock),
+	}
+}
+
+// add records a wait. The class name may be in the internal form of the JVM,
+// java/lang/Object, the owner is empty when unknown.
+func (b *builder) add(key Key, wait time.Duration, owner string, timed bool) {
+	key.Class = strings.ReplaceAll(key.Class, "/", ".")
+	l, ok := b.locks[key]
+	if !ok {
+		l = &lock{owners: make(map[string]*Owner)}
+		b.locks[key] = l
+	}
+	l.waits = append(l.waits, wait)
+	if timed {
+		l.timed++
+	}
+	if owner == "" {
+		return
+	}
+	o, ok := l.owners[owner]
+	if !ok {
+		o = &Owner{Thread: owner}
+		l.owners[owner] = o
+	}
+	o.Count++
+	o.Wait += wait
+}
+
+func (b *builder) report() *Report {
+	r := &Report{Locks: make([]Lock, 0, len(b.locks))}
+	for key, l := range b.locks {
+		sort.Slice(l.waits, func(i, j int) bool { return l.waits[i] < l.waits[j] })
+		res := Lock{
+			Key:   key,
+			Count: int64(len(l.waits)),
+			P99:   report.Percentile(l.waits, 99),
+			Max:   l.waits[len(l.waits)-1],
+			Timed: l.timed,
+		}
+		for _, w := range l.waits {
+			res.Total += w
+		}
+		for _, o := range l.owners {
+			res.Owners = append(res.Owners, *o)
+		}
+		sort.Slice(res.Owners, func(i, j int) bool {
+			a, b := &res.Owners[i], &res.Owners[j]
+			if a.Wait != b.Wait {
+				return a.Wait > b.Wait
+			}
+			return a.Thread < b.Thread
+		})
+		if len(res.Owners) > b.options.TopOwners {
+			res.Owners = res.Owners[:b.options.TopOwners]
+		}
+		r.Locks = append(r.Locks, res)
+	}
+	sort.Slice(r.Locks, func(i, j int) bool {
+		a, b := &r.Locks[i], &r.Locks[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		return a.Address < b.Address
+	})
+	return r
+}
+
+// FromParser reads the remaining events of the parser into a report.
+func FromParser(p *parser.Parser, options Options) (*Report, error) {
+	b := newBuilder(options)
+	err := report.ParseEvents(p, func(typ def.TypeID) {
+		switch typ {
+		case p.TypeMap.T_MONITOR_ENTER:
+			e := &p.JavaMonitorEnter
+			key := Key{Kind: Monitor, Class: report.ClassName(p, e.MonitorClass), Address: e.Address}
+			b.add(key, p.TicksToDuration(e.Duration), report.ThreadName(p, e.PreviousOwner), false)
+		case p.TypeMap.T_THREAD_PARK:
+			e := &p.ThreadPark
+			key := Key{Kind: Park, Class: report.ClassName(p, e.ParkedClass), Address: e.Address}
+			// untimed parks record zero or Long.MIN_VALUE
+			timed := int64(e.Timeout) > 0 || int64(e.Until) > 0
+			b.add(key, p.TicksToDuration(e.Duration), "", timed)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.report(), nil
+}
+
+// WriteText writes the locks as a table.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tCLASS\tADDRESS\tCOUNT\tTOTAL\tP99\tMAX\tOWNERS")
+	for _, l := range r.Locks {
+		class := l.Class
+		if class == "" {
+			class = "-"
+		}
+		owners := make([]string, 0, len(l.Owners))
+		for _, o := range l.Owners {
+			owners = append(owners, fmt.Sprintf("%s (%d, %s)", o.Thread, o.Count, o.Wait))
+		}
+		if len(owners) == 0 {
+			owners = append(owners, "-")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%#x\t%d\t%s\t%s\t%s\t%s\n", l.Kind, class, l.Address, l.Count, l.Total, l.P99, l.Max, strings.Join(owners, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+package locks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	monitorEnter = 104
+	threadPark   = 105
+)
+
+func lockRecording() []byte {
+	r := jfrtest.New()
+	r.EventClass(monitorEnter, "jdk.JavaMonitorEnter",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "monitorClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "previousOwner", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})
+	r.EventClass(threadPark, "jdk.ThreadPark",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "parkedClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "timeout", Class: jfrtest.Long},
+		jfrtest.Field{Name: "until", Class: jfrtest.Long},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})
+
+	r.ClassConstant(1, "com/foo/Cache")
+	r.ClassConstant(2, "java/util/concurrent/locks/ReentrantLock$NonfairSync")
+	r.Constant(jfrtest.Thread, 1, "", uint64(11), "worker-1", uint64(1), false)
+	r.Constant(jfrtest.Thread, 2, "", uint64(12), "worker-2", uint64(2), false)
+	r.Constant(jfrtest.Thread, 3, "", uint64(13), "refresher", uint64(3), false)
+
+	ms := uint64(time.Millisecond)
+	start := uint64(jfrtest.StartTicks)
+	r.Event(monitorEnter, start, 10*ms, uint64(1), uint64(0), uint64(1), uint64(3), uint64(0x1000))
+	r.Event(monitorEnter, start, 30*ms, uint64(2), uint64(0), uint64(1), uint64(3), uint64(0x1000))
+	r.Event(monitorEnter, start, 5*ms, uint64(2), uint64(0), uint64(1), uint64(1), uint64(0x1000))
+	// another instance of the same class
+	r.Event(monitorEnter, start, 1*ms, uint64(1), uint64(0), uint64(1), uint64(3), uint64(0x2000))
+	r.Event(threadPark, start, 20*ms, uint64(1), uint64(0), uint64(2), uint64(0), uint64(0), uint64(0x3000))
+	r.Event(threadPark, start, 2*ms, uint64(2), uint64(0), uint64(2), 50*ms, uint64(0), uint64(0x3000))
+	return r.Bytes()
+}
+
+func TestReport(t *testing.T) {
+	report, err := FromParser(parser.NewParser(lockRecording(), parser.Options{}), Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []Lock{
+		{
+			Key:   Key{Kind: Monitor, Class: "com.foo.Cache", Address: 0x1000},
+			Count: 3, Total: 45 * time.Millisecond, P99: 30 * time.Millisecond, Max: 30 * time.Millisecond,
+			Owners: []Owner{
+				{Thread: "refresher", Count: 2, Wait: 40 * time.Millisecond},
+				{Thread: "worker-1", Count: 1, Wait: 5 * time.Millisecond},
+			},
+		},
+		{
+			Key:   Key{Kind: Park, Class: "java.util.concurrent.locks.ReentrantLock$NonfairSync", Address: 0x3000},
+			Count: 2, Total: 22 * time.Millisecond, P99: 20 * time.Millisecond, Max: 20 * time.Millisecond,
+			Timed: 1,
+		},
+		{
+			Key:   Key{Kind: Monitor, Class: "com.foo.Cache", Address: 0x2000},
+			Count: 1, Total: time.Millisecond, P99: time.Millisecond, Max: time.Millisecond,
+			Owners: []Owner{{Thread: "refresher", Count: 1, Wait: time.Millisecond}},
+		},
+	}, report.Locks)
+}
+
+func TestTopOwners(t *testing.T) {
+	report, err := FromParser(parser.NewParser(lockRecording(), parser.Options{}), Options{TopOwners: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []Owner{{Thread: "refresher", Count: 2, Wait: 40 * time.Millisecond}}, report.Locks[0].Owners)
+
+	var buf strings.Builder
+	require.NoError(t, report.WriteText(&buf))
+	assert.Equal(t, `KIND     CLASS                                                 ADDRESS  COUNT  TOTAL  P99   MAX   OWNERS
+monitor  com.foo.Cache                                         0x1000   3      45ms   30ms  30ms  refresher (2, 40ms)
+park     java.util.concurrent.locks.ReentrantLock$NonfairSync  0x3000   2      22ms   20ms  20ms  -
+monitor  com.foo.Cache                                         0x2000   1      1ms    1ms   1ms   refresher (1, 1ms)
+`, buf.String())
+}
@@ -0,0 +1,398 @@
+// Package metrics turns the periodic gauge events of a recording, such as
+// jdk.CPULoad, jdk.GCHeapSummary or jdk.NetworkUtilization, into timestamped
+// series, to backfill the dashboards of the time a recording covers. The
+// values are converted to the base unit of their kind, which the names end
+// with, as OpenMetrics expects.
+package metrics
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+const DefaultPrefix = "jfr"
+
+const (
+	Gauge   = "gauge"
+	Counter = "counter"
+)
+
+type Options struct {
+	// Prefix starts the names of the series, DefaultPrefix when empty.
+	Prefix string
+	// EventTypes limits the series to the ones of these event types, such
+	// as jdk.CPULoad; all the supported types when empty.
+	EventTypes []string
+}
+
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type Series struct {
+	// Name is the name of the metric family, without the _total suffix of
+	// the samples of the counters.
+	Name string `json:"name"`
+	// Type is Gauge or Counter.
+	Type string `json:"type"`
+	// Unit is the OpenMetrics unit the name ends with, such as bytes or
+	// ratio, empty for the counts.
+	Unit   string            `json:"unit"`
+	Help   string            `json:"help"`
+	Labels map[string]string `json:"labels"`
+	// Samples are ordered by time.
+	Samples []Sample `json:"samples"`
+}
+
+// Export holds the series ordered by name, then by labels.
+type Export struct {
+	Series []*Series `json:"series"`
+}
+
+// gauge reads a value of an event, either a quantity or a count.
+type gauge struct {
+	name     string
+	help     string
+	counter  bool
+	quantity *attributes.Attribute[units.IQuantity]
+	count    *attributes.Attribute[int64]
+	// bits tells that the quantity is a number of bits per second, which
+	// the parser reads as a frequency.
+	bits bool
+}
+
+// source is the event type of a group of gauges and the labels its events
+// tell the series apart with.
+type source struct {
+	eventType string
+	filter    parser.EventFilter
+	labels    func(event *parser.GenericEvent) (map[string]string, error)
+	gauges    []gauge
+}
+
+var sources = []source{
+	{types.CpuLoad, filters.CpuLoad, nil, []gauge{
+		{name: "cpu_load_jvm_user", help: "JVM user mode CPU load", quantity: attributes.JvmUser},
+		{name: "cpu_load_jvm_system", help: "JVM system mode CPU load", quantity: attributes.JvmSystem},
+		{name: "cpu_load_machine_total", help: "Machine CPU load", quantity: attributes.MachineTotal},
+	}},
+	{types.ThreadCpuLoad, filters.ThreadCpuLoad, threadLabels, []gauge{
+		{name: "thread_cpu_load_user", help: "Thread user mode CPU load", quantity: attributes.UserLoad},
+		{name: "thread_cpu_load_system", help: "Thread system mode CPU load", quantity: attributes.SystemLoad},
+	}},
+	{types.HeapSummary, filters.HeapSummary, heapLabels, []gauge{
+		{name: "gc_heap_used", help: "Java heap used before or after a garbage collection", quantity: attributes.HeapUsed},
+	}},
+	{types.OSMemorySummary, filters.OsMemorySummary, nil, []gauge{
+		{name: "physical_memory_total", help: "Total physical memory of the machine", quantity: attributes.PhysicalTotalSize},
+		{name: "physical_memory_used", help: "Physical memory used on the machine", quantity: attributes.PhysicalUsedSize},
+	}},
+	{types.ClassLoadStatistics, filters.ClassLoadStatistics, nil, []gauge{
+		{name: "classes_loaded", help: "Classes loaded since the start of the JVM", counter: true, count: attributes.LoadedClassCount},
+		{name: "classes_unloaded", help: "Classes unloaded since the start of the JVM", counter: true, count: attributes.UnloadedClassCount},
+	}},
+	{types.ThreadStatistics, filters.ThreadStatistics, nil, []gauge{
+		{name: "threads_active", help: "Live Java threads", count: attributes.ActiveThreads},
+		{name: "threads_daemon", help: "Live Java daemon threads", count: attributes.DaemonThreads},
+		{name: "threads_started", help: "Java threads started since the start of the JVM", counter: true, count: attributes.AccumulatedThreads},
+		{name: "threads_peak", help: "Peak number of live Java threads", count: attributes.PeakThreads},
+	}},
+	{types.ContextSwitchRate, filters.ContextSwitchRate, nil, []gauge{
+		{name: "thread_context_switch_rate", help: "Operating system thread context switches per second", quantity: attributes.ContextSwitches},
+	}},
+	{types.NetworkUtilization, filters.NetworkUtilization, networkLabels, []gauge{
+		{name: "network_read_rate", help: "Network interface read rate", quantity: attributes.ReadRate, bits: true},
+		{name: "network_write_rate", help: "Network interface write rate", quantity: attributes.WriteRate, bits: true},
+	}},
+}
+
+func threadLabels(event *parser.GenericEvent) (map[string]string, error) {
+	thread, err := attributes.EventThread.GetValue(event)
+	if err != nil {
+		return nil, err
+	}
+	name := thread.JavaName
+	if name == "" {
+		name = thread.OsName
+	}
+	return map[string]string{"thread": name}, nil
+}
+
+func heapLabels(event *parser.GenericEvent) (map[string]string, error) {
+	when, err := attributes.GcWhen.GetValue(event)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"when": when}, nil
+}
+
+func networkLabels(event *parser.GenericEvent) (map[string]string, error) {
+	name, err := attributes.NetworkInterface.GetValue(event)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"interface": name}, nil
+}
+
+// value returns the value of a gauge in the base unit of its kind, and the
+// OpenMetrics name of the unit.
+func (g *gauge) value(event *parser.GenericEvent) (float64, string, error) {
+	if g.count != nil {
+		n, err := g.count.GetValue(event)
+		return float64(n), "", err
+	}
+	q, err := g.quantity.GetValue(event)
+	if err != nil {
+		return 0, "", err
+	}
+	var unit string
+	switch q.Unit().Kind {
+	case units.Memory:
+		q, err = q.In(units.Byte)
+		unit = "bytes"
+	case units.Duration:
+		q, err = q.In(units.Second)
+		unit = "seconds"
+	case units.Percentage:
+		q, err = q.In(units.Multiple)
+		unit = "ratio"
+	case units.Frequency:
+		q, err = q.In(units.Hertz)
+		unit = "hertz"
+	default:
+		return 0, "", fmt.Errorf("unsupported unit %s of %s", q.Unit().Name, g.quantity.Name)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	if g.bits {
+		return q.FloatValue() / 8, "bytes_per_second", nil
+	}
+	return q.FloatValue(), unit, nil
+}
+
+// FromChunks reads the series of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Export, error) {
+	prefix := options.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	selected := make(map[string]bool)
+	for _, t := range options.EventTypes {
+		selected[t] = true
+	}
+	series := make(map[string]*Series)
+	for _, chunk := range chunks {
+		for _, s := range sources {
+			if len(selected) > 0 && !selected[s.eventType] {
+				continue
+			}
+			for _, event := range chunk.Apply(s.filter) {
+				start, err := attributes.StartTime.GetValue(event)
+				if err != nil {
+					return nil, err
+				}
+				t, err := units.ToTime(start)
+				if err != nil {
+					return nil, err
+				}
+				var labels map[string]string
+				if s.labels != nil {
+					if labels, err = s.labels(event); err != nil {
+						return nil, fmt.Errorf("%s: %w", s.eventType, err)
+					}
+				}
+				for i := range s.gauges {
+					g := &s.gauges[i]
+					v, unit, err := g.value(event)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", s.eventType, err)
+					}
+					name := prefix + "_" + g.name
+					if unit != "" {
+						name += "_" + unit
+					}
+					key := name + key(labels)
+					ts, ok := series[key]
+					if !ok {
+						ts = &Series{Name: name, Type: Gauge, Unit: unit, Help: g.help, Labels: labels}
+						if g.counter {
+							ts.Type = Counter
+						}
+						if ts.Labels == nil {
+							ts.Labels = map[string]string{}
+						}
+						series[key] = ts
+					}
+					ts.Samples = append(ts.Samples, Sample{Time: t, Value: v})
+				}
+			}
+		}
+	}
+	e := &Export{}
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := series[k]
+		sort.SliceStable(s.Samples, func(i, j int) bool {
+			return s.Samples[i].Time.Before(s.Samples[j].Time)
+		})
+		e.Series = append(e.Series, s)
+	}
+	return e, nil
+}
+
+// key orders the labels of a series, to identify it.
+func key(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString("\x00" + name + "\x00" + labels[name])
+	}
+	return b.String()
+}
+
+// WriteOpenMetrics writes the series in the OpenMetrics text format, with
+// the timestamps of the samples.
+func (e *Export) WriteOpenMetrics(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var family string
+	for _, s := range e.Series {
+		if s.Name != family {
+			family = s.Name
+			fmt.Fprintf(bw, "# TYPE %s %s\n", s.Name, s.Type)
+			if s.Unit != "" {
+				fmt.Fprintf(bw, "# UNIT %s %s\n", s.Name, s.Unit)
+			}
+			fmt.Fprintf(bw, "# HELP %s %s\n", s.Name, escape(s.Help, false))
+		}
+		name := s.Name
+		if s.Type == Counter {
+			name += "_total"
+		}
+		labels := openMetricsLabels(s.Labels)
+		for _, sample := range s.Samples {
+			fmt.Fprintf(bw, "%s%s %s %s\n", name, labels, formatFloat(sample.Value), timestamp(sample.Time))
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+func openMetricsLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := labelNames([]map[string]string{labels})
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+escape(labels[name], true)+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes the backslashes and the line feeds of a help text, and the
+// double quotes of a label value.
+func escape(s string, quotes bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quotes {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		// the byte and class counts without an exponent
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// timestamp formats t as the seconds since the epoch, to the nanosecond.
+func timestamp(t time.Time) string {
+	s := strconv.FormatInt(t.Unix(), 10)
+	if ns := t.Nanosecond(); ns != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+	}
+	return s
+}
+
+func labelNames(labels []map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, l := range labels {
+		for name := range l {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteCSV writes a row per sample: the time, the name of the series, the
+// value and a column per label name of all the series, empty for the series
+// without the label.
+func (e *Export) WriteCSV(w io.Writer) error {
+	all := make([]map[string]string, 0, len(e.Series))
+	for _, s := range e.Series {
+		all = append(all, s.Labels)
+	}
+	names := labelNames(all)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"time", "metric", "value"}, names...)); err != nil {
+		return err
+	}
+	row := make([]string, 3+len(names))
+	for _, s := range e.Series {
+		row[1] = s.Name
+		if s.Type == Counter {
+			row[1] += "_total"
+		}
+		for i, name := range names {
+			row[3+i] = s.Labels[name]
+		}
+		for _, sample := range s.Samples {
+			row[0] = sample.Time.UTC().Format(time.RFC3339Nano)
+			row[2] = formatFloat(sample.Value)
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromChunks(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	export, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	series := make(map[string]*Series)
+	for _, s := range export.Series {
+		series[s.Name+key(s.Labels)] = s
+	}
+	cpu := series["jfr_cpu_load_machine_total_ratio"]
+	require.NotNil(t, cpu)
+	assert.Equal(t, Gauge, cpu.Type)
+	assert.Equal(t, "ratio", cpu.Unit)
+	assert.Len(t, cpu.Samples, 59)
+	for i, s := range cpu.Samples {
+		assert.True(t, s.Value >= 0 && s.Value <= 1, s.Value)
+		if i > 0 {
+			assert.False(t, s.Time.Before(cpu.Samples[i-1].Time))
+		}
+	}
+
+	heap := series["jfr_gc_heap_used_bytes"+key(map[string]string{"when": "Before GC"})]
+	require.NotNil(t, heap)
+	assert.Equal(t, "bytes", heap.Unit)
+	assert.Len(t, heap.Samples, 658)
+
+	loaded := series["jfr_classes_loaded"]
+	require.NotNil(t, loaded)
+	assert.Equal(t, Counter, loaded.Type)
+	assert.Equal(t, Sample{Time: time.Unix(0, 1673597259335437811), Value: 6544}, loaded.Samples[0])
+
+	network := series["jfr_network_read_rate_bytes_per_second"+key(map[string]string{"interface": "en0"})]
+	require.NotNil(t, network)
+	assert.Len(t, network.Samples, 11)
+
+	export, err = FromChunks(chunks, Options{Prefix: "app_jvm", EventTypes: []string{types.ContextSwitchRate}})
+	require.NoError(t, err)
+	require.Len(t, export.Series, 1)
+	assert.Equal(t, "app_jvm_thread_context_switch_rate_hertz", export.Series[0].Name)
+	assert.Len(t, export.Series[0].Samples, 5)
+}
+
+const (
+	cpuLoad          = 100
+	threadCPULoad    = 101
+	threadStatistics = 102
+)
+
+func recording() []byte {
+	r := jfrtest.New()
+	r.EventClass(cpuLoad, types.CpuLoad,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "jvmUser", Class: jfrtest.Float, Annotation: jfrtest.Percentage},
+		jfrtest.Field{Name: "jvmSystem", Class: jfrtest.Float, Annotation: jfrtest.Percentage},
+		jfrtest.Field{Name: "machineTotal", Class: jfrtest.Float, Annotation: jfrtest.Percentage})
+	r.EventClass(threadCPULoad, types.ThreadCpuLoad,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "user", Class: jfrtest.Float, Annotation: jfrtest.Percentage},
+		jfrtest.Field{Name: "system", Class: jfrtest.Float, Annotation: jfrtest.Percentage})
+	r.EventClass(threadStatistics, types.ThreadStatistics,
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "activeCount", Class: jfrtest.Long},
+		jfrtest.Field{Name: "daemonCount", Class: jfrtest.Long},
+		jfrtest.Field{Name: "accumulatedCount", Class: jfrtest.Long},
+		jfrtest.Field{Name: "peakCount", Class: jfrtest.Long})
+
+	r.Constant(jfrtest.Thread, 1, "worker \"1\"", uint64(11), "worker \"1\"", uint64(1), false)
+
+	start := uint64(jfrtest.StartTicks)
+	// out of order, as the events of different threads are
+	r.Event(cpuLoad, start+1_000_000_000, float32(0.5), float32(0.25), float32(1))
+	r.Event(cpuLoad, start, float32(0.125), float32(0), float32(0.5))
+	r.Event(threadCPULoad, start+500_000_000, uint64(1), float32(0.75), float32(0))
+	r.Event(threadStatistics, start, uint64(12), uint64(10), uint64(40), uint64(14))
+	return r.Bytes()
+}
+
+func TestWrite(t *testing.T) {
+	chunks, err := parser.Parse(bytes.NewReader(recording()))
+	require.NoError(t, err)
+	export, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	var om strings.Builder
+	require.NoError(t, export.WriteOpenMetrics(&om))
+	assert.Equal(t, `# TYPE jfr_cpu_load_jvm_system_ratio gauge
+# UNIT jfr_cpu_load_jvm_system_ratio ratio
+# HELP jfr_cpu_load_jvm_system_ratio JVM system mode CPU load
+jfr_cpu_load_jvm_system_ratio 0 1700000000
+jfr_cpu_load_jvm_system_ratio 0.25 1700000001
+# TYPE jfr_cpu_load_jvm_user_ratio gauge
+# UNIT jfr_cpu_load_jvm_user_ratio ratio
+# HELP jfr_cpu_load_jvm_user_ratio JVM user mode CPU load
+jfr_cpu_load_jvm_user_ratio 0.125 1700000000
+jfr_cpu_load_jvm_user_ratio 0.5 1700000001
+# TYPE jfr_cpu_load_machine_total_ratio gauge
+# UNIT jfr_cpu_load_machine_total_ratio ratio
+# HELP jfr_cpu_load_machine_total_ratio Machine CPU load
+jfr_cpu_load_machine_total_ratio 0.5 1700000000
+jfr_cpu_load_machine_total_ratio 1 1700000001
+# TYPE jfr_thread_cpu_load_system_ratio gauge
+# UNIT jfr_thread_cpu_load_system_ratio ratio
+# HELP jfr_thread_cpu_load_system_ratio Thread system mode CPU load
+jfr_thread_cpu_load_system_ratio{thread="worker \"1\""} 0 1700000000.5
+# TYPE jfr_thread_cpu_load_user_ratio gauge
+# UNIT jfr_thread_cpu_load_user_ratio ratio
+# HELP jfr_thread_cpu_load_user_ratio Thread user mode CPU load
+jfr_thread_cpu_load_user_ratio{thread="worker \"1\""} 0.75 1700000000.5
+# TYPE jfr_threads_active gauge
+# HELP jfr_threads_active Live Java threads
+jfr_threads_active 12 1700000000
+# TYPE jfr_threads_daemon gauge
+# HELP jfr_threads_daemon Live Java daemon threads
+jfr_threads_daemon 10 1700000000
+# TYPE jfr_threads_peak gauge
+# HELP jfr_threads_peak Peak number of live Java threads
+jfr_threads_peak 14 1700000000
+# TYPE jfr_threads_started counter
+# HELP jfr_threads_started Java threads started since the start of the JVM
+jfr_threads_started_total 40 1700000000
+# EOF
+`, om.String())
+
+	var csv strings.Builder
+	require.NoError(t, export.WriteCSV(&csv))
+	assert.Equal(t, `time,metric,value,thread
+2023-11-14T22:13:20Z,jfr_cpu_load_jvm_system_ratio,0,
+2023-11-14T22:13:21Z,jfr_cpu_load_jvm_system_ratio,0.25,
+2023-11-14T22:13:20Z,jfr_cpu_load_jvm_user_ratio,0.125,
+2023-11-14T22:13:21Z,jfr_cpu_load_jvm_user_ratio,0.5,
+2023-11-14T22:13:20Z,jfr_cpu_load_machine_total_ratio,0.5,
+2023-11-14T22:13:21Z,jfr_cpu_load_machine_total_ratio,1,
+2023-11-14T22:13:20.5Z,jfr_thread_cpu_load_system_ratio,0,"worker ""1"""
+2023-11-14T22:13:20.5Z,jfr_thread_cpu_load_user_ratio,0.75,"worker ""1"""
+2023-11-14T22:13:20Z,jfr_threads_active,12,
+2023-11-14T22:13:20Z,jfr_threads_daemon,10,
+2023-11-14T22:13:20Z,jfr_threads_peak,14,
+2023-11-14T22:13:20Z,jfr_threads_started_total,40,
+`, csv.String())
+}
@@ -0,0 +1,283 @@
+// Package safepoints reconstructs the safepoints of a recording from the
+// jdk.SafepointBegin, jdk.SafepointStateSynchronization,
+// jdk.SafepointCleanup and jdk.SafepointEnd events, and the VM operations
+// they ran, to tell the time to reach a safepoint from the time of the
+// operation.
+package safepoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/common/filters"
+	"github.com/grafana/jfr-parser/common/types"
+	"github.com/grafana/jfr-parser/common/units"
+	"github.com/grafana/jfr-parser/internal/report"
+	"github.com/grafana/jfr-parser/parser"
+)
+
+type Options struct {
+	// MinTotal leaves the safepoints shorter than it out of the timeline,
+	// not out of the summaries.
+	MinTotal time.Duration
+}
+
+type Safepoint struct {
+	ID    int64     `json:"id"`
+	Start time.Time `json:"start"`
+	// Operation is the type of the VM operation, such as
+	// G1CollectForAllocation, empty when the recording has no
+	// jdk.ExecuteVMOperation event for the safepoint.
+	Operation string `json:"operation"`
+	// TimeToSafepoint is the time the VM thread waited for the Java threads
+	// to stop.
+	TimeToSafepoint time.Duration `json:"timeToSafepoint"`
+	Cleanup         time.Duration `json:"cleanup"`
+	// OperationTime is the duration of the outermost VM operation, which
+	// includes the operations it nested.
+	OperationTime time.Duration `json:"operationTime"`
+	// Total is the time the Java threads were stopped or stopping, from the
+	// start of the synchronization to the end of the safepoint.
+	Total time.Duration `json:"total"`
+	// Threads is the number of Java threads to stop, JNICritical the number
+	// of them in JNI critical regions, which delay the garbage collections.
+	Threads     int64 `json:"threads"`
+	JNICritical int64 `json:"jniCritical"`
+	// Running is the number of threads still running when the
+	// synchronization started, Iterations the number of times the VM thread
+	// checked them.
+	Running    int64 `json:"running"`
+	Iterations int64 `json:"iterations"`
+}
+
+// Durations summarizes a part of the safepoints.
+type Durations struct {
+	Total time.Duration `json:"total"`
+	Max   time.Duration `json:"max"`
+	P99   time.Duration `json:"p99"`
+}
+
+// Summary summarizes the safepoints of an operation type, or all of them.
+type Summary struct {
+	Operation       string    `json:"operation"`
+	Count           int64     `json:"count"`
+	TimeToSafepoint Durations `json:"timeToSafepoint"`
+	OperationTime   Durations `json:"operationTime"`
+	Total           Durations `json:"total"`
+}
+
+type Report struct {
+	// All summarizes every safepoint, its operation is empty.
+	All Summary `json:"all"`
+	// Operations are ordered by decreasing total time.
+	Operations []Summary `json:"operations"`
+	// Timeline is ordered by start.
+	Timeline []Safepoint `json:"timeline"`
+}
+
+type safepoint struct {
+	Safepoint
+	begun, synchronized bool
+	begin               time.Duration
+	end                 time.Time
+}
+
+type builder struct {
+	options    Options
+	safepoints map[int64]*safepoint
+}
+
+func newBuilder(options Options) *builder {
+	return &builder{
+		options:    options,
+		safepoints: make(map[int64]*safepoint),
+	}
+}
+
+func (b *builder) safepoint(id int64) *safepoint {
+	s, ok := b.safepoints[id]
+	if !ok {
+		s = &safepoint{Safepoint: Safepoint{ID: id}}
+		b.safepoints[id] = s
+	}
+	return s
+}
+
+func (b *builder) report() *Report {
+	r := &Report{}
+	var all []*Safepoint
+	byOperation := make(map[string][]*Safepoint)
+	for _, s := range b.safepoints {
+		if !s.begun {
+			// operation of a safepoint which started before the recording
+			continue
+		}
+		if !s.synchronized {
+			// jdk.SafepointBegin lasts until the start of the operation
+			s.TimeToSafepoint = s.begin - s.Cleanup
+		}
+		if s.end.IsZero() {
+			s.Total = s.begin + s.OperationTime
+		} else {
+			s.Total = s.end.Sub(s.Start)
+		}
+		all = append(all, &s.Safepoint)
+		byOperation[s.Operation] = append(byOperation[s.Operation], &s.Safepoint)
+		if s.Total >= b.options.MinTotal {
+			r.Timeline = append(r.Timeline, s.Safepoint)
+		}
+	}
+	r.All = summarize("", all)
+	for operation, safepoints := range byOperation {
+		r.Operations = append(r.Operations, summarize(operation, safepoints))
+	}
+	sort.Slice(r.Operations, func(i, j int) bool {
+		a, b := &r.Operations[i], &r.Operations[j]
+		if a.Total.Total != b.Total.Total {
+			return a.Total.Total > b.Total.Total
+		}
+		return a.Operation < b.Operation
+	})
+	sort.Slice(r.Timeline, func(i, j int) bool {
+		a, b := &r.Timeline[i], &r.Timeline[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return a.ID < b.ID
+	})
+	return r
+}
+
+func summarize(operation string, safepoints []*Safepoint) Summary {
+	s := Summary{Operation: operation, Count: int64(len(safepoints))}
+	if len(safepoints) == 0 {
+		return s
+	}
+	durations := make([]time.Duration, len(safepoints))
+	for _, part := range []struct {
+		summary *Durations
+		get     func(*Safepoint) time.Duration
+	}{
+		{&s.TimeToSafepoint, func(s *Safepoint) time.Duration { return s.TimeToSafepoint }},
+		{&s.OperationTime, func(s *Safepoint) time.Duration { return s.OperationTime }},
+		{&s.Total, func(s *Safepoint) time.Duration { return s.Total }},
+	} {
+		for i, sp := range safepoints {
+			durations[i] = part.get(sp)
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		for _, d := range durations {
+			part.summary.Total += d
+		}
+		part.summary.Max = durations[len(durations)-1]
+		part.summary.P99 = report.Percentile(durations, 99)
+	}
+	return s
+}
+
+// FromChunks reports the events of chunks read by parser.Parse.
+func FromChunks(chunks []*parser.Chunk, options Options) (*Report, error) {
+	b := newBuilder(options)
+	for _, chunk := range chunks {
+		for _, event := range chunk.Apply(filters.SafePoints) {
+			id, start, duration, err := timing(event)
+			if err != nil {
+				return nil, err
+			}
+			s := b.safepoint(id)
+			switch event.ClassMetadata.Name {
+			case types.SafepointBegin:
+				s.begun = true
+				s.Start, s.begin = start, duration
+				s.Threads, _ = attributes.TotalThreadCount.GetValue(event)
+				s.JNICritical, _ = attributes.JniCriticalThreads.GetValue(event)
+			case types.SafepointStateSync:
+				s.synchronized = true
+				s.TimeToSafepoint = duration
+				s.Running, _ = attributes.RunningThreadCount.GetValue(event)
+				s.Iterations, _ = attributes.SyncIterations.GetValue(event)
+			case types.SafepointCleanup:
+				s.Cleanup = duration
+			case types.SafepointEnd:
+				s.end = start.Add(duration)
+			}
+		}
+		for _, event := range chunk.Apply(filters.VmOperationsSafepoint) {
+			id, _, duration, err := timing(event)
+			if err != nil {
+				return nil, err
+			}
+			s := b.safepoint(id)
+			// nested operations run in the same safepoint, within the
+			// outermost one, which lasts the longest
+			if s.Operation != "" && duration <= s.OperationTime {
+				continue
+			}
+			if s.Operation, err = attributes.VmOperation.GetValue(event); err != nil {
+				return nil, err
+			}
+			s.OperationTime = duration
+		}
+	}
+	return b.report(), nil
+}
+
+// timing returns the safepoint identifier, the start and the duration of an
+// event.
+func timing(event *parser.GenericEvent) (int64, time.Time, time.Duration, error) {
+	id, err := attributes.SafepointId.GetValue(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	startTime, err := attributes.StartTime.GetValue(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	d, err := attributes.Duration.GetValue(event)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	duration, err := units.ToDuration(d)
+	if err != nil {
+		return 0, time.Time{}, 0, err
+	}
+	return id, time.Unix(0, startTime.IntValue()), duration, nil
+}
+
+// WriteJSON writes the report as indented JSON, with durations in
+// nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the summaries and the timeline as tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tCOUNT\tTTSP TOTAL\tTTSP MAX\tTTSP P99\tOPERATION TOTAL\tOPERATION MAX\tTOTAL\tMAX\tP99")
+	for _, s := range r.Operations {
+		writeSummary(tw, report.OrDash(s.Operation), s)
+	}
+	writeSummary(tw, "all", r.All)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "START\tID\tOPERATION\tTTSP\tCLEANUP\tOPERATION TIME\tTOTAL\tTHREADS\tRUNNING")
+	for _, s := range r.Timeline {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%d\t%d\n", s.Start.UTC().Format(time.RFC3339Nano), s.ID, report.OrDash(s.Operation),
+			s.TimeToSafepoint, s.Cleanup, s.OperationTime, s.Total, s.Threads, s.Running)
+	}
+	return tw.Flush()
+}
+
+func writeSummary(w io.Writer, operation string, s Summary) {
+	fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", operation, s.Count,
+		s.TimeToSafepoint.Total, s.TimeToSafepoint.Max, s.TimeToSafepoint.P99,
+		s.OperationTime.Total, s.OperationTime.Max,
+		s.Total.Total, s.Total.Max, s.Total.P99)
+}
@@ -0,0 +1,125 @@
+package safepoints
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	chunks, err := parser.ParseFile("../../parser/testdata/prof.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(112), report.All.Count)
+	assert.Equal(t, 18988282*time.Nanosecond, report.All.TimeToSafepoint.Total)
+	assert.Equal(t, 57249981*time.Nanosecond, report.All.Total.Total)
+	assert.Len(t, report.Timeline, 112)
+
+	require.Len(t, report.Operations, 5)
+	revoke := report.Operations[0]
+	assert.Equal(t, "RevokeBias", revoke.Operation)
+	assert.Equal(t, int64(76), revoke.Count)
+	assert.Equal(t, 4144222*time.Nanosecond, revoke.TimeToSafepoint.Max)
+	assert.Equal(t, 323835*time.Nanosecond, revoke.OperationTime.Max)
+
+	for _, s := range report.Timeline {
+		assert.GreaterOrEqual(t, s.Total, s.TimeToSafepoint+s.OperationTime, "safepoint %d", s.ID)
+	}
+}
+
+func TestWithoutSynchronization(t *testing.T) {
+	// ddtrace.jfr has no jdk.SafepointStateSynchronization nor
+	// jdk.SafepointEnd events
+	chunks, err := parser.ParseFile("../../parser/testdata/ddtrace.jfr")
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{MinTotal: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(828), report.All.Count)
+	require.Len(t, report.Timeline, 1)
+	s := report.Timeline[0]
+	assert.Equal(t, int64(513), s.ID)
+	assert.Equal(t, "RedefineClasses", s.Operation)
+	assert.Equal(t, 114583*time.Nanosecond, s.TimeToSafepoint)
+	assert.Equal(t, 680282167*time.Nanosecond, s.OperationTime)
+	assert.Equal(t, s.TimeToSafepoint+s.OperationTime, s.Total)
+	assert.Equal(t, int64(38), s.Threads)
+
+	var buf strings.Builder
+	require.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "\n2023-01-13T08:07:38.34860677Z  513  RedefineClasses  114.583µs  0s       680.282167ms    680.39675ms  38       0\n")
+}
+
+const (
+	vmOperationType = 100
+	safepointBegin  = 101
+	safepointEnd    = 102
+	vmOperation     = 103
+)
+
+func nestedRecording() []byte {
+	duration := jfrtest.Field{Name: "duration", Class: jfrtest.Long, Annotation: jfrtest.Timespan, Value: "TICKS"}
+	r := jfrtest.New()
+	r.Class(vmOperationType, "jdk.types.VMOperationType",
+		jfrtest.Field{Name: "type", Class: jfrtest.String})
+	r.EventClass(safepointBegin, "jdk.SafepointBegin",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		duration,
+		jfrtest.Field{Name: "safepointId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "totalThreadCount", Class: jfrtest.Int},
+		jfrtest.Field{Name: "jniCriticalThreadCount", Class: jfrtest.Int})
+	r.EventClass(safepointEnd, "jdk.SafepointEnd",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		duration,
+		jfrtest.Field{Name: "safepointId", Class: jfrtest.Long})
+	r.EventClass(vmOperation, "jdk.ExecuteVMOperation",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		duration,
+		jfrtest.Field{Name: "operation", Class: vmOperationType, CPool: true},
+		jfrtest.Field{Name: "safepoint", Class: jfrtest.Boolean},
+		jfrtest.Field{Name: "safepointId", Class: jfrtest.Long})
+
+	r.Constant(vmOperationType, 1, "G1CollectFull")
+	r.Constant(vmOperationType, 2, "HandshakeAllThreads")
+
+	start := uint64(jfrtest.StartTicks)
+	ms := uint64(time.Millisecond)
+	// the nested operation ends, and is recorded, before the outer one
+	r.Event(safepointBegin, start, ms, uint64(1), 10, 0)
+	r.Event(vmOperation, start+2*ms, 2*ms, uint64(2), true, uint64(1))
+	r.Event(vmOperation, start+ms, 5*ms, uint64(1), true, uint64(1))
+	r.Event(safepointEnd, start+6*ms, uint64(0), uint64(1))
+	// without jdk.SafepointEnd
+	r.Event(safepointBegin, start+10*ms, ms, uint64(2), 10, 0)
+	r.Event(vmOperation, start+11*ms, 3*ms, uint64(1), true, uint64(2))
+	r.Event(vmOperation, start+12*ms, ms, uint64(2), true, uint64(2))
+	return r.Bytes()
+}
+
+func TestNestedOperations(t *testing.T) {
+	chunks, err := parser.Parse(bytes.NewReader(nestedRecording()))
+	require.NoError(t, err)
+	report, err := FromChunks(chunks, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, report.Timeline, 2)
+	s := report.Timeline[0]
+	assert.Equal(t, "G1CollectFull", s.Operation)
+	assert.Equal(t, 5*time.Millisecond, s.OperationTime)
+	assert.Equal(t, 6*time.Millisecond, s.Total)
+	s = report.Timeline[1]
+	assert.Equal(t, "G1CollectFull", s.Operation)
+	assert.Equal(t, 3*time.Millisecond, s.OperationTime)
+	assert.Equal(t, 4*time.Millisecond, s.Total)
+
+	require.Len(t, report.Operations, 1)
+	assert.Equal(t, int64(2), report.Operations[0].Count)
+}
@@ -1,8 +1,9 @@
 package types
 
 const (
-	jdkTypePrefix     = "jdk."
-	datadogTypePrefix = "datadog."
+	jdkTypePrefix      = "jdk."
+	datadogTypePrefix  = "datadog."
+	profilerTypePrefix = "profiler."
 )
 
 const (
@@ -15,6 +16,12 @@ const (
 	OSMemorySummary              = jdkTypePrefix + "PhysicalMemory"
 	OSInformation                = jdkTypePrefix + "OSInformation"
 	CPUInformation               = jdkTypePrefix + "CPUInformation"
+	VirtualizationInformation    = jdkTypePrefix + "VirtualizationInformation"
+	ContainerConfiguration       = jdkTypePrefix + "ContainerConfiguration"
+	ContainerCPUUsage            = jdkTypePrefix + "ContainerCPUUsage"
+	ContainerCPUThrottling       = jdkTypePrefix + "ContainerCPUThrottling"
+	ContainerMemoryUsage         = jdkTypePrefix + "ContainerMemoryUsage"
+	ContainerIOUsage             = jdkTypePrefix + "ContainerIOUsage"
 	ThreadAllocationStatistics   = jdkTypePrefix + "ThreadAllocationStatistics"
 	HeapConf                     = jdkTypePrefix + "GCHeapConfiguration"
 	GcConf                       = jdkTypePrefix + "GCConfiguration"
@@ -29,6 +36,7 @@ const (
 	ClassLoadStatistics          = jdkTypePrefix + "ClassLoadingStatistics"
 	ClassLoaderStatistics        = jdkTypePrefix + "ClassLoaderStatistics"
 	Compilation                  = jdkTypePrefix + "Compilation"
+	Deoptimization               = jdkTypePrefix + "Deoptimization"
 	FileWrite                    = jdkTypePrefix + "FileWrite"
 	FileRead                     = jdkTypePrefix + "FileRead"
 	SocketWrite                  = jdkTypePrefix + "SocketWrite"
@@ -88,10 +96,15 @@ const (
 	GcConfTlab                         = jdkTypePrefix + "GCTLABConfiguration"
 	JavaThreadStart                    = jdkTypePrefix + "ThreadStart"
 	JavaThreadEnd                      = jdkTypePrefix + "ThreadEnd"
+	VirtualThreadStart                 = jdkTypePrefix + "VirtualThreadStart"
+	VirtualThreadEnd                   = jdkTypePrefix + "VirtualThreadEnd"
+	VirtualThreadPinned                = jdkTypePrefix + "VirtualThreadPinned"
+	VirtualThreadSubmitFailed          = jdkTypePrefix + "VirtualThreadSubmitFailed"
 	VmOperations                       = jdkTypePrefix + "ExecuteVMOperation"
 	VmShutdown                         = jdkTypePrefix + "Shutdown"
 	ThreadStatistics                   = jdkTypePrefix + "JavaThreadStatistics"
 	ContextSwitchRate                  = jdkTypePrefix + "ThreadContextSwitchRate"
+	NetworkUtilization                 = jdkTypePrefix + "NetworkUtilization"
 	CompilerConfig                     = jdkTypePrefix + "CompilerConfiguration"
 	CodeCacheConfig                    = jdkTypePrefix + "CodeCacheConfiguration"
 	CodeSweeperConfig                  = jdkTypePrefix + "CodeSweeperConfiguration"
@@ -183,3 +196,9 @@ const (
 	//datadog.DeadlockedThread	Deadlocked Thread	Datadog deadlock detection event - thread details. [datadog.DeadlockedThread]	null
 	DatadogDeadlockedThread = datadogTypePrefix + "DeadlockedThread"
 )
+
+const (
+	// Malloc and Free are recorded by async-profiler in nativemem mode
+	Malloc = profilerTypePrefix + "Malloc"
+	Free   = profilerTypePrefix + "Free"
+)
@@ -41,6 +41,15 @@ const (
 	GCWhen               FieldClass = "jdk.types.GCWhen"
 	ReferenceType        FieldClass = "jdk.types.ReferenceType"
 	MetadataType         FieldClass = "jdk.types.MetadataType"
+	DeoptimizationReason FieldClass = "jdk.types.DeoptimizationReason"
+	DeoptimizationAction FieldClass = "jdk.types.DeoptimizationAction"
+	OldObject            FieldClass = "jdk.types.OldObject"
+	Reference            FieldClass = "jdk.types.Reference"
+	OldObjectArray       FieldClass = "jdk.types.OldObjectArray"
+	OldObjectField       FieldClass = "jdk.types.OldObjectField"
+	OldObjectGcRoot      FieldClass = "jdk.types.OldObjectGcRoot"
+	OldObjectRootSystem  FieldClass = "jdk.types.OldObjectRootSystem"
+	OldObjectRootType    FieldClass = "jdk.types.OldObjectRootType"
 	LogLevel             FieldClass = "profiler.types.LogLevel"
 	AttributeValue       FieldClass = "profiler.types.AttributeValue"
 )
@@ -2,6 +2,7 @@ package units
 
 import (
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -21,3 +22,20 @@ func ToTime(quantity IQuantity) (time.Time, error) {
 
 	return time.Unix(0, quantity.IntValue()), nil
 }
+
+func ToDuration(quantity IQuantity) (time.Duration, error) {
+	if quantity.Unit() == nil {
+		return 0, fmt.Errorf("nil unit")
+	}
+	if kind := quantity.Unit().Kind; kind != Duration {
+		return 0, fmt.Errorf("not kind of duration: %q", kind.String())
+	}
+
+	quantity, err := quantity.In(Nanosecond)
+	if err != nil {
+		return 0, fmt.Errorf("unable to be converted to nanoseconds: %w", err)
+	}
+
+	// ticks convert to fractional nanoseconds
+	return time.Duration(math.Round(quantity.FloatValue())), nil
+}
@@ -60,6 +60,27 @@ func main() {
 	write("types/stacktrace.go", generate(&Type_jdk_types_StackTrace, options{
 		cpool: true,
 	}))
+	write("types/old_object.go", generate(&Type_jdk_types_OldObject, options{
+		cpool: true,
+	}))
+	write("types/reference.go", generate(&Type_jdk_types_Reference, options{
+		cpool: true,
+	}))
+	write("types/old_object_array.go", generate(&Type_jdk_types_OldObjectArray, options{
+		cpool: true,
+	}))
+	write("types/old_object_field.go", generate(&Type_jdk_types_OldObjectField, options{
+		cpool: true,
+	}))
+	write("types/old_object_gc_root.go", generate(&Type_jdk_types_OldObjectGcRoot, options{
+		cpool: true,
+	}))
+	write("types/old_object_root_system.go", generate(&Type_jdk_types_OldObjectRootSystem, options{
+		cpool: true,
+	}))
+	write("types/old_object_root_type.go", generate(&Type_jdk_types_OldObjectRootType, options{
+		cpool: true,
+	}))
 
 	write("types/active_settings.go", generate(&Type_jdk_ActiveSetting, options{}))
 
@@ -69,6 +90,32 @@ func main() {
 	write("types/monitor_enter.go", generate(&Type_jdk_JavaMonitorEnter, options{}))
 	write("types/thread_park.go", generate(&Type_jdk_ThreadPark, options{}))
 	write("types/live_object.go", generate(&Type_profiler_LiveObject, options{}))
+	write("types/malloc.go", generate(&Type_profiler_Malloc, options{}))
+	write("types/free.go", generate(&Type_profiler_Free, options{}))
+	write("types/datadog_execution_sample.go", generate(&Type_datadog_ExecutionSample, options{}))
+	write("types/datadog_method_sample.go", generate(&Type_datadog_MethodSample, options{}))
+	write("types/datadog_object_sample.go", generate(&Type_datadog_ObjectSample, options{}))
+	write("types/datadog_heap_live_object.go", generate(&Type_datadog_HeapLiveObject, options{}))
+	write("types/datadog_exception_sample.go", generate(&Type_datadog_ExceptionSample, options{}))
+	write("types/datadog_endpoint.go", generate(&Type_datadog_Endpoint, options{}))
+	write("types/exception_throw.go", generate(&Type_jdk_JavaExceptionThrow, options{}))
+	write("types/error_throw.go", generate(&Type_jdk_JavaErrorThrow, options{}))
+	write("types/exception_statistics.go", generate(&Type_jdk_ExceptionStatistics, options{}))
+	write("types/file_read.go", generate(&Type_jdk_FileRead, options{}))
+	write("types/file_write.go", generate(&Type_jdk_FileWrite, options{}))
+	write("types/socket_read.go", generate(&Type_jdk_SocketRead, options{}))
+	write("types/socket_write.go", generate(&Type_jdk_SocketWrite, options{}))
+	write("types/monitor_wait.go", generate(&Type_jdk_JavaMonitorWait, options{}))
+	write("types/thread_sleep.go", generate(&Type_jdk_ThreadSleep, options{}))
+	write("types/old_object_sample.go", generate(&Type_jdk_OldObjectSample, options{}))
+	write("types/class_load.go", generate(&Type_jdk_ClassLoad, options{}))
+	write("types/virtual_thread_pinned.go", generate(&Type_jdk_VirtualThreadPinned, options{}))
+	write("types/virtual_thread_submit_failed.go", generate(&Type_jdk_VirtualThreadSubmitFailed, options{}))
+	write("types/container_configuration.go", generate(&Type_jdk_ContainerConfiguration, options{}))
+	write("types/container_cpu_usage.go", generate(&Type_jdk_ContainerCPUUsage, options{}))
+	write("types/container_cpu_throttling.go", generate(&Type_jdk_ContainerCPUThrottling, options{}))
+	write("types/container_memory_usage.go", generate(&Type_jdk_ContainerMemoryUsage, options{}))
+	write("types/container_io_usage.go", generate(&Type_jdk_ContainerIOUsage, options{}))
 	write("types/skipper.go", generate(&def.Class{
 		Name:   "SkipConstantPool",
 		ID:     0,
@@ -118,6 +165,20 @@ func TypeForCPoolID(ID def.TypeID) *def.Class {
 		return &Type_jdk_types_ClassLoader
 	case T_STACK_FRAME:
 		return &Type_jdk_types_StackFrame
+	case T_OLD_OBJECT:
+		return &Type_jdk_types_OldObject
+	case T_REFERENCE:
+		return &Type_jdk_types_Reference
+	case T_OLD_OBJECT_ARRAY:
+		return &Type_jdk_types_OldObjectArray
+	case T_OLD_OBJECT_FIELD:
+		return &Type_jdk_types_OldObjectField
+	case T_OLD_OBJECT_GC_ROOT:
+		return &Type_jdk_types_OldObjectGcRoot
+	case T_OLD_OBJECT_ROOT_SYSTEM:
+		return &Type_jdk_types_OldObjectRootSystem
+	case T_OLD_OBJECT_ROOT_TYPE:
+		return &Type_jdk_types_OldObjectRootType
 	default:
 		panic("unknown type " + TypeID2Sym(ID))
 	}
@@ -167,7 +228,7 @@ func generate(typ *def.Class, opt options) string {
 			extraBinds += fmt.Sprintf(", bind%s *%s", name(TypeForCPoolID(binding.Type)), bindName(TypeForCPoolID(binding.Type)))
 		}
 		res += fmt.Sprintf("func (this *%sList) Parse(data []byte, bind *%s %s , typeMap *def.TypeMap) (pos int, err error) {\n", name(typ), bindName(typ), extraBinds)
-		receiver = fmt.Sprintf("this.%s[i]", name(typ))
+		receiver = fmt.Sprintf("this.%s[base+i]", name(typ))
 	} else {
 		receiver = fmt.Sprintf("this")
 
@@ -191,15 +252,24 @@ func generate(typ *def.Class, opt options) string {
 	if opt.cpool {
 		res += emitReadI32(1)
 		res += pad(1) + "n := int(v32_)\n"
+		res += emitCheckLength(1, "n", "ConstantPoolEntries", "constant pool entries")
 		if opt.doNotKeepData {
 
 		} else {
+			// the constant pools of a chunk may be split across the
+			// checkpoints of the chain, a reset list is created by the first
+			// one and extended by the others
 			if opt.sortedIDs {
-				res += pad(1) + fmt.Sprintf("this.IDMap = NewIDMap[%s](n)\n", refName(typ))
+				res += pad(1) + "if this.IDMap.Slice == nil && this.IDMap.Dict == nil {\n"
+				res += pad(2) + fmt.Sprintf("this.IDMap = NewIDMap[%s](n)\n", refName(typ))
 			} else {
-				res += pad(1) + fmt.Sprintf("this.IDMap = make(map[%s]uint32, n)\n", refName(typ))
+				res += pad(1) + "if this.IDMap == nil {\n"
+				res += pad(2) + fmt.Sprintf("this.IDMap = make(map[%s]uint32, n)\n", refName(typ))
 			}
-			res += pad(1) + fmt.Sprintf("this.%s = make([]%s, n)\n", name(typ), name(typ))
+			res += pad(2) + fmt.Sprintf("this.%s = make([]%s, 0, n)\n", name(typ), name(typ))
+			res += pad(1) + "}\n"
+			res += pad(1) + fmt.Sprintf("base := len(this.%s)\n", name(typ))
+			res += pad(1) + fmt.Sprintf("this.%s = append(this.%s, make([]%s, n)...)\n", name(typ), name(typ), name(typ))
 		}
 		res += "	for i := 0; i < n; i++ {\n"
 	} else {
@@ -222,12 +292,12 @@ func generate(typ *def.Class, opt options) string {
 
 		} else {
 
-			res += pad(depth) + fmt.Sprintf("this.%s[i] = bind.Temp\n", name(typ))
+			res += pad(depth) + fmt.Sprintf("this.%s[base+i] = bind.Temp\n", name(typ))
 
 			if opt.sortedIDs {
-				res += pad(depth) + "this.IDMap.Set(id, i)\n"
+				res += pad(depth) + "this.IDMap.Set(id, base+i)\n"
 			} else {
-				res += pad(depth) + "this.IDMap[id] = uint32(i)\n"
+				res += pad(depth) + "this.IDMap[id] = uint32(base + i)\n"
 			}
 		}
 		res += pad(1) + "}\n"
@@ -262,8 +332,10 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 	res += pad(depth) + fmt.Sprintf("	if %s.Fields[%sFieldIndex].Field.Array {\n", bindName, bindName)
 	res += emitReadI32(depth + 2)
 	res += pad(depth) + fmt.Sprintf("		%sArraySize = int(v32_)\n", bindName)
+	res += emitCheckLength(depth+2, bindName+"ArraySize", "ArrayLength", "array length")
 	if len(complexFields) > 0 {
-		res += pad(depth) + fmt.Sprintf("		if %s.Fields[%sFieldIndex].Field.Type == typeMap.%s {\n", bindName, bindName, TypeID2Sym(complexFields[0].Type))
+		res += pad(depth) + fmt.Sprintf("		if %s.Fields[%sFieldIndex].Field.Type == typeMap.%s && %s.Fields[%sFieldIndex].%s != nil {\n",
+			bindName, bindName, TypeID2Sym(complexFields[0].Type), bindName, bindName, name(TypeForCPoolID(complexFields[0].Type)))
 		res += pad(depth) + fmt.Sprintf("			*%s.Fields[%sFieldIndex].%s = make([]%s, 0, %sArraySize)\n",
 			bindName, bindName, name(TypeForCPoolID(complexFields[0].Type)), name(TypeForCPoolID(complexFields[0].Type)), bindName)
 		res += pad(depth) + fmt.Sprintf("		}\n")
@@ -272,7 +344,7 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 	res += pad(depth) + fmt.Sprintf("	for %sArrayIndex := 0; %sArrayIndex < %sArraySize; %sArrayIndex++ {\n", bindName, bindName, bindName, bindName)
 	res += pad(depth) + fmt.Sprintf("	if %s.Fields[%sFieldIndex].Field.ConstantPool {\n", bindName, bindName)
 	res += emitReadI32(depth + 2)
-	if len(cpoolFields) > 0 {
+	if len(cpoolFields) > 0 || fieldsHas(fs, T_STRING) {
 		res += pad(depth) + fmt.Sprintf("		switch %s.Fields[%sFieldIndex].Field.Type {\n", bindName, bindName)
 		for _, field := range cpoolFields {
 			res += pad(depth) + fmt.Sprintf("		case typeMap.%s:\n", TypeID2Sym(field.Type))
@@ -280,6 +352,16 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 			res += pad(depth) + fmt.Sprintf("				*%s.Fields[%sFieldIndex].%s = %s(v32_)\n", bindName, bindName, goTypeName(field), goTypeName(field))
 			res += pad(depth) + fmt.Sprintf("			}\n")
 		}
+		if fieldsHas(fs, T_STRING) {
+			res += pad(depth) + fmt.Sprintf("		case typeMap.T_STRING:\n")
+			res += pad(depth) + fmt.Sprintf("			if %s.Fields[%sFieldIndex].string != nil {\n", bindName, bindName)
+			res += pad(depth) + fmt.Sprintf("				if s, ok := typeMap.Strings[uint64(v32_)]; ok {\n")
+			res += pad(depth) + fmt.Sprintf("					*%s.Fields[%sFieldIndex].string = s\n", bindName, bindName)
+			res += pad(depth) + fmt.Sprintf("				} else {\n")
+			res += pad(depth) + fmt.Sprintf("					typeMap.UnresolvedStrings++\n")
+			res += pad(depth) + fmt.Sprintf("				}\n")
+			res += pad(depth) + fmt.Sprintf("			}\n")
+		}
 		res += pad(depth) + fmt.Sprintf("		}\n")
 	}
 	res += pad(depth) + fmt.Sprintf("	} else {\n")
@@ -325,7 +407,7 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 		res += pad(depth) + fmt.Sprintf("			// skipping\n")
 	}
 	res += pad(depth) + fmt.Sprintf("		case typeMap.T_FLOAT:\n")
-	res += emitReadI32(depth + 3)
+	res += emitReadF32(depth + 3)
 	if fieldsHas(fs, T_FLOAT) {
 		res += pad(depth) + fmt.Sprintf("			if %s.Fields[%sFieldIndex].float32 != nil {\n", bindName, bindName)
 		res += pad(depth) + fmt.Sprintf("				*%s.Fields[%sFieldIndex].float32 = *(*float32)(unsafe.Pointer(&v32_))\n", bindName, bindName)
@@ -333,6 +415,10 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 	} else {
 		res += pad(depth) + fmt.Sprintf("			// skipping\n")
 	}
+	// shorts are varints like ints, no binding stores them yet
+	res += pad(depth) + fmt.Sprintf("		case typeMap.T_SHORT:\n")
+	res += emitReadI32(depth + 3)
+	res += pad(depth) + fmt.Sprintf("			// skipping\n")
 	if nestedAllowed {
 		for _, field := range complexFields {
 			nestedType := TypeForCPoolID(field.Type)
@@ -365,10 +451,10 @@ func generateBindLoop(typ *def.Class, bindName string, depth int, nestedAllowed
 	res += emitReadI32(depth + 7)
 	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_STRING{\n", bindName)
 	res += emitString(depth + 7)
-	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_INT {\n", bindName)
+	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_INT || %sSkipFieldType == typeMap.T_SHORT {\n", bindName, bindName)
 	res += emitReadI32(depth + 7)
 	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_FLOAT {\n", bindName)
-	res += emitReadI32(depth + 7)
+	res += emitReadF32(depth + 7)
 	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_LONG {\n", bindName)
 	res += emitReadU64(depth + 7)
 	res += pad(depth) + fmt.Sprintf("					} else if %sSkipFieldType == typeMap.T_BOOLEAN {\n", bindName)
@@ -477,7 +563,12 @@ func generateBinding(typ *def.Class, opt options) string {
 		if slices.Contains(opt.skipFields, typ.Fields[i].Name) {
 			res += fmt.Sprintf("			res.Fields = append(res.Fields, %s{Field: &typ.Fields[i]}) // skip to save mem\n", bindFieldName(typ))
 		} else {
-			res += fmt.Sprintf("			if typ.Fields[i].Equals(&def.Field{Name: \"%s\", Type: typeMap.%s, ConstantPool: %v, Array: %v}) {\n", typ.Fields[i].Name, TypeID2Sym(typ.Fields[i].Type), typ.Fields[i].ConstantPool, typ.Fields[i].Array)
+			if typ.Fields[i].Type == T_STRING && !typ.Fields[i].ConstantPool {
+				// strings may be written inline or as java.lang.String constant pool references
+				res += fmt.Sprintf("			if typ.Fields[i].Equals(&def.Field{Name: \"%s\", Type: typeMap.%s, ConstantPool: typ.Fields[i].ConstantPool, Array: %v}) {\n", typ.Fields[i].Name, TypeID2Sym(typ.Fields[i].Type), typ.Fields[i].Array)
+			} else {
+				res += fmt.Sprintf("			if typ.Fields[i].Equals(&def.Field{Name: \"%s\", Type: typeMap.%s, ConstantPool: %v, Array: %v}) {\n", typ.Fields[i].Name, TypeID2Sym(typ.Fields[i].Type), typ.Fields[i].ConstantPool, typ.Fields[i].Array)
+			}
 			res += fmt.Sprintf("				res.Fields = append(res.Fields, %s{Field: &typ.Fields[i], %s: &res.Temp.%s}) \n", bindFieldName(typ), goTypeName(typ.Fields[i]), capitalize(typ.Fields[i].Name))
 			res += fmt.Sprintf("			} else {\n")
 			res += fmt.Sprintf("				res.Fields = append(res.Fields, %s{Field: &typ.Fields[i]}) // skip changed field\n", bindFieldName(typ))
@@ -502,18 +593,33 @@ func emitString(depth int) string {
 	res += pad(depth) + "pos++\n"
 	res += pad(depth) + "switch b_ {\n"
 	res += pad(depth) + "case 0:\n"
-	res += pad(depth) + "	break\n"
+	res += pad(depth) + "	s_ = def.Null\n"
 	res += pad(depth) + "case 1:\n"
 	res += pad(depth) + "	break\n"
+	res += pad(depth) + "case 2:\n"
+	res += emitReadU64(depth + 1)
+	res += pad(depth) + "	if s, ok := typeMap.Strings[v64_]; ok {\n"
+	res += pad(depth) + "		s_ = s\n"
+	res += pad(depth) + "	} else {\n"
+	res += pad(depth) + "		typeMap.UnresolvedStrings++\n"
+	res += pad(depth) + "	}\n"
 	res += pad(depth) + "case 3:\n"
 	res += emitReadI32(depth + 1)
-	res += pad(depth) + "	if pos+int(v32_) > l {\n"
-	res += pad(depth) + "		return 0, io.ErrUnexpectedEOF\n"
-	res += pad(depth) + "	}\n"
-
+	res += emitCheckLength(depth+1, "int(v32_)", "StringLength", "string length")
 	res += pad(depth) + "	bs := data[pos : pos+int(v32_)]\n"
 	res += pad(depth) + fmt.Sprintf("	s_ = *(*string)(unsafe.Pointer(&bs))\n")
 
+	res += pad(depth) + "	pos += int(v32_)\n"
+	res += pad(depth) + "case 4:\n"
+	res += emitReadI32(depth + 1)
+	res += emitCheckLength(depth+1, "int(v32_)", "StringLength", "string length")
+	res += pad(depth) + "	if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {\n"
+	res += pad(depth) + "		return 0, err\n"
+	res += pad(depth) + "	}\n"
+	res += pad(depth) + "case 5:\n"
+	res += emitReadI32(depth + 1)
+	res += emitCheckLength(depth+1, "int(v32_)", "StringLength", "string length")
+	res += pad(depth) + "	s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])\n"
 	res += pad(depth) + "	pos += int(v32_)\n"
 	res += pad(depth) + "default:\n"
 	res += pad(depth) + "	return 0, fmt.Errorf(\"unknown string type %d at %d\", b_, pos)\n"
@@ -521,6 +627,18 @@ func emitString(depth int) string {
 	return res
 }
 
+// emitCheckLength bounds a length read from the input by the remaining data,
+// every element taking at least a byte, and by the configured limit.
+func emitCheckLength(depth int, value string, limit string, what string) string {
+	res := pad(depth) + fmt.Sprintf("if %s > l-pos {\n", value)
+	res += pad(depth) + "	return 0, io.ErrUnexpectedEOF\n"
+	res += pad(depth) + "}\n"
+	res += pad(depth) + fmt.Sprintf("if typeMap.Limits.%s > 0 && %s > typeMap.Limits.%s {\n", limit, value, limit)
+	res += pad(depth) + fmt.Sprintf("	return 0, fmt.Errorf(\"%s %%d: %%w\", %s, def.ErrLimitExceeded)\n", what, value)
+	res += pad(depth) + "}\n"
+	return res
+}
+
 func emitReadByte(depth int) string {
 	code := ""
 	code += pad(depth) + "if pos >= l {\n"
@@ -552,6 +670,18 @@ func emitReadI32(depth int) string {
 	return code
 }
 
+// emitReadF32 reads the bits of a float, which unlike integers are not
+// varint encoded.
+func emitReadF32(depth int) string {
+	code := ""
+	code += pad(depth) + "if pos+4 > l {\n"
+	code += pad(depth) + "	return 0, io.ErrUnexpectedEOF\n"
+	code += pad(depth) + "}\n"
+	code += pad(depth) + "v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])\n"
+	code += pad(depth) + "pos += 4\n"
+	return code
+}
+
 func emitReadU64(depth int) string {
 	code := ""
 
@@ -601,8 +731,12 @@ func goTypeName(field def.Field) string {
 
 func name(typ *def.Class) string {
 	fs := strings.Split(typ.Name, ".")
-	s := fs[len(fs)-1]
-	return capitalize(s)
+	s := capitalize(fs[len(fs)-1])
+	if fs[0] == "datadog" {
+		// apart from the jdk events of the same name
+		return "Datadog" + s
+	}
+	return s
 }
 
 func bindName(typ *def.Class) string {
@@ -7,53 +7,86 @@ import (
 )
 
 var (
-	T_METADATA                = def.TypeID(0)
-	T_CPOOL                   = def.TypeID(1)
-	T_BOOLEAN                 = def.TypeID(4)
-	T_CHAR                    = def.TypeID(5)
-	T_FLOAT                   = def.TypeID(6)
-	T_DOUBLE                  = def.TypeID(7)
-	T_BYTE                    = def.TypeID(8)
-	T_SHORT                   = def.TypeID(9)
-	T_INT                     = def.TypeID(10)
-	T_LONG                    = def.TypeID(11)
-	T_STRING                  = def.TypeID(20)
-	T_CLASS                   = def.TypeID(21)
-	T_THREAD                  = def.TypeID(22)
-	T_CLASS_LOADER            = def.TypeID(23)
-	T_FRAME_TYPE              = def.TypeID(24)
-	T_THREAD_STATE            = def.TypeID(25)
-	T_STACK_TRACE             = def.TypeID(26)
-	T_STACK_FRAME             = def.TypeID(27)
-	T_METHOD                  = def.TypeID(28)
-	T_PACKAGE                 = def.TypeID(29)
-	T_SYMBOL                  = def.TypeID(30)
-	T_LOG_LEVEL               = def.TypeID(31)
-	T_EVENT                   = def.TypeID(100)
-	T_EXECUTION_SAMPLE        = def.TypeID(101)
-	T_ALLOC_IN_NEW_TLAB       = def.TypeID(102)
-	T_ALLOC_OUTSIDE_TLAB      = def.TypeID(103)
-	T_MONITOR_ENTER           = def.TypeID(104)
-	T_THREAD_PARK             = def.TypeID(105)
-	T_CPU_LOAD                = def.TypeID(106)
-	T_ACTIVE_RECORDING        = def.TypeID(107)
-	T_ACTIVE_SETTING          = def.TypeID(108)
-	T_OS_INFORMATION          = def.TypeID(109)
-	T_CPU_INFORMATION         = def.TypeID(110)
-	T_JVM_INFORMATION         = def.TypeID(111)
-	T_INITIAL_SYSTEM_PROPERTY = def.TypeID(112)
-	T_NATIVE_LIBRARY          = def.TypeID(113)
-	T_LOG                     = def.TypeID(114)
-	T_LIVE_OBJECT             = def.TypeID(115)
-	T_ANNOTATION              = def.TypeID(200)
-	T_LABEL                   = def.TypeID(201)
-	T_CATEGORY                = def.TypeID(202)
-	T_TIMESTAMP               = def.TypeID(203)
-	T_TIMESPAN                = def.TypeID(204)
-	T_DATA_AMOUNT             = def.TypeID(205)
-	T_MEMORY_ADDRESS          = def.TypeID(206)
-	T_UNSIGNED                = def.TypeID(207)
-	T_PERCENTAGE              = def.TypeID(208)
+	T_METADATA                     = def.TypeID(0)
+	T_CPOOL                        = def.TypeID(1)
+	T_BOOLEAN                      = def.TypeID(4)
+	T_CHAR                         = def.TypeID(5)
+	T_FLOAT                        = def.TypeID(6)
+	T_DOUBLE                       = def.TypeID(7)
+	T_BYTE                         = def.TypeID(8)
+	T_SHORT                        = def.TypeID(9)
+	T_INT                          = def.TypeID(10)
+	T_LONG                         = def.TypeID(11)
+	T_STRING                       = def.TypeID(20)
+	T_CLASS                        = def.TypeID(21)
+	T_THREAD                       = def.TypeID(22)
+	T_CLASS_LOADER                 = def.TypeID(23)
+	T_FRAME_TYPE                   = def.TypeID(24)
+	T_THREAD_STATE                 = def.TypeID(25)
+	T_STACK_TRACE                  = def.TypeID(26)
+	T_STACK_FRAME                  = def.TypeID(27)
+	T_METHOD                       = def.TypeID(28)
+	T_PACKAGE                      = def.TypeID(29)
+	T_SYMBOL                       = def.TypeID(30)
+	T_LOG_LEVEL                    = def.TypeID(31)
+	T_OLD_OBJECT                   = def.TypeID(32)
+	T_REFERENCE                    = def.TypeID(33)
+	T_OLD_OBJECT_ARRAY             = def.TypeID(34)
+	T_OLD_OBJECT_FIELD             = def.TypeID(35)
+	T_OLD_OBJECT_GC_ROOT           = def.TypeID(36)
+	T_OLD_OBJECT_ROOT_SYSTEM       = def.TypeID(37)
+	T_OLD_OBJECT_ROOT_TYPE         = def.TypeID(38)
+	T_EVENT                        = def.TypeID(100)
+	T_EXECUTION_SAMPLE             = def.TypeID(101)
+	T_ALLOC_IN_NEW_TLAB            = def.TypeID(102)
+	T_ALLOC_OUTSIDE_TLAB           = def.TypeID(103)
+	T_MONITOR_ENTER                = def.TypeID(104)
+	T_THREAD_PARK                  = def.TypeID(105)
+	T_CPU_LOAD                     = def.TypeID(106)
+	T_ACTIVE_RECORDING             = def.TypeID(107)
+	T_ACTIVE_SETTING               = def.TypeID(108)
+	T_OS_INFORMATION               = def.TypeID(109)
+	T_CPU_INFORMATION              = def.TypeID(110)
+	T_JVM_INFORMATION              = def.TypeID(111)
+	T_INITIAL_SYSTEM_PROPERTY      = def.TypeID(112)
+	T_NATIVE_LIBRARY               = def.TypeID(113)
+	T_LOG                          = def.TypeID(114)
+	T_LIVE_OBJECT                  = def.TypeID(115)
+	T_DD_EXECUTION_SAMPLE          = def.TypeID(116)
+	T_DD_METHOD_SAMPLE             = def.TypeID(117)
+	T_DD_OBJECT_SAMPLE             = def.TypeID(118)
+	T_DD_HEAP_LIVE_OBJECT          = def.TypeID(119)
+	T_DD_EXCEPTION_SAMPLE          = def.TypeID(120)
+	T_DD_ENDPOINT                  = def.TypeID(121)
+	T_EXCEPTION_THROW              = def.TypeID(122)
+	T_ERROR_THROW                  = def.TypeID(123)
+	T_EXCEPTION_STATISTICS         = def.TypeID(124)
+	T_FILE_READ                    = def.TypeID(125)
+	T_FILE_WRITE                   = def.TypeID(126)
+	T_SOCKET_READ                  = def.TypeID(127)
+	T_SOCKET_WRITE                 = def.TypeID(128)
+	T_MONITOR_WAIT                 = def.TypeID(129)
+	T_THREAD_SLEEP                 = def.TypeID(130)
+	T_CLASS_LOAD                   = def.TypeID(131)
+	T_OLD_OBJECT_SAMPLE            = def.TypeID(132)
+	T_VIRTUAL_THREAD_PINNED        = def.TypeID(133)
+	T_VIRTUAL_THREAD_SUBMIT_FAILED = def.TypeID(134)
+	T_CONTAINER_CONFIGURATION      = def.TypeID(135)
+	T_CONTAINER_CPU_USAGE          = def.TypeID(136)
+	T_CONTAINER_CPU_THROTTLING     = def.TypeID(137)
+	T_CONTAINER_MEMORY_USAGE       = def.TypeID(138)
+	T_CONTAINER_IO_USAGE           = def.TypeID(139)
+	T_MALLOC                       = def.TypeID(140)
+	T_FREE                         = def.TypeID(141)
+	T_ANNOTATION                   = def.TypeID(200)
+	T_LABEL                        = def.TypeID(201)
+	T_CATEGORY                     = def.TypeID(202)
+	T_TIMESTAMP                    = def.TypeID(203)
+	T_TIMESPAN                     = def.TypeID(204)
+	T_DATA_AMOUNT                  = def.TypeID(205)
+	T_MEMORY_ADDRESS               = def.TypeID(206)
+	T_UNSIGNED                     = def.TypeID(207)
+	T_PERCENTAGE                   = def.TypeID(208)
 )
 
 func TypeID2Sym(id def.TypeID) string {
@@ -102,6 +135,20 @@ func TypeID2Sym(id def.TypeID) string {
 		return "T_SYMBOL"
 	case T_LOG_LEVEL:
 		return "T_LOG_LEVEL"
+	case T_OLD_OBJECT:
+		return "T_OLD_OBJECT"
+	case T_REFERENCE:
+		return "T_REFERENCE"
+	case T_OLD_OBJECT_ARRAY:
+		return "T_OLD_OBJECT_ARRAY"
+	case T_OLD_OBJECT_FIELD:
+		return "T_OLD_OBJECT_FIELD"
+	case T_OLD_OBJECT_GC_ROOT:
+		return "T_OLD_OBJECT_GC_ROOT"
+	case T_OLD_OBJECT_ROOT_SYSTEM:
+		return "T_OLD_OBJECT_ROOT_SYSTEM"
+	case T_OLD_OBJECT_ROOT_TYPE:
+		return "T_OLD_OBJECT_ROOT_TYPE"
 	case T_EVENT:
 		return "T_EVENT"
 	case T_EXECUTION_SAMPLE:
@@ -134,6 +181,58 @@ func TypeID2Sym(id def.TypeID) string {
 		return "T_LOG"
 	case T_LIVE_OBJECT:
 		return "T_LIVE_OBJECT"
+	case T_DD_EXECUTION_SAMPLE:
+		return "T_DD_EXECUTION_SAMPLE"
+	case T_DD_METHOD_SAMPLE:
+		return "T_DD_METHOD_SAMPLE"
+	case T_DD_OBJECT_SAMPLE:
+		return "T_DD_OBJECT_SAMPLE"
+	case T_DD_HEAP_LIVE_OBJECT:
+		return "T_DD_HEAP_LIVE_OBJECT"
+	case T_DD_EXCEPTION_SAMPLE:
+		return "T_DD_EXCEPTION_SAMPLE"
+	case T_DD_ENDPOINT:
+		return "T_DD_ENDPOINT"
+	case T_EXCEPTION_THROW:
+		return "T_EXCEPTION_THROW"
+	case T_ERROR_THROW:
+		return "T_ERROR_THROW"
+	case T_EXCEPTION_STATISTICS:
+		return "T_EXCEPTION_STATISTICS"
+	case T_FILE_READ:
+		return "T_FILE_READ"
+	case T_FILE_WRITE:
+		return "T_FILE_WRITE"
+	case T_SOCKET_READ:
+		return "T_SOCKET_READ"
+	case T_SOCKET_WRITE:
+		return "T_SOCKET_WRITE"
+	case T_MONITOR_WAIT:
+		return "T_MONITOR_WAIT"
+	case T_THREAD_SLEEP:
+		return "T_THREAD_SLEEP"
+	case T_CLASS_LOAD:
+		return "T_CLASS_LOAD"
+	case T_OLD_OBJECT_SAMPLE:
+		return "T_OLD_OBJECT_SAMPLE"
+	case T_VIRTUAL_THREAD_PINNED:
+		return "T_VIRTUAL_THREAD_PINNED"
+	case T_VIRTUAL_THREAD_SUBMIT_FAILED:
+		return "T_VIRTUAL_THREAD_SUBMIT_FAILED"
+	case T_CONTAINER_CONFIGURATION:
+		return "T_CONTAINER_CONFIGURATION"
+	case T_CONTAINER_CPU_USAGE:
+		return "T_CONTAINER_CPU_USAGE"
+	case T_CONTAINER_CPU_THROTTLING:
+		return "T_CONTAINER_CPU_THROTTLING"
+	case T_CONTAINER_MEMORY_USAGE:
+		return "T_CONTAINER_MEMORY_USAGE"
+	case T_CONTAINER_IO_USAGE:
+		return "T_CONTAINER_IO_USAGE"
+	case T_MALLOC:
+		return "T_MALLOC"
+	case T_FREE:
+		return "T_FREE"
 	case T_ANNOTATION:
 		return "T_ANNOTATION"
 	case T_LABEL:
@@ -220,6 +319,7 @@ var Type_java_lang_Thread = def.Class{
 		{Name: "osThreadId", Type: T_LONG, ConstantPool: false},
 		{Name: "javaName", Type: T_STRING, ConstantPool: false},
 		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+		{Name: "virtual", Type: T_BOOLEAN, ConstantPool: false},
 	},
 }
 var Type_jdk_types_ClassLoader = def.Class{
@@ -472,6 +572,395 @@ var Type_profiler_LiveObject = def.Class{
 		{Name: "allocationTime", Type: T_LONG, ConstantPool: false},
 	},
 }
+var Type_profiler_Malloc = def.Class{
+	Name: "profiler.Malloc",
+	ID:   T_MALLOC,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "address", Type: T_LONG, ConstantPool: false},
+		{Name: "size", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_profiler_Free = def.Class{
+	Name: "profiler.Free",
+	ID:   T_FREE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "address", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_ExecutionSample = def.Class{
+	Name: "datadog.ExecutionSample",
+	ID:   T_DD_EXECUTION_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "state", Type: T_THREAD_STATE, ConstantPool: true},
+		{Name: "spanId", Type: T_LONG, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+		{Name: "weight", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_MethodSample = def.Class{
+	Name: "datadog.MethodSample",
+	ID:   T_DD_METHOD_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "state", Type: T_THREAD_STATE, ConstantPool: true},
+		{Name: "spanId", Type: T_LONG, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+		{Name: "weight", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_ObjectSample = def.Class{
+	Name: "datadog.ObjectSample",
+	ID:   T_DD_OBJECT_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "objectClass", Type: T_CLASS, ConstantPool: true},
+		{Name: "size", Type: T_LONG, ConstantPool: false},
+		{Name: "weight", Type: T_FLOAT, ConstantPool: false},
+		{Name: "spanId", Type: T_LONG, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_HeapLiveObject = def.Class{
+	Name: "datadog.HeapLiveObject",
+	ID:   T_DD_HEAP_LIVE_OBJECT,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "objectClass", Type: T_CLASS, ConstantPool: true},
+		{Name: "age", Type: T_LONG, ConstantPool: false},
+		{Name: "size", Type: T_LONG, ConstantPool: false},
+		{Name: "weight", Type: T_FLOAT, ConstantPool: false},
+		{Name: "spanId", Type: T_LONG, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_ExceptionSample = def.Class{
+	Name: "datadog.ExceptionSample",
+	ID:   T_DD_EXCEPTION_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "type", Type: T_STRING, ConstantPool: false},
+		{Name: "message", Type: T_STRING, ConstantPool: false},
+		{Name: "stackDepth", Type: T_INT, ConstantPool: false},
+		{Name: "sampled", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "firstOccurrence", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+		{Name: "spanId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_datadog_Endpoint = def.Class{
+	Name: "datadog.Endpoint",
+	ID:   T_DD_ENDPOINT,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "endpoint", Type: T_STRING, ConstantPool: false},
+		{Name: "localRootSpanId", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_JavaExceptionThrow = def.Class{
+	Name: "jdk.JavaExceptionThrow",
+	ID:   T_EXCEPTION_THROW,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "message", Type: T_STRING, ConstantPool: false},
+		{Name: "thrownClass", Type: T_CLASS, ConstantPool: true},
+	},
+}
+var Type_jdk_JavaErrorThrow = def.Class{
+	Name: "jdk.JavaErrorThrow",
+	ID:   T_ERROR_THROW,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "message", Type: T_STRING, ConstantPool: false},
+		{Name: "thrownClass", Type: T_CLASS, ConstantPool: true},
+	},
+}
+var Type_jdk_ExceptionStatistics = def.Class{
+	Name: "jdk.ExceptionStatistics",
+	ID:   T_EXCEPTION_STATISTICS,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "throwables", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_FileRead = def.Class{
+	Name: "jdk.FileRead",
+	ID:   T_FILE_READ,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "path", Type: T_STRING, ConstantPool: false},
+		{Name: "bytesRead", Type: T_LONG, ConstantPool: false},
+		{Name: "endOfFile", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+var Type_jdk_FileWrite = def.Class{
+	Name: "jdk.FileWrite",
+	ID:   T_FILE_WRITE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "path", Type: T_STRING, ConstantPool: false},
+		{Name: "bytesWritten", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_SocketRead = def.Class{
+	Name: "jdk.SocketRead",
+	ID:   T_SOCKET_READ,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "host", Type: T_STRING, ConstantPool: false},
+		{Name: "address", Type: T_STRING, ConstantPool: false},
+		{Name: "port", Type: T_INT, ConstantPool: false},
+		{Name: "timeout", Type: T_LONG, ConstantPool: false},
+		{Name: "bytesRead", Type: T_LONG, ConstantPool: false},
+		{Name: "endOfStream", Type: T_BOOLEAN, ConstantPool: false},
+	},
+}
+var Type_jdk_SocketWrite = def.Class{
+	Name: "jdk.SocketWrite",
+	ID:   T_SOCKET_WRITE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "host", Type: T_STRING, ConstantPool: false},
+		{Name: "address", Type: T_STRING, ConstantPool: false},
+		{Name: "port", Type: T_INT, ConstantPool: false},
+		{Name: "bytesWritten", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_JavaMonitorWait = def.Class{
+	Name: "jdk.JavaMonitorWait",
+	ID:   T_MONITOR_WAIT,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "monitorClass", Type: T_CLASS, ConstantPool: true},
+		{Name: "notifier", Type: T_THREAD, ConstantPool: true},
+		{Name: "timeout", Type: T_LONG, ConstantPool: false},
+		{Name: "timedOut", Type: T_BOOLEAN, ConstantPool: false},
+		{Name: "address", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ThreadSleep = def.Class{
+	Name: "jdk.ThreadSleep",
+	ID:   T_THREAD_SLEEP,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "time", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ClassLoad = def.Class{
+	Name: "jdk.ClassLoad",
+	ID:   T_CLASS_LOAD,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "loadedClass", Type: T_CLASS, ConstantPool: true},
+		{Name: "definingClassLoader", Type: T_CLASS_LOADER, ConstantPool: true},
+		{Name: "initiatingClassLoader", Type: T_CLASS_LOADER, ConstantPool: true},
+	},
+}
+var Type_jdk_OldObjectSample = def.Class{
+	Name: "jdk.OldObjectSample",
+	ID:   T_OLD_OBJECT_SAMPLE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "allocationTime", Type: T_LONG, ConstantPool: false},
+		{Name: "objectSize", Type: T_LONG, ConstantPool: false},
+		{Name: "objectAge", Type: T_LONG, ConstantPool: false},
+		{Name: "lastKnownHeapUsage", Type: T_LONG, ConstantPool: false},
+		{Name: "object", Type: T_OLD_OBJECT, ConstantPool: true},
+		{Name: "arrayElements", Type: T_INT, ConstantPool: false},
+		{Name: "root", Type: T_OLD_OBJECT_GC_ROOT, ConstantPool: true},
+	},
+}
+var Type_jdk_types_OldObject = def.Class{
+	Name: "jdk.types.OldObject",
+	ID:   T_OLD_OBJECT,
+	Fields: []def.Field{
+		{Name: "address", Type: T_LONG, ConstantPool: false},
+		{Name: "type", Type: T_CLASS, ConstantPool: true},
+		{Name: "description", Type: T_STRING, ConstantPool: false},
+		{Name: "referrer", Type: T_REFERENCE, ConstantPool: true},
+	},
+}
+var Type_jdk_types_Reference = def.Class{
+	Name: "jdk.types.Reference",
+	ID:   T_REFERENCE,
+	Fields: []def.Field{
+		{Name: "array", Type: T_OLD_OBJECT_ARRAY, ConstantPool: true},
+		{Name: "field", Type: T_OLD_OBJECT_FIELD, ConstantPool: true},
+		{Name: "object", Type: T_OLD_OBJECT, ConstantPool: true},
+		{Name: "skip", Type: T_INT, ConstantPool: false},
+	},
+}
+var Type_jdk_types_OldObjectArray = def.Class{
+	Name: "jdk.types.OldObjectArray",
+	ID:   T_OLD_OBJECT_ARRAY,
+	Fields: []def.Field{
+		{Name: "size", Type: T_INT, ConstantPool: false},
+		{Name: "index", Type: T_INT, ConstantPool: false},
+	},
+}
+var Type_jdk_types_OldObjectField = def.Class{
+	Name: "jdk.types.OldObjectField",
+	ID:   T_OLD_OBJECT_FIELD,
+	Fields: []def.Field{
+		{Name: "name", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_OldObjectGcRoot = def.Class{
+	Name: "jdk.types.OldObjectGcRoot",
+	ID:   T_OLD_OBJECT_GC_ROOT,
+	Fields: []def.Field{
+		{Name: "description", Type: T_STRING, ConstantPool: false},
+		{Name: "system", Type: T_OLD_OBJECT_ROOT_SYSTEM, ConstantPool: true},
+		{Name: "type", Type: T_OLD_OBJECT_ROOT_TYPE, ConstantPool: true},
+	},
+}
+var Type_jdk_types_OldObjectRootSystem = def.Class{
+	Name: "jdk.types.OldObjectRootSystem",
+	ID:   T_OLD_OBJECT_ROOT_SYSTEM,
+	Fields: []def.Field{
+		{Name: "system", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_types_OldObjectRootType = def.Class{
+	Name: "jdk.types.OldObjectRootType",
+	ID:   T_OLD_OBJECT_ROOT_TYPE,
+	Fields: []def.Field{
+		{Name: "type", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_VirtualThreadPinned = def.Class{
+	Name: "jdk.VirtualThreadPinned",
+	ID:   T_VIRTUAL_THREAD_PINNED,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		// JDK 24 and later
+		{Name: "blockingOperation", Type: T_STRING, ConstantPool: false},
+		{Name: "pinnedReason", Type: T_STRING, ConstantPool: false},
+		{Name: "carrierThread", Type: T_THREAD, ConstantPool: true},
+	},
+}
+var Type_jdk_VirtualThreadSubmitFailed = def.Class{
+	Name: "jdk.VirtualThreadSubmitFailed",
+	ID:   T_VIRTUAL_THREAD_SUBMIT_FAILED,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "duration", Type: T_LONG, ConstantPool: false},
+		{Name: "eventThread", Type: T_THREAD, ConstantPool: true},
+		{Name: "stackTrace", Type: T_STACK_TRACE, ConstantPool: true},
+		{Name: "javaThreadId", Type: T_LONG, ConstantPool: false},
+		{Name: "exceptionMessage", Type: T_STRING, ConstantPool: false},
+	},
+}
+var Type_jdk_ContainerConfiguration = def.Class{
+	Name: "jdk.ContainerConfiguration",
+	ID:   T_CONTAINER_CONFIGURATION,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "containerType", Type: T_STRING, ConstantPool: false},
+		{Name: "cpuSlicePeriod", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuQuota", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuShares", Type: T_LONG, ConstantPool: false},
+		{Name: "effectiveCpuCount", Type: T_LONG, ConstantPool: false},
+		{Name: "memorySoftLimit", Type: T_LONG, ConstantPool: false},
+		{Name: "memoryLimit", Type: T_LONG, ConstantPool: false},
+		{Name: "swapMemoryLimit", Type: T_LONG, ConstantPool: false},
+		{Name: "hostTotalMemory", Type: T_LONG, ConstantPool: false}, // JDK 21 and later
+	},
+}
+var Type_jdk_ContainerCPUUsage = def.Class{
+	Name: "jdk.ContainerCPUUsage",
+	ID:   T_CONTAINER_CPU_USAGE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuTime", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuUserTime", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuSystemTime", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ContainerCPUThrottling = def.Class{
+	Name: "jdk.ContainerCPUThrottling",
+	ID:   T_CONTAINER_CPU_THROTTLING,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuElapsedSlices", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuThrottledSlices", Type: T_LONG, ConstantPool: false},
+		{Name: "cpuThrottledTime", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ContainerMemoryUsage = def.Class{
+	Name: "jdk.ContainerMemoryUsage",
+	ID:   T_CONTAINER_MEMORY_USAGE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "memoryFailCount", Type: T_LONG, ConstantPool: false},
+		{Name: "memoryUsage", Type: T_LONG, ConstantPool: false},
+		{Name: "swapMemoryUsage", Type: T_LONG, ConstantPool: false},
+	},
+}
+var Type_jdk_ContainerIOUsage = def.Class{
+	Name: "jdk.ContainerIOUsage",
+	ID:   T_CONTAINER_IO_USAGE,
+	Fields: []def.Field{
+		{Name: "startTime", Type: T_LONG, ConstantPool: false},
+		{Name: "serviceRequests", Type: T_LONG, ConstantPool: false},
+		{Name: "dataTransferred", Type: T_LONG, ConstantPool: false},
+	},
+}
 var Type_jdk_jfr_Label = def.Class{
 	Name: "jdk.jfr.Label",
 	ID:   T_LABEL,
@@ -1,10 +1,11 @@
 module github.com/grafana/jfr-parser
 
-go 1.21
+go 1.22
 
 require (
 	github.com/GuanceCloud/zipstream v0.1.0
 	github.com/grafana/jfr-parser/pprof v0.0.0-20240428042017-f984a370a654
+	github.com/klauspost/compress v1.18.0
 	github.com/pierrec/lz4/v4 v4.1.18
 	github.com/stretchr/testify v1.9.0
 )
@@ -0,0 +1,290 @@
+// Package jfrtest writes single chunk recordings for the tests of events the
+// testdata recordings do not contain.
+package jfrtest
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// Type IDs of the classes every recording declares.
+const (
+	Boolean     = 4
+	Float       = 6
+	Int         = 10
+	Long        = 11
+	String      = 20
+	Class       = 21
+	Thread      = 22
+	ClassLoader = 23
+	FrameType   = 24
+	ThreadState = 25
+	StackTrace  = 26
+	StackFrame  = 27
+	Method      = 28
+	Package     = 29
+	Symbol      = 30
+	Timestamp   = 203
+	Timespan    = 204
+	DataAmount  = 205
+	Percentage  = 206
+	Frequency   = 207
+)
+
+const (
+	// StartNanos is the start of the chunk, in nanoseconds since epoch.
+	StartNanos = 1_700_000_000_000_000_000
+	// StartTicks is the tick count at StartNanos, ticks are nanoseconds.
+	StartTicks = 1000
+)
+
+type Recording struct {
+	classes []class
+	pools   map[int][][]byte
+	order   []int
+	events  []byte
+}
+
+type class struct {
+	id        int
+	name      string
+	superType string
+	fields    []Field
+}
+
+type Field struct {
+	Name  string
+	Class int
+	CPool bool
+	Array bool
+	// Annotation is an annotation class of the field, such as Timespan, and
+	// Value the value of the annotation, such as TICKS.
+	Annotation int
+	Value      string
+}
+
+func New() *Recording {
+	r := &Recording{pools: make(map[int][][]byte)}
+	r.Class(Boolean, "boolean")
+	r.Class(Float, "float")
+	r.Class(Int, "int")
+	r.Class(Long, "long")
+	r.Class(String, "java.lang.String")
+	r.Class(Class, "java.lang.Class",
+		Field{Name: "name", Class: Symbol, CPool: true})
+	r.Class(Thread, "java.lang.Thread",
+		Field{Name: "osName", Class: String},
+		Field{Name: "osThreadId", Class: Long},
+		Field{Name: "javaName", Class: String},
+		Field{Name: "javaThreadId", Class: Long},
+		Field{Name: "virtual", Class: Boolean})
+	r.Class(ClassLoader, "jdk.types.ClassLoader",
+		Field{Name: "name", Class: Symbol, CPool: true})
+	r.Class(FrameType, "jdk.types.FrameType",
+		Field{Name: "description", Class: String})
+	r.Class(ThreadState, "jdk.types.ThreadState",
+		Field{Name: "name", Class: String})
+	r.Class(StackTrace, "jdk.types.StackTrace",
+		Field{Name: "truncated", Class: Boolean},
+		Field{Name: "frames", Class: StackFrame, Array: true})
+	r.Class(StackFrame, "jdk.types.StackFrame",
+		Field{Name: "method", Class: Method, CPool: true},
+		Field{Name: "lineNumber", Class: Int},
+		Field{Name: "bytecodeIndex", Class: Int},
+		Field{Name: "type", Class: FrameType, CPool: true})
+	r.Class(Method, "jdk.types.Method",
+		Field{Name: "type", Class: Class, CPool: true},
+		Field{Name: "name", Class: Symbol, CPool: true})
+	r.Class(Package, "jdk.types.Package",
+		Field{Name: "name", Class: Symbol, CPool: true})
+	r.Class(Symbol, "jdk.types.Symbol",
+		Field{Name: "string", Class: String})
+	for _, a := range []class{
+		{id: Timestamp, name: "jdk.jfr.Timestamp"},
+		{id: Timespan, name: "jdk.jfr.Timespan"},
+		{id: DataAmount, name: "jdk.jfr.DataAmount"},
+		{id: Percentage, name: "jdk.jfr.Percentage"},
+		{id: Frequency, name: "jdk.jfr.Frequency"},
+	} {
+		a.superType = "java.lang.annotation.Annotation"
+		a.fields = []Field{{Name: "value", Class: String}}
+		r.classes = append(r.classes, a)
+	}
+	return r
+}
+
+// Class declares a type.
+func (r *Recording) Class(id int, name string, fields ...Field) {
+	r.classes = append(r.classes, class{id: id, name: name, fields: fields})
+}
+
+// EventClass declares an event type, a subtype of jdk.jfr.Event. Its
+// startTime field is annotated as a timestamp in ticks.
+func (r *Recording) EventClass(id int, name string, fields ...Field) {
+	r.classes = append(r.classes, class{id: id, name: name, superType: "jdk.jfr.Event", fields: fields})
+}
+
+// Constant adds an entry to the constant pool of a class.
+func (r *Recording) Constant(class int, id uint64, values ...any) {
+	if _, ok := r.pools[class]; !ok {
+		r.order = append(r.order, class)
+	}
+	r.pools[class] = append(r.pools[class], appendValues(appendVarint(nil, id), values...))
+}
+
+// ClassConstant adds a class and the symbol of its name to the constant
+// pools, both with the given ID.
+func (r *Recording) ClassConstant(id uint64, name string) {
+	r.Constant(Symbol, id, name)
+	r.Constant(Class, id, id)
+}
+
+// StackTrace adds a stack trace of methods, the top frame first.
+func (r *Recording) StackTrace(id uint64, methods ...uint64) {
+	frames := []any{false, len(methods)}
+	for i, m := range methods {
+		frames = append(frames, m, int32(10*(i+1)), int32(0), uint64(0))
+	}
+	r.Constant(StackTrace, id, frames...)
+}
+
+// PooledString is a string written as a reference to the java.lang.String
+// constant pool, such as r.Constant(String, id, "/tmp").
+type PooledString uint64
+
+// Event adds an event, the values are written as varints, booleans, 4 bytes
+// floats, UTF-8 strings and pooled strings.
+func (r *Recording) Event(class int, values ...any) {
+	r.events = appendEvent(r.events, appendValues(appendVarint(nil, uint64(class)), values...))
+}
+
+func (r *Recording) Bytes() []byte {
+	const headerSize = 68
+	buf := make([]byte, headerSize)
+	copy(buf, "FLR\x00")
+	binary.BigEndian.PutUint32(buf[4:], 0x20000)
+	binary.BigEndian.PutUint64(buf[32:], StartNanos)
+	binary.BigEndian.PutUint64(buf[40:], 10_000_000_000) // duration nanos
+	binary.BigEndian.PutUint64(buf[48:], StartTicks)
+	binary.BigEndian.PutUint64(buf[56:], 1_000_000_000) // ticks per second
+	binary.BigEndian.PutUint32(buf[64:], 1)             // compressed integers
+
+	buf = append(buf, r.events...)
+
+	cpool := appendValues(nil, 1, 0, 0, 0, 1, len(r.order))
+	for _, class := range r.order {
+		cpool = appendValues(cpool, class, len(r.pools[class]))
+		for _, entry := range r.pools[class] {
+			cpool = append(cpool, entry...)
+		}
+	}
+	binary.BigEndian.PutUint64(buf[16:], uint64(len(buf)))
+	buf = appendEvent(buf, cpool)
+
+	binary.BigEndian.PutUint64(buf[24:], uint64(len(buf)))
+	buf = appendEvent(buf, r.metadata())
+
+	binary.BigEndian.PutUint64(buf[8:], uint64(len(buf)))
+	return buf
+}
+
+func (r *Recording) metadata() []byte {
+	var strs []string
+	index := map[string]int{}
+	str := func(s string) int {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = len(strs)
+		strs = append(strs, s)
+		return index[s]
+	}
+	element := func(dst []byte, name string, children int, attrs ...string) []byte {
+		dst = appendValues(dst, str(name), len(attrs)/2)
+		for _, a := range attrs {
+			dst = appendVarint(dst, uint64(str(a)))
+		}
+		return appendVarint(dst, uint64(children))
+	}
+
+	var tree []byte
+	tree = element(tree, "root", 1)
+	tree = element(tree, "metadata", len(r.classes))
+	for _, c := range r.classes {
+		attrs := []string{"id", strconv.Itoa(c.id), "name", c.name}
+		if c.superType != "" {
+			attrs = append(attrs, "superType", c.superType)
+		}
+		tree = element(tree, "class", len(c.fields), attrs...)
+		for _, f := range c.fields {
+			attrs := []string{"name", f.Name, "class", strconv.Itoa(f.Class)}
+			if f.CPool {
+				attrs = append(attrs, "constantPool", "true")
+			}
+			if f.Array {
+				attrs = append(attrs, "dimension", "1")
+			}
+			if c.superType == "jdk.jfr.Event" && f.Name == "startTime" {
+				f.Annotation, f.Value = Timestamp, "TICKS"
+			}
+			if f.Annotation != 0 {
+				tree = element(tree, "field", 1, attrs...)
+				tree = element(tree, "annotation", 0, "class", strconv.Itoa(f.Annotation), "value", f.Value)
+				continue
+			}
+			tree = element(tree, "field", 0, attrs...)
+		}
+	}
+
+	res := appendValues(nil, 0, 0, 0, 0, len(strs))
+	for _, s := range strs {
+		res = appendValues(res, s)
+	}
+	return append(res, tree...)
+}
+
+func appendEvent(dst, body []byte) []byte {
+	size := len(body) + 1
+	for len(appendVarint(nil, uint64(size))) != size-len(body) {
+		size++
+	}
+	return append(appendVarint(dst, uint64(size)), body...)
+}
+
+func appendValues(dst []byte, values ...any) []byte {
+	for _, v := range values {
+		switch v := v.(type) {
+		case int:
+			dst = appendVarint(dst, uint64(v))
+		case int32:
+			dst = appendVarint(dst, uint64(uint32(v)))
+		case uint64:
+			dst = appendVarint(dst, v)
+		case bool:
+			if v {
+				dst = append(dst, 1)
+			} else {
+				dst = append(dst, 0)
+			}
+		case float32:
+			dst = binary.BigEndian.AppendUint32(dst, math.Float32bits(v))
+		case string:
+			dst = append(appendValues(append(dst, 3), len(v)), v...)
+		case PooledString:
+			dst = appendVarint(append(dst, 2), uint64(v))
+		default:
+			panic("unsupported value")
+		}
+	}
+	return dst
+}
+
+// appendVarint writes v in at most 9 bytes, the last one holds 8 bits.
+func appendVarint(dst []byte, v uint64) []byte {
+	for i := 0; i < 8 && v >= 0x80; i++ {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
@@ -0,0 +1,106 @@
+// Package report holds the helpers shared by the reports of the common
+// packages.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/jfr-parser/common/attributes"
+	"github.com/grafana/jfr-parser/parser"
+	types2 "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+// ParseEvents calls add with the type of each of the remaining events of the
+// parser, the event itself is in the field of the parser for its type.
+func ParseEvents(p *parser.Parser, add func(typ def.TypeID)) error {
+	for {
+		typ, err := p.ParseEvent()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("jfr parser ParseEvent error: %w", err)
+		}
+		add(typ)
+	}
+}
+
+// Percentile is the nearest rank percentile of sorted durations.
+func Percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// StartTime is the start time of an event read by parser.Parse.
+func StartTime(event *parser.GenericEvent) (time.Time, error) {
+	startTime, err := attributes.StartTime.GetValue(event)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, startTime.IntValue()), nil
+}
+
+// JavaName converts a class name in the internal form of the JVM,
+// java/lang/Object, to its Java name.
+func JavaName(class string) string {
+	return strings.ReplaceAll(class, "/", ".")
+}
+
+// MethodName is the Java name of the class of a method read by parser.Parse,
+// followed by the method name.
+func MethodName(m *parser.Method) string {
+	if m == nil || m.Name == nil {
+		return ""
+	}
+	if m.Type == nil || m.Type.Name == nil {
+		return m.Name.String
+	}
+	return JavaName(m.Type.Name.String) + "." + m.Name.String
+}
+
+// ClassName is the name of a class of the constant pools of the parser, in
+// the internal form of the JVM.
+func ClassName(p *parser.Parser, ref types2.ClassRef) string {
+	cls := p.GetClass(ref)
+	if cls == nil {
+		return ""
+	}
+	return p.GetSymbolString(cls.Name)
+}
+
+// ThreadName is the Java name of a thread of the constant pools of the
+// parser, or its OS name or ID for the threads the JVM does not know.
+func ThreadName(p *parser.Parser, ref types2.ThreadRef) string {
+	t := p.GetThread(ref)
+	if t == nil {
+		return ""
+	}
+	if t.JavaName != "" {
+		return t.JavaName
+	}
+	if t.OsName != "" {
+		return t.OsName
+	}
+	return "tid " + strconv.FormatUint(t.OsThreadId, 10)
+}
+
+// FormatBytes formats a size in MiB for the text reports.
+func FormatBytes(n int64) string {
+	return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+}
+
+// OrDash is s, or a dash for an empty column of the text reports.
+func OrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
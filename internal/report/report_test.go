@@ -0,0 +1,17 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := make([]time.Duration, 200)
+	for i := range durations {
+		durations[i] = time.Duration(i + 1)
+	}
+	assert.Equal(t, time.Duration(198), Percentile(durations, 99))
+	assert.Equal(t, time.Duration(1), Percentile(durations[:1], 99))
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"github.com/grafana/jfr-parser/common/units"
 	"strconv"
 
@@ -10,23 +11,33 @@ import (
 const (
 	valueProperty = "value"
 
-	annotationLabel         = "jdk.jfr.Label"
-	annotationDescription   = "jdk.jfr.Description"
-	annotationExperimental  = "jdk.jfr.Experimental"
-	annotationCategory      = "jdk.jfr.Category"
-	annotationTimestamp     = "jdk.jfr.Timestamp"
-	annotationTimespan      = "jdk.jfr.Timespan"
-	annotationMemoryAddress = "jdk.jfr.MemoryAddress"
-	annotationPercentage    = "jdk.jfr.Percentage"
-	annotationMemoryAmount  = "jdk.jfr.MemoryAmount"
-	annotationDataAmount    = "jdk.jfr.DataAmount"
-	annotationFrequency     = "jdk.jfr.Frequency"
-	annotationUnsigned      = "jdk.jfr.Unsigned"
+	annotationLabel          = "jdk.jfr.Label"
+	annotationDescription    = "jdk.jfr.Description"
+	annotationExperimental   = "jdk.jfr.Experimental"
+	annotationCategory       = "jdk.jfr.Category"
+	annotationTimestamp      = "jdk.jfr.Timestamp"
+	annotationTimespan       = "jdk.jfr.Timespan"
+	annotationMemoryAddress  = "jdk.jfr.MemoryAddress"
+	annotationPercentage     = "jdk.jfr.Percentage"
+	annotationMemoryAmount   = "jdk.jfr.MemoryAmount"
+	annotationDataAmount     = "jdk.jfr.DataAmount"
+	annotationFrequency      = "jdk.jfr.Frequency"
+	annotationUnsigned       = "jdk.jfr.Unsigned"
+	annotationName           = "jdk.jfr.Name"
+	annotationContentType    = "jdk.jfr.ContentType"
+	annotationRelational     = "jdk.jfr.Relational"
+	annotationTransitionFrom = "jdk.jfr.TransitionFrom"
+	annotationTransitionTo   = "jdk.jfr.TransitionTo"
+	annotationEnabled        = "jdk.jfr.Enabled"
+	annotationThreshold      = "jdk.jfr.Threshold"
+	annotationPeriod         = "jdk.jfr.Period"
+	annotationStackTrace     = "jdk.jfr.StackTrace"
 )
 
 const (
 	unitS            = "SECONDS"
 	unitMS           = "MILLISECONDS"
+	unitUS           = "MICROSECONDS"
 	unitNS           = "NANOSECONDS"
 	unitTicks        = "TICKS"
 	unitSSinceEpoch  = "SECONDS_SINCE_EPOCH"
@@ -54,58 +65,138 @@ func (a *AnnotationMetadata) SetAttribute(key, value string) (err error) {
 
 func (a *AnnotationMetadata) AppendChild(string) Element { return nil }
 
+// Annotation is an annotation of a class or field resolved against the class map.
+// Annotations defined by applications are kept the same way as the jdk.jfr ones.
+type Annotation struct {
+	Name   string
+	Class  *ClassMetadata // the annotation type, nil if it is missing from the metadata
+	Values map[string]string
+}
+
+// Value returns the "value" element of the annotation.
+func (a *Annotation) Value() string {
+	return a.Values[valueProperty]
+}
+
+// Bool returns the "value" element of the annotation as a boolean.
+func (a *Annotation) Bool() bool {
+	b, _ := parseBool(a.Value())
+	return b
+}
+
+// Array returns the elements of an array valued annotation element, which
+// are stored as key-0, key-1...
+func (a *Annotation) Array(key string) []string {
+	values := make([]string, 0)
+	for i := 0; ; i++ {
+		v, ok := a.Values[fmt.Sprintf("%s-%d", key, i)]
+		if !ok {
+			return values
+		}
+		values = append(values, v)
+	}
+}
+
+// Is reports whether the annotation type itself carries the given
+// meta-annotation, such as jdk.jfr.ContentType or jdk.jfr.Relational.
+func (a *Annotation) Is(name string) bool {
+	return a.Class != nil && a.Class.Annotation(name) != nil
+}
+
 type BaseAnnotation struct {
 	label        *string
 	description  *string
 	experimental *bool
+	resolved     []*Annotation
 	Annotations  []*AnnotationMetadata
 }
 
-func (b *BaseAnnotation) Label(classMap ClassMap) string {
-	if b.label == nil {
+// AllAnnotations returns all the annotations, including custom ones.
+func (b *BaseAnnotation) AllAnnotations(classMap ClassMap) []*Annotation {
+	if b.resolved == nil {
+		b.resolved = make([]*Annotation, 0, len(b.Annotations))
 		for _, annotation := range b.Annotations {
-			if classMap[annotation.ClassID].Name == annotationLabel {
-				b.label = utils.NewPointer(annotation.Values[valueProperty])
-				break
+			a := &Annotation{Values: annotation.Values, Class: classMap[annotation.ClassID]}
+			if a.Class != nil {
+				a.Name = a.Class.Name
 			}
+			b.resolved = append(b.resolved, a)
 		}
-		if b.label == nil {
-			b.label = utils.NewPointer("")
+	}
+	return b.resolved
+}
+
+// Annotation returns the annotation of the given type name, or nil.
+func (b *BaseAnnotation) Annotation(classMap ClassMap, name string) *Annotation {
+	for _, a := range b.AllAnnotations(classMap) {
+		if a.Name == name {
+			return a
 		}
 	}
+	return nil
+}
+
+func (b *BaseAnnotation) annotationValue(classMap ClassMap, name string) string {
+	if a := b.Annotation(classMap, name); a != nil {
+		return a.Value()
+	}
+	return ""
+}
+
+// findMeta returns the first annotation whose type carries the meta-annotation.
+func (b *BaseAnnotation) findMeta(classMap ClassMap, meta string) *Annotation {
+	for _, a := range b.AllAnnotations(classMap) {
+		if a.Is(meta) {
+			return a
+		}
+	}
+	return nil
+}
+
+func (b *BaseAnnotation) Label(classMap ClassMap) string {
+	if b.label == nil {
+		b.label = utils.NewPointer(b.annotationValue(classMap, annotationLabel))
+	}
 	return *b.label
 }
 
 func (b *BaseAnnotation) Description(classMap ClassMap) string {
 	if b.description == nil {
-		for _, annotation := range b.Annotations {
-			if classMap[annotation.ClassID].Name == annotationDescription {
-				b.description = utils.NewPointer(annotation.Values[valueProperty])
-				break
-			}
-		}
-		if b.description == nil {
-			b.description = utils.NewPointer("")
-		}
+		b.description = utils.NewPointer(b.annotationValue(classMap, annotationDescription))
 	}
 	return *b.description
 }
 
 func (b *BaseAnnotation) Experimental(classMap ClassMap) bool {
 	if b.experimental == nil {
-		if b.experimental == nil {
-			for _, annotation := range b.Annotations {
-				if classMap[annotation.ClassID].Name == annotationExperimental {
-					b.experimental = utils.NewPointer(true)
-					break
-				}
-			}
-			b.experimental = utils.NewPointer(false)
-		}
+		b.experimental = utils.NewPointer(b.Annotation(classMap, annotationExperimental) != nil)
 	}
 	return *b.experimental
 }
 
+// AnnotatedName returns the value of the jdk.jfr.Name annotation, or "".
+func (b *BaseAnnotation) AnnotatedName(classMap ClassMap) string {
+	return b.annotationValue(classMap, annotationName)
+}
+
+// ContentType returns the name of the content type annotation, an annotation
+// itself annotated with jdk.jfr.ContentType such as jdk.jfr.Timespan, or "".
+func (b *BaseAnnotation) ContentType(classMap ClassMap) string {
+	if a := b.findMeta(classMap, annotationContentType); a != nil {
+		return a.Name
+	}
+	return ""
+}
+
+// Relation returns the name of the relational annotation, an annotation itself
+// annotated with jdk.jfr.Relational, or "".
+func (b *BaseAnnotation) Relation(classMap ClassMap) string {
+	if a := b.findMeta(classMap, annotationRelational); a != nil {
+		return a.Name
+	}
+	return ""
+}
+
 type ClassAnnotation struct {
 	categories []string
 	BaseAnnotation
@@ -117,3 +208,19 @@ type FieldAnnotation struct {
 	unit          *units.Unit
 	BaseAnnotation
 }
+
+func (f *FieldAnnotation) Frequency(classMap ClassMap) bool {
+	return f.Annotation(classMap, annotationFrequency) != nil
+}
+
+func (f *FieldAnnotation) MemoryAddress(classMap ClassMap) bool {
+	return f.Annotation(classMap, annotationMemoryAddress) != nil
+}
+
+func (f *FieldAnnotation) TransitionFrom(classMap ClassMap) bool {
+	return f.Annotation(classMap, annotationTransitionFrom) != nil
+}
+
+func (f *FieldAnnotation) TransitionTo(classMap ClassMap) bool {
+	return f.Annotation(classMap, annotationTransitionTo) != nil
+}
@@ -3,8 +3,12 @@ package parser
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+
+	"github.com/grafana/jfr-parser/parser/types/def"
 )
 
 const (
@@ -53,6 +57,8 @@ type Chunk struct {
 	Header   Header
 	Metadata ChunkMetadata
 	ChunkEvents
+
+	cpools PoolMap
 }
 
 type EventCollection struct {
@@ -66,6 +72,36 @@ func (c *EventCollection) Add(e *GenericEvent) {
 
 type ChunkParseOptions struct {
 	CPoolProcessor func(meta *ClassMetadata, cpool *CPool)
+
+	// Limits on lengths read from the recording, turning hostile input into
+	// errors rather than huge allocations. Zero means no limit besides the size
+	// of the input itself.
+	ChunkSizeLimit    int
+	StringLengthLimit int
+	ArrayLengthLimit  int
+	ConstantPoolLimit int
+
+	// Recover makes parsing lenient towards truncated or partially corrupt
+	// recordings: all complete chunks are returned, and a chunk which was never
+	// finalised is scanned up to the end of the stream, reusing the metadata and
+	// constant pools of the previous chunk when its own are missing. The error
+	// which cut the recording short is returned as a *RecoveredError next to
+	// the chunks. Input which does not start with a readable chunk still fails.
+	Recover bool
+}
+
+// RecoveredError is the error which cut a recording short in recovery mode,
+// returned along with the chunks read until then.
+type RecoveredError struct {
+	Err error
+}
+
+func (e *RecoveredError) Error() string {
+	return fmt.Sprintf("recovered from truncated recording: %s", e.Err)
+}
+
+func (e *RecoveredError) Unwrap() error {
+	return e.Err
 }
 
 func (c *Chunk) addEvent(e *GenericEvent) {
@@ -102,6 +138,10 @@ func (c *Chunk) ShowClassMeta(name string) {
 }
 
 func (c *Chunk) Parse(r io.Reader, options *ChunkParseOptions) (err error) {
+	return c.parse(r, options, nil)
+}
+
+func (c *Chunk) parse(r io.Reader, options *ChunkParseOptions, prev *Chunk) (err error) {
 	bufR, ok := r.(*bufio.Reader)
 	if !ok {
 		bufR = bufio.NewReader(r)
@@ -138,17 +178,72 @@ func (c *Chunk) Parse(r io.Reader, options *ChunkParseOptions) (err error) {
 	c.Header.MetadataOffset -= headerSize + 8
 	c.Header.ConstantPoolOffset -= headerSize + 8
 	useCompression := c.Header.Features&1 == 1
-	// TODO: assert c.Header.ChunkSize is small enough
-	buf = make([]byte, c.Header.ChunkSize)
-	if _, err := io.ReadFull(r, buf); err != nil {
-		return fmt.Errorf("unable to read chunk contents: %w", err)
+	// a chunk which was never finalised runs until the end of the stream
+	unfinished := options.Recover && (c.Header.ChunkSize <= 0 || c.Header.MetadataOffset < 0 || c.Header.ConstantPoolOffset < 0)
+	size := c.Header.ChunkSize
+	if unfinished {
+		size = math.MaxInt64
+	} else if size < 0 {
+		return fmt.Errorf("invalid chunk size: %d", size+headerSize+8)
+	}
+	if limit := int64(options.ChunkSizeLimit) - headerSize - 8; options.ChunkSizeLimit > 0 && size > limit {
+		if !unfinished {
+			return fmt.Errorf("chunk size %d exceeds limit %d", size+headerSize+8, options.ChunkSizeLimit)
+		}
+		size = limit
 	}
+	// the buffer grows with the data actually read, not with the announced size
+	cr := NewChunkReader(bufR)
+	var truncated error
+	if _, err := cr.FillTo(int(size)); err != nil {
+		if !options.Recover || err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("unable to read chunk contents: %w", err)
+		}
+		if !unfinished {
+			truncated = fmt.Errorf("unable to read chunk contents: %w", err)
+		}
+		unfinished = true
+	}
+	buf = cr.buf[:cr.size]
+	chunkSize := int64(len(buf))
 
 	br := bytes.NewReader(buf)
-	rd := NewReader(br, useCompression)
-	pointer := int64(0)
+	rd := newReader(br, useCompression, def.Limits{
+		StringLength:        options.StringLengthLimit,
+		ArrayLength:         options.ArrayLengthLimit,
+		ConstantPoolEntries: options.ConstantPoolLimit,
+	})
 	eventsOffset := make(map[int64]int32)
 
+	if !unfinished || (c.Header.MetadataOffset >= 0 && c.Header.MetadataOffset < chunkSize &&
+		c.Header.ConstantPoolOffset >= 0 && c.Header.ConstantPoolOffset < chunkSize) {
+		err = c.parseConstants(br, rd, eventsOffset, options)
+		if err != nil && (!unfinished || prev == nil) {
+			return err
+		}
+	}
+	if unfinished && c.cpools == nil {
+		if prev == nil {
+			return fmt.Errorf("unfinished chunk without metadata")
+		}
+		c.Metadata = prev.Metadata
+		c.cpools = prev.cpools
+	}
+
+	err = c.parseEvents(br, rd, eventsOffset, chunkSize)
+	if unfinished {
+		// keep the events read so far, the rest of the chunk is lost
+		if err == nil {
+			err = truncated
+		}
+		if err != nil {
+			return &RecoveredError{Err: err}
+		}
+	}
+	return err
+}
+
+func (c *Chunk) parseConstants(br *bytes.Reader, rd Reader, eventsOffset map[int64]int32, options *ChunkParseOptions) error {
 	// Parse metadata
 	if _, err := br.Seek(c.Header.MetadataOffset, io.SeekStart); err != nil {
 		return fmt.Errorf("unable to seek reader: %w", err)
@@ -173,7 +268,12 @@ func (c *Chunk) Parse(r io.Reader, options *ChunkParseOptions) (err error) {
 	cpools := make(PoolMap)
 	delta := int64(0)
 	cp := new(ConstantPoolEvent)
+	visited := make(map[int64]struct{})
 	for {
+		if _, ok := visited[delta]; ok {
+			return fmt.Errorf("constant pool cycle at %d", c.Header.ConstantPoolOffset+delta)
+		}
+		visited[delta] = struct{}{}
 		size, err := rd.VarInt()
 		if err != nil {
 			return fmt.Errorf("unable to parse checkpoint event size: %w", err)
@@ -199,15 +299,19 @@ func (c *Chunk) Parse(r io.Reader, options *ChunkParseOptions) (err error) {
 	}
 
 	// Second pass over constant pools: resolve constants
-	if err = ResolveConstants(c.Metadata.ClassMap, cpools); err != nil {
+	if err := ResolveConstants(c.Metadata.ClassMap, cpools); err != nil {
 		return err
 	}
+	c.cpools = cpools
+	return nil
+}
 
-	// Parse the rest of events
+func (c *Chunk) parseEvents(br *bytes.Reader, rd Reader, eventsOffset map[int64]int32, chunkSize int64) error {
+	pointer := int64(0)
 	if _, err := br.Seek(pointer, io.SeekStart); err != nil {
 		return fmt.Errorf("unable to seek reader: %w", err)
 	}
-	for pointer != c.Header.ChunkSize {
+	for pointer != chunkSize {
 		if size, ok := eventsOffset[pointer]; ok {
 			pointer += int64(size)
 		} else {
@@ -218,11 +322,14 @@ func (c *Chunk) Parse(r io.Reader, options *ChunkParseOptions) (err error) {
 			if err != nil {
 				return fmt.Errorf("unable to parse event size: %w", err)
 			}
-			if size == 0 {
-				return fmt.Errorf("found event with invalid size (0)")
+			if size <= 0 {
+				return fmt.Errorf("found event with invalid size (%d)", size)
+			}
+			if int64(size) > chunkSize-pointer {
+				return fmt.Errorf("event at %d with size %d exceeds the chunk", pointer, size)
 			}
 			eventsOffset[pointer] = size
-			ge, err := ParseEvent(rd, c.Metadata.ClassMap, cpools)
+			ge, err := ParseEvent(rd, c.Metadata.ClassMap, c.cpools)
 			if err != nil {
 				return fmt.Errorf("unable to parse event: %w", err)
 			}
@@ -255,3 +362,106 @@ func ResolveConstants(classes ClassMap, poolMap PoolMap) (err error) {
 
 	return nil
 }
+
+const chunkReaderMinGrow = 4096
+
+// ChunkReader buffers a chunk read from a stream. The buffer grows with the data
+// actually read rather than with the size announced by the chunk header, and
+// keeps everything read so far available for random access.
+type ChunkReader struct {
+	r    io.Reader
+	buf  []byte
+	pos  int
+	size int
+	err  error
+}
+
+func NewChunkReader(r io.Reader) *ChunkReader {
+	return &ChunkReader{r: r}
+}
+
+// FillTo reads from the underlying stream until n bytes are buffered. It returns
+// the number of bytes added, and io.ErrUnexpectedEOF if the stream ends before.
+func (c *ChunkReader) FillTo(n int) (int, error) {
+	start := c.size
+	for c.size < n && c.err == nil {
+		if c.size == len(c.buf) {
+			grow := len(c.buf)
+			if grow < chunkReaderMinGrow {
+				grow = chunkReaderMinGrow
+			}
+			if grow > n-c.size {
+				grow = n - c.size
+			}
+			buf := make([]byte, len(c.buf)+grow)
+			copy(buf, c.buf[:c.size])
+			c.buf = buf
+		}
+		m, err := c.r.Read(c.buf[c.size:])
+		c.size += m
+		if err != nil {
+			c.err = err
+		}
+	}
+	if c.size < n {
+		if c.err == io.EOF {
+			return c.size - start, io.ErrUnexpectedEOF
+		}
+		return c.size - start, c.err
+	}
+	return c.size - start, nil
+}
+
+// Unread returns the number of buffered bytes not read yet.
+func (c *ChunkReader) Unread() int {
+	return c.size - c.pos
+}
+
+func (c *ChunkReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if c.pos >= c.size {
+		if _, err := c.FillTo(c.pos + len(p)); c.pos >= c.size {
+			if c.err != nil {
+				return 0, c.err
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, c.buf[c.pos:c.size])
+	c.pos += n
+	return n, nil
+}
+
+// ReadAt reads from the beginning of the chunk, regardless of the read position.
+func (c *ChunkReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off > int64(math.MaxInt-len(p)) {
+		return 0, io.EOF
+	}
+	c.FillTo(int(off) + len(p))
+	if int(off) >= c.size {
+		return 0, io.EOF
+	}
+	n := copy(p, c.buf[off:c.size])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Skip advances the read position by n bytes, returning the number of bytes
+// actually skipped.
+func (c *ChunkReader) Skip(n int) (int, error) {
+	_, err := c.FillTo(c.pos + n)
+	skipped := c.size - c.pos
+	if skipped >= n {
+		c.pos += n
+		return n, nil
+	}
+	c.pos = c.size
+	return skipped, err
+}
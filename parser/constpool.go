@@ -33,7 +33,9 @@ func (c *ConstantPoolEvent) Parse(r Reader, classes ClassMap, cpools PoolMap) (e
 	if err != nil {
 		return fmt.Errorf("unable to parse checkpoint event's number of constant pools: %w", err)
 	}
-	// TODO: assert n is small enough
+	if err := checkLength(r, n, 0, "constant pools"); err != nil {
+		return fmt.Errorf("invalid checkpoint event's number of constant pools: %w", err)
+	}
 	for i := 0; i < int(n); i++ {
 		classID, err := r.VarLong()
 		if err != nil {
@@ -48,7 +50,9 @@ func (c *ConstantPoolEvent) Parse(r Reader, classes ClassMap, cpools PoolMap) (e
 		if err != nil {
 			return fmt.Errorf("unable to parse constant pool's number of constants: %w", err)
 		}
-		// TODO: assert m is small enough
+		if err := checkLength(r, m, readerLimits(r).ConstantPoolEntries, "constant pool entries"); err != nil {
+			return fmt.Errorf("invalid constant pool's number of constants: %w", err)
+		}
 		for j := 0; j < int(m); j++ {
 			idx, err := r.VarLong()
 			if err != nil {
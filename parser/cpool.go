@@ -8,7 +8,56 @@ import (
 )
 
 func (p *Parser) readConstantPool(pos int) error {
+	p.TypeMap.Strings = make(map[uint64]string)
+	p.TypeMap.UnresolvedStrings = 0
+	p.resetConstantPools()
+	if err := p.readConstantPoolChain(pos); err != nil {
+		return err
+	}
+	// constants may reference strings of a java.lang.String pool further down
+	// the chain, a second pass resolves them
+	if p.TypeMap.UnresolvedStrings > 0 && len(p.TypeMap.Strings) > 0 {
+		p.TypeMap.UnresolvedStrings = 0
+		p.resetConstantPools()
+		return p.readConstantPoolChain(pos)
+	}
+	return nil
+}
+
+// resetConstantPools empties the lists before reading the constant pools of a
+// chunk. The checkpoints of the chain extend the lists rather than replace
+// them: the leak profiler, for one, writes the threads and stack traces of the
+// old object samples in a checkpoint of its own. The lists are reallocated,
+// not truncated, as the previous chunk may still be in use by
+// readUnfinishedChunk.
+func (p *Parser) resetConstantPools() {
+	p.FrameTypes.IDMap = nil
+	p.ThreadStates.IDMap = nil
+	p.Threads.IDMap = nil
+	p.Classes.IDMap = nil
+	// the IDMap of the methods is a struct rather than a map, its zero value
+	// is the empty map the others are reset to with nil
+	p.Methods.IDMap = gtypes.IDMap[gtypes.MethodRef]{}
+	p.Packages.IDMap = nil
+	p.Symbols.IDMap = nil
+	p.LogLevels.IDMap = nil
+	p.Stacktrace.IDMap = nil
+	p.OldObjects.IDMap = nil
+	p.References.IDMap = nil
+	p.OldObjectArrays.IDMap = nil
+	p.OldObjectFields.IDMap = nil
+	p.OldObjectGcRoots.IDMap = nil
+	p.OldObjectRootSystems.IDMap = nil
+	p.OldObjectRootTypes.IDMap = nil
+}
+
+func (p *Parser) readConstantPoolChain(pos int) error {
+	visited := make(map[int]struct{})
 	for {
+		if _, ok := visited[pos]; ok {
+			return fmt.Errorf("constant pool cycle @ %d", pos)
+		}
+		visited[pos] = struct{}{}
 		if err := p.seek(pos); err != nil {
 			return err
 		}
@@ -40,6 +89,9 @@ func (p *Parser) readConstantPool(pos int) error {
 		if err != nil {
 			return err
 		}
+		if err := p.checkLength(int(n), 0, "constant pools"); err != nil {
+			return err
+		}
 		_ = startTimeTicks
 		_ = duration
 		_ = delta
@@ -80,6 +132,8 @@ func (p *Parser) readConstants(c *def.Class) error {
 	case "jdk.types.ChunkHeader":
 		p.pos += chunkHeaderSize
 		return nil
+	case "java.lang.String":
+		return p.readStrings()
 	case "jdk.types.FrameType":
 		o, err := p.FrameTypes.Parse(p.buf[p.pos:], p.bindFrameType, &p.TypeMap)
 		p.pos += o
@@ -119,6 +173,55 @@ func (p *Parser) readConstants(c *def.Class) error {
 		o, err := p.Stacktrace.Parse(p.buf[p.pos:], p.bindStackTrace, p.bindStackFrame, &p.TypeMap)
 		p.pos += o
 		return err
+	case "jdk.types.OldObject":
+		if p.bindOldObject == nil {
+			return fmt.Errorf("no \"jdk.types.OldObject\"")
+		}
+		o, err := p.OldObjects.Parse(p.buf[p.pos:], p.bindOldObject, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.Reference":
+		if p.bindReference == nil {
+			return fmt.Errorf("no \"jdk.types.Reference\"")
+		}
+		o, err := p.References.Parse(p.buf[p.pos:], p.bindReference, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.OldObjectArray":
+		if p.bindOldObjectArray == nil {
+			return fmt.Errorf("no \"jdk.types.OldObjectArray\"")
+		}
+		o, err := p.OldObjectArrays.Parse(p.buf[p.pos:], p.bindOldObjectArray, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.OldObjectField":
+		if p.bindOldObjectField == nil {
+			return fmt.Errorf("no \"jdk.types.OldObjectField\"")
+		}
+		o, err := p.OldObjectFields.Parse(p.buf[p.pos:], p.bindOldObjectField, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.OldObjectGcRoot":
+		if p.bindOldObjectGcRoot == nil {
+			return fmt.Errorf("no \"jdk.types.OldObjectGcRoot\"")
+		}
+		o, err := p.OldObjectGcRoots.Parse(p.buf[p.pos:], p.bindOldObjectGcRoot, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.OldObjectRootSystem":
+		if p.bindOldObjectRootSystem == nil {
+			return fmt.Errorf("no \"jdk.types.OldObjectRootSystem\"")
+		}
+		o, err := p.OldObjectRootSystems.Parse(p.buf[p.pos:], p.bindOldObjectRootSystem, &p.TypeMap)
+		p.pos += o
+		return err
+	case "jdk.types.OldObjectRootType":
+		if p.bindOldObjectRootType == nil {
+			return fmt.Errorf("no \"jdk.types.OldObjectRootType\"")
+		}
+		o, err := p.OldObjectRootTypes.Parse(p.buf[p.pos:], p.bindOldObjectRootType, &p.TypeMap)
+		p.pos += o
+		return err
 	default:
 		b := gtypes.NewBindSkipConstantPool(c, &p.TypeMap)
 		skipper := gtypes.SkipConstantPoolList{}
@@ -127,3 +230,25 @@ func (p *Parser) readConstants(c *def.Class) error {
 		return err
 	}
 }
+
+func (p *Parser) readStrings() error {
+	n, err := p.varInt()
+	if err != nil {
+		return err
+	}
+	if err := p.checkLength(int(n), p.options.ConstantPoolLimit, "constant pool entries"); err != nil {
+		return err
+	}
+	for i := 0; i < int(n); i++ {
+		id, err := p.varLong()
+		if err != nil {
+			return err
+		}
+		s, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.TypeMap.Strings[id] = s
+	}
+	return nil
+}
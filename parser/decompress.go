@@ -2,12 +2,15 @@ package parser
 
 import (
 	"bytes"
+	"compress/bzip2"
 	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
+
 	"github.com/GuanceCloud/zipstream"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
-	"io"
 )
 
 type CompressionType uint8
@@ -18,71 +21,276 @@ const (
 	GZip
 	ZIP
 	LZ4
+	ZSTD
+	BZip2
+	XZ
 )
 
+func (t CompressionType) String() string {
+	switch t {
+	case PlainJFR:
+		return "jfr"
+	case GZip:
+		return "gzip"
+	case ZIP:
+		return "zip"
+	case LZ4:
+		return "lz4"
+	case ZSTD:
+		return "zstd"
+	case BZip2:
+		return "bzip2"
+	case XZ:
+		return "xz"
+	default:
+		return "unknown"
+	}
+}
+
 var (
-	JFRMagic  = []byte{'F', 'L', 'R', 0}
-	ZIPMagic  = []byte{0x50, 0x4b, 3, 4}
-	LZ4Magic  = []byte{4, 34, 77, 24}
-	GZipMagic = []byte{31, 139}
+	JFRMagic   = []byte{'F', 'L', 'R', 0}
+	ZIPMagic   = []byte{0x50, 0x4b, 3, 4}
+	LZ4Magic   = []byte{4, 34, 77, 24}
+	GZipMagic  = []byte{31, 139}
+	ZSTDMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	BZip2Magic = []byte{'B', 'Z', 'h'}
+	XZMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0}
 )
 
+// magicLen is the number of leading bytes needed to tell all supported formats apart.
+const magicLen = 6
+
+// zstdMaxWindow caps the window a zstd frame may declare, the decoder
+// allocates it up front. It is the largest window of the zstd CLI, with --long.
+const zstdMaxWindow = 128 << 20
+
+// ErrDecompressedSizeExceeded is returned when the decompressed stream grows
+// beyond DecompressOptions.MaxSize.
+var ErrDecompressedSizeExceeded = errors.New("decompressed size limit exceeded")
+
+// DecompressOptions controls how Decompress unpacks its input.
+type DecompressOptions struct {
+	// ZipEntry selects the zip entry with this name. By default the first
+	// regular file of the archive is used.
+	ZipEntry string
+	// AllZipEntries concatenates every zip entry that starts with the JFR
+	// magic. JFR chunks are self-delimiting, so the result parses as a single
+	// multi-chunk recording. ZipEntry is ignored when this is set.
+	AllZipEntries bool
+	// MaxSize caps the number of decompressed bytes; reading past it fails
+	// with ErrDecompressedSizeExceeded. It also caps the window of zstd
+	// frames. Zero means no limit.
+	MaxSize int64
+}
+
 func hasMagic(buf []byte, magic []byte) bool {
 	if len(buf) < len(magic) {
 		return false
 	}
-	return bytes.Compare(buf[:len(magic)], magic) == 0
+	return bytes.Equal(buf[:len(magic)], magic)
 }
 
 func GuessCompressionType(magic []byte) CompressionType {
-	if len(magic) == 4 {
-		if hasMagic(magic, ZIPMagic) {
-			return ZIP
-		} else if hasMagic(magic, LZ4Magic) {
-			return LZ4
-		} else if hasMagic(magic, JFRMagic) {
-			return PlainJFR
-		}
-	}
-	if len(magic) >= 2 && hasMagic(magic[:2], GZipMagic) {
+	switch {
+	case hasMagic(magic, ZIPMagic):
+		return ZIP
+	case hasMagic(magic, LZ4Magic):
+		return LZ4
+	case hasMagic(magic, JFRMagic):
+		return PlainJFR
+	case hasMagic(magic, ZSTDMagic):
+		return ZSTD
+	case hasMagic(magic, XZMagic):
+		return XZ
+	case hasMagic(magic, BZip2Magic):
+		return BZip2
+	case hasMagic(magic, GZipMagic):
 		return GZip
 	}
 	return Unknown
 }
 
 func Decompress(r io.Reader) (io.ReadCloser, error) {
-	buf := make([]byte, 4)
+	rc, _, err := DecompressWithOptions(r, DecompressOptions{})
+	return rc, err
+}
+
+// DecompressWithOptions detects the container format of r and returns a reader
+// of the uncompressed JFR data together with the detected CompressionType.
+// The type is also returned alongside errors once the magic could be read.
+func DecompressWithOptions(r io.Reader, options DecompressOptions) (io.ReadCloser, CompressionType, error) {
+	buf := make([]byte, magicLen)
 	n, err := io.ReadFull(r, buf)
 	if n == 0 && err != nil {
-		return nil, fmt.Errorf("unable to read file magic: %w", err)
+		return nil, Unknown, fmt.Errorf("unable to read file magic: %w", err)
 	}
 
 	buf = buf[:n]
 	typ := GuessCompressionType(buf)
 	r = io.MultiReader(bytes.NewReader(buf), r)
 
+	var rc io.ReadCloser
 	switch typ {
 	case GZip:
-		return gzip.NewReader(r)
+		rc, err = gzip.NewReader(r)
 	case ZIP:
-		zr := zipstream.NewReader(r)
-		for {
-			entry, err := zr.GetNextEntry()
-			if err != nil {
-				if err == io.EOF {
-					return nil, fmt.Errorf("the zip archive does not contain any regular file")
+		rc, err = openZip(zipstream.NewReader(r), options)
+	case LZ4:
+		rc = io.NopCloser(lz4.NewReader(r))
+	case ZSTD:
+		var d *zstd.Decoder
+		d, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(zstdWindow(options.MaxSize)))
+		if err == nil {
+			rc = d.IOReadCloser()
+		}
+	case BZip2:
+		rc = io.NopCloser(bzip2.NewReader(r))
+	case PlainJFR:
+		rc = io.NopCloser(r)
+	case XZ:
+		return nil, typ, errors.New("unsupported compression type: xz")
+	default:
+		return nil, typ, errors.New("unsupported compression type")
+	}
+	if err != nil {
+		return nil, typ, err
+	}
+	if options.MaxSize > 0 {
+		rc = &sizeLimitedReader{rc: rc, remaining: options.MaxSize}
+	}
+	return rc, typ, nil
+}
+
+// zstdWindow returns the largest zstd window to allocate, a window larger
+// than the size cap is never filled by a stream within it.
+func zstdWindow(maxSize int64) uint64 {
+	if maxSize <= 0 || maxSize >= zstdMaxWindow {
+		return zstdMaxWindow
+	}
+	if maxSize < zstd.MinWindowSize {
+		return zstd.MinWindowSize
+	}
+	return uint64(maxSize)
+}
+
+func openZip(zr *zipstream.Reader, options DecompressOptions) (io.ReadCloser, error) {
+	if options.AllZipEntries {
+		return &zipEntriesReader{zr: zr}, nil
+	}
+	for {
+		entry, err := zr.GetNextEntry()
+		if err != nil {
+			if err == io.EOF {
+				if options.ZipEntry != "" {
+					return nil, fmt.Errorf("the zip archive does not contain entry %q", options.ZipEntry)
 				}
-				return nil, fmt.Errorf("unable to resolve zip entry: %w", err)
+				return nil, fmt.Errorf("the zip archive does not contain any regular file")
 			}
-			if !entry.IsDir() {
-				return entry.Open()
+			return nil, fmt.Errorf("unable to resolve zip entry: %w", err)
+		}
+		if entry.IsDir() {
+			continue
+		}
+		if options.ZipEntry == "" || entry.Name == options.ZipEntry {
+			return entry.Open()
+		}
+	}
+}
+
+// zipEntriesReader reads the JFR entries of a zip archive back to back.
+type zipEntriesReader struct {
+	zr    *zipstream.Reader
+	cur   io.ReadCloser
+	found bool
+}
+
+func (z *zipEntriesReader) Read(p []byte) (int, error) {
+	for {
+		if z.cur == nil {
+			if err := z.next(); err != nil {
+				return 0, err
 			}
 		}
-	case LZ4:
-		return io.NopCloser(lz4.NewReader(r)), nil
-	case PlainJFR:
-		return io.NopCloser(r), nil
-	default:
-		return nil, errors.New("unsupported compression type")
+		n, err := z.cur.Read(p)
+		if err == io.EOF {
+			z.cur.Close()
+			z.cur = nil
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+func (z *zipEntriesReader) next() error {
+	for {
+		entry, err := z.zr.GetNextEntry()
+		if err != nil {
+			if err == io.EOF {
+				if !z.found {
+					return fmt.Errorf("the zip archive does not contain any JFR file")
+				}
+				return io.EOF
+			}
+			return fmt.Errorf("unable to resolve zip entry: %w", err)
+		}
+		if entry.IsDir() {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("unable to open zip entry %q: %w", entry.Name, err)
+		}
+		magic := make([]byte, len(JFRMagic))
+		n, err := io.ReadFull(rc, magic)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			rc.Close()
+			return fmt.Errorf("unable to read zip entry %q: %w", entry.Name, err)
+		}
+		if !hasMagic(magic[:n], JFRMagic) {
+			rc.Close()
+			continue
+		}
+		z.found = true
+		z.cur = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(magic), rc), rc}
+		return nil
 	}
 }
+
+func (z *zipEntriesReader) Close() error {
+	if z.cur != nil {
+		return z.cur.Close()
+	}
+	return nil
+}
+
+type sizeLimitedReader struct {
+	rc        io.ReadCloser
+	remaining int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for a single byte to tell an exact fit from an overflow.
+		var b [1]byte
+		n, err := l.rc.Read(b[:])
+		if n > 0 {
+			return 0, ErrDecompressedSizeExceeded
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.rc.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *sizeLimitedReader) Close() error {
+	return l.rc.Close()
+}
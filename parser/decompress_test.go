@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"archive/zip"
 	"bytes"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"testing"
 )
@@ -24,6 +27,14 @@ var (
 			name: "gzip",
 			file: []byte{31, 139, 8, 8, 25, 230, 194, 99, 0, 3, 50, 46, 116, 120, 116, 0, 203, 72, 205, 201, 201, 87, 40, 207, 47, 202, 73, 225, 226, 2, 0, 221, 82, 167, 119, 13, 0, 0, 0},
 		},
+		{
+			name: "zstd",
+			file: []byte{40, 181, 47, 253, 36, 13, 105, 0, 0, 104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 10, 10, 46, 73, 200, 30},
+		},
+		{
+			name: "bzip2",
+			file: []byte{66, 90, 104, 57, 49, 65, 89, 38, 83, 89, 122, 157, 112, 224, 0, 0, 2, 209, 128, 0, 16, 64, 0, 6, 68, 144, 128, 32, 0, 49, 6, 76, 65, 0, 122, 37, 208, 24, 150, 195, 31, 139, 185, 34, 156, 40, 72, 61, 78, 184, 112, 0},
+		},
 		{
 			name: "lz4",
 			file: []byte{4, 34, 77, 24, 100, 64, 167, 13, 0, 0, 128, 104, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 10, 10, 0, 0, 0, 0, 157, 89, 174, 11},
@@ -55,3 +66,135 @@ func TestUncompress(t *testing.T) {
 		})
 	}
 }
+
+func TestDecompressType(t *testing.T) {
+	expected := map[string]CompressionType{
+		"zip":   ZIP,
+		"gzip":  GZip,
+		"zstd":  ZSTD,
+		"bzip2": BZip2,
+		"lz4":   LZ4,
+	}
+	for _, tc := range testCases {
+		r, typ, err := DecompressWithOptions(bytes.NewReader(tc.file), DecompressOptions{})
+		if err != nil {
+			t.Fatalf("%s: unable to uncompress file: %s", tc.name, err)
+		}
+		r.Close()
+		if typ != expected[tc.name] {
+			t.Errorf("%s: expected type %s, got %s", tc.name, expected[tc.name], typ)
+		}
+	}
+
+	xz := []byte{0xfd, '7', 'z', 'X', 'Z', 0, 0, 4}
+	if _, typ, err := DecompressWithOptions(bytes.NewReader(xz), DecompressOptions{}); err == nil || typ != XZ {
+		t.Errorf("expected an unsupported xz error, got %s, %v", typ, err)
+	}
+}
+
+func TestDecompressMaxSize(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _, err := DecompressWithOptions(bytes.NewReader(tc.file), DecompressOptions{MaxSize: int64(len(plainText))})
+			if err != nil {
+				t.Fatalf("unable to uncompress file: %s", err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if err != nil || string(data) != plainText {
+				t.Fatalf("expected exact fit to succeed, got [%s], %v", string(data), err)
+			}
+
+			r, _, err = DecompressWithOptions(bytes.NewReader(tc.file), DecompressOptions{MaxSize: 5})
+			if err != nil {
+				t.Fatalf("unable to uncompress file: %s", err)
+			}
+			_, err = io.ReadAll(r)
+			r.Close()
+			if !errors.Is(err, ErrDecompressedSizeExceeded) {
+				t.Fatalf("expected ErrDecompressedSizeExceeded, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecompressZstdWindow(t *testing.T) {
+	// an empty frame declaring a window of 2^(10+exponent) bytes
+	frame := func(exponent byte) []byte {
+		return []byte{0x28, 0xb5, 0x2f, 0xfd, 0, exponent << 3, 1, 0, 0}
+	}
+	read := func(file []byte, options DecompressOptions) error {
+		r, _, err := DecompressWithOptions(bytes.NewReader(file), options)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	if err := read(frame(16), DecompressOptions{}); err != nil {
+		t.Errorf("expected a 64 MiB window to be accepted, got %v", err)
+	}
+	if err := read(frame(18), DecompressOptions{}); err == nil {
+		t.Errorf("expected a 256 MiB window to be rejected")
+	}
+	if err := read(frame(6), DecompressOptions{MaxSize: 1 << 16}); err != nil {
+		t.Errorf("expected a window within the size cap to be accepted, got %v", err)
+	}
+	if err := read(frame(7), DecompressOptions{MaxSize: 1 << 16}); err == nil {
+		t.Errorf("expected a window beyond the size cap to be rejected")
+	}
+}
+
+func TestDecompressZipEntries(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range []struct{ name, data string }{
+		{"dir/", ""},
+		{"a.jfr", "FLR\x00a"},
+		{"readme.txt", "notes"},
+		{"b.jfr", "FLR\x00b"},
+	} {
+		// zipstream needs the sizes in the local header, so no data descriptors.
+		w, err := zw.CreateRaw(&zip.FileHeader{
+			Name:               f.name,
+			Method:             zip.Store,
+			CRC32:              crc32.ChecksumIEEE([]byte(f.data)),
+			CompressedSize64:   uint64(len(f.data)),
+			UncompressedSize64: uint64(len(f.data)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(options DecompressOptions) (string, error) {
+		r, _, err := DecompressWithOptions(bytes.NewReader(buf.Bytes()), options)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		return string(data), err
+	}
+
+	if data, err := read(DecompressOptions{}); err != nil || data != "FLR\x00a" {
+		t.Errorf("first entry: got [%q], %v", data, err)
+	}
+	if data, err := read(DecompressOptions{ZipEntry: "readme.txt"}); err != nil || data != "notes" {
+		t.Errorf("named entry: got [%q], %v", data, err)
+	}
+	if _, err := read(DecompressOptions{ZipEntry: "missing.jfr"}); err == nil {
+		t.Errorf("expected an error for a missing entry")
+	}
+	if data, err := read(DecompressOptions{AllZipEntries: true}); err != nil || data != "FLR\x00aFLR\x00b" {
+		t.Errorf("all entries: got [%q], %v", data, err)
+	}
+}
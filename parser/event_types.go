@@ -20,6 +20,11 @@ var events = map[string]func() Event{
 	"jdk.CodeSweeperStatistics":                func() Event { return new(CodeSweeperStatistics) },
 	"jdk.CompilerConfiguration":                func() Event { return new(CompilerConfiguration) },
 	"jdk.CompilerStatistics":                   func() Event { return new(CompilerStatistics) },
+	"jdk.ContainerCPUThrottling":               func() Event { return new(ContainerCPUThrottling) },
+	"jdk.ContainerCPUUsage":                    func() Event { return new(ContainerCPUUsage) },
+	"jdk.ContainerConfiguration":               func() Event { return new(ContainerConfiguration) },
+	"jdk.ContainerIOUsage":                     func() Event { return new(ContainerIOUsage) },
+	"jdk.ContainerMemoryUsage":                 func() Event { return new(ContainerMemoryUsage) },
 	"jdk.DoubleFlag":                           func() Event { return new(DoubleFlag) },
 	"jdk.ExceptionStatistics":                  func() Event { return new(ExceptionStatistics) },
 	"jdk.ExecutionSample":                      func() Event { return new(ExecutionSample) },
@@ -764,6 +769,146 @@ func (cs *CompilerStatistics) Parse(r Reader, classes ClassMap, cpools PoolMap,
 	return parseFields(r, classes, cpools, class, nil, true, cs.setField)
 }
 
+type ContainerCPUThrottling struct {
+	StartTime          int64
+	CpuElapsedSlices   int64
+	CpuThrottledSlices int64
+	CpuThrottledTime   int64
+}
+
+func (cct *ContainerCPUThrottling) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "startTime":
+		cct.StartTime, err = toLong(p)
+	case "cpuElapsedSlices":
+		cct.CpuElapsedSlices, err = toLong(p)
+	case "cpuThrottledSlices":
+		cct.CpuThrottledSlices, err = toLong(p)
+	case "cpuThrottledTime":
+		cct.CpuThrottledTime, err = toLong(p)
+	}
+	return err
+}
+
+func (cct *ContainerCPUThrottling) Parse(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadata) error {
+	return parseFields(r, classes, cpools, class, nil, true, cct.setField)
+}
+
+type ContainerCPUUsage struct {
+	StartTime     int64
+	CpuTime       int64
+	CpuUserTime   int64
+	CpuSystemTime int64
+}
+
+func (ccu *ContainerCPUUsage) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "startTime":
+		ccu.StartTime, err = toLong(p)
+	case "cpuTime":
+		ccu.CpuTime, err = toLong(p)
+	case "cpuUserTime":
+		ccu.CpuUserTime, err = toLong(p)
+	case "cpuSystemTime":
+		ccu.CpuSystemTime, err = toLong(p)
+	}
+	return err
+}
+
+func (ccu *ContainerCPUUsage) Parse(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadata) error {
+	return parseFields(r, classes, cpools, class, nil, true, ccu.setField)
+}
+
+type ContainerConfiguration struct {
+	StartTime         int64
+	ContainerType     string
+	CpuSlicePeriod    int64
+	CpuQuota          int64
+	CpuShares         int64
+	EffectiveCpuCount int64
+	MemorySoftLimit   int64
+	MemoryLimit       int64
+	SwapMemoryLimit   int64
+	HostTotalMemory   int64
+}
+
+func (cc *ContainerConfiguration) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "startTime":
+		cc.StartTime, err = toLong(p)
+	case "containerType":
+		cc.ContainerType, err = ToString(p)
+	case "cpuSlicePeriod":
+		cc.CpuSlicePeriod, err = toLong(p)
+	case "cpuQuota":
+		cc.CpuQuota, err = toLong(p)
+	case "cpuShares":
+		cc.CpuShares, err = toLong(p)
+	case "effectiveCpuCount":
+		cc.EffectiveCpuCount, err = toLong(p)
+	case "memorySoftLimit":
+		cc.MemorySoftLimit, err = toLong(p)
+	case "memoryLimit":
+		cc.MemoryLimit, err = toLong(p)
+	case "swapMemoryLimit":
+		cc.SwapMemoryLimit, err = toLong(p)
+	case "hostTotalMemory":
+		cc.HostTotalMemory, err = toLong(p)
+	}
+	return err
+}
+
+func (cc *ContainerConfiguration) Parse(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadata) error {
+	return parseFields(r, classes, cpools, class, nil, true, cc.setField)
+}
+
+type ContainerIOUsage struct {
+	StartTime       int64
+	ServiceRequests int64
+	DataTransferred int64
+}
+
+func (ciu *ContainerIOUsage) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "startTime":
+		ciu.StartTime, err = toLong(p)
+	case "serviceRequests":
+		ciu.ServiceRequests, err = toLong(p)
+	case "dataTransferred":
+		ciu.DataTransferred, err = toLong(p)
+	}
+	return err
+}
+
+func (ciu *ContainerIOUsage) Parse(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadata) error {
+	return parseFields(r, classes, cpools, class, nil, true, ciu.setField)
+}
+
+type ContainerMemoryUsage struct {
+	StartTime       int64
+	MemoryFailCount int64
+	MemoryUsage     int64
+	SwapMemoryUsage int64
+}
+
+func (cmu *ContainerMemoryUsage) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "startTime":
+		cmu.StartTime, err = toLong(p)
+	case "memoryFailCount":
+		cmu.MemoryFailCount, err = toLong(p)
+	case "memoryUsage":
+		cmu.MemoryUsage, err = toLong(p)
+	case "swapMemoryUsage":
+		cmu.SwapMemoryUsage, err = toLong(p)
+	}
+	return err
+}
+
+func (cmu *ContainerMemoryUsage) Parse(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadata) error {
+	return parseFields(r, classes, cpools, class, nil, true, cmu.setField)
+}
+
 type DoubleFlag struct {
 	StartTime int64
 	Name      string
@@ -807,7 +952,7 @@ func (es *ExceptionStatistics) setField(name string, p ParseResolvable) (err err
 		es.EventThread, err = toThread(p)
 	case "stackTrace":
 		es.StackTrace, err = toStackTrace(p)
-	case "throwable":
+	case "throwables":
 		es.Throwable, err = toLong(p)
 	}
 	return err
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+var fuzzOptions = Options{
+	ChunkSizeLimit:    1 << 24,
+	StringLengthLimit: 1 << 16,
+	ArrayLengthLimit:  1 << 16,
+	ConstantPoolLimit: 1 << 20,
+}
+
+func addFuzzRecordings(f *testing.F) {
+	jfr, err := readGzipFile("./testdata/FastSlow_2024_01_16_180855.jfr.gz")
+	if err != nil {
+		f.Fatalf("Unable to read JFR file: %s", err)
+	}
+	// the second chunk is small enough for the fuzzer to mutate quickly
+	chunk := jfr[127450:142496]
+	f.Add(chunk)
+	f.Add(chunk[:10000])
+}
+
+func FuzzParser(f *testing.F) {
+	addFuzzRecordings(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, recover := range []bool{false, true} {
+			options := fuzzOptions
+			options.SymbolProcessor = ProcessSymbols
+			options.Recover = recover
+			p := NewParser(data, options)
+			for {
+				if _, err := p.ParseEvent(); err != nil {
+					break
+				}
+			}
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	addFuzzRecordings(f)
+	for _, tc := range testCases {
+		f.Add(tc.file)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = Parse(bytes.NewReader(data))
+		_, _ = ParseWithOptions(bytes.NewReader(data), &ChunkParseOptions{
+			ChunkSizeLimit:    fuzzOptions.ChunkSizeLimit,
+			StringLengthLimit: fuzzOptions.StringLengthLimit,
+			ArrayLengthLimit:  fuzzOptions.ArrayLengthLimit,
+			ConstantPoolLimit: fuzzOptions.ConstantPoolLimit,
+			Recover:           true,
+		})
+	})
+}
+
+func FuzzDecompress(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.file)
+	}
+	for _, name := range []string{"./testdata/ddtrace.jfr.zip", "./testdata/ddtrace.jfr.lz4"} {
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			f.Fatalf("Unable to read file: %s", err)
+		}
+		f.Add(buf)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rc, err := Decompress(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		defer rc.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(rc, 1<<24))
+	})
+}
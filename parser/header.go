@@ -32,7 +32,7 @@ func (h *Header) Parse(rd Reader) (err error) {
 }
 
 func (p *Parser) readChunkHeader(pos int) error {
-	if p.pos+chunkHeaderSize > len(p.buf) {
+	if pos+chunkHeaderSize > len(p.buf) {
 		return io.ErrUnexpectedEOF
 	}
 
@@ -54,15 +54,26 @@ func (p *Parser) readChunkHeader(pos int) error {
 	if h.Version < 0x20000 || h.Version > 0x2ffff {
 		return fmt.Errorf("unknown version %x", h.Version)
 	}
-	if h.OffsetConstantPool <= 0 || h.OffsetMeta <= 0 {
-		return fmt.Errorf("invalid offsets: cp %d meta %d", h.OffsetConstantPool, h.OffsetMeta)
+	p.unfinished = false
+	if p.options.Recover && (h.Size < chunkHeaderSize || h.Size > len(p.buf)-pos || h.OffsetConstantPool <= 0 || h.OffsetMeta <= 0) {
+		// the chunk was never finalised, its events run until the end of the buffer
+		p.header = h
+		p.chunkEnd = len(p.buf)
+		p.unfinished = true
+		return nil
 	}
-	if h.Size <= 0 {
+	if h.Size < chunkHeaderSize {
 		return fmt.Errorf("invalid size: %d", h.Size)
 	}
 	if p.options.ChunkSizeLimit > 0 && h.Size > p.options.ChunkSizeLimit {
 		return fmt.Errorf("chunk size %d exceeds limit %d", h.Size, p.options.ChunkSizeLimit)
 	}
+	if h.Size > len(p.buf)-pos {
+		return io.ErrUnexpectedEOF
+	}
+	if h.OffsetConstantPool < chunkHeaderSize || h.OffsetConstantPool >= h.Size || h.OffsetMeta < chunkHeaderSize || h.OffsetMeta >= h.Size {
+		return fmt.Errorf("invalid offsets: cp %d meta %d", h.OffsetConstantPool, h.OffsetMeta)
+	}
 	p.header = h
 	p.chunkEnd = pos + h.Size
 	return nil
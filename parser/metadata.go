@@ -109,8 +109,8 @@ func (f *FieldMetadata) TickTimestamp(classMap ClassMap) bool {
 }
 
 func (f *FieldMetadata) resolve(classMap ClassMap) {
-	for _, annotation := range f.Annotations {
-		switch classMap[annotation.ClassID].Name {
+	for _, annotation := range f.AllAnnotations(classMap) {
+		switch annotation.Name {
 		case annotationUnsigned:
 			f.unsigned = utils.NewPointer(true)
 		case annotationMemoryAmount, annotationDataAmount:
@@ -123,6 +123,8 @@ func (f *FieldMetadata) resolve(classMap ClassMap) {
 				f.unit = units.Nanosecond.Derived("tick", units.F64(1e9/float64(f.ChunkHeader.TicksPerSecond)))
 			case unitNS:
 				f.unit = units.Nanosecond
+			case unitUS:
+				f.unit = units.Microsecond
 			case unitMS:
 				f.unit = units.Millisecond
 			case unitS:
@@ -168,23 +170,9 @@ type ClassMetadata struct {
 
 func (c *ClassMetadata) Category() []string {
 	if c.categories == nil {
-		for _, annotation := range c.Annotations {
-			if c.ClassMap[annotation.ClassID].Name == annotationCategory {
-				categories := make([]string, 0, len(annotation.Values))
-				idx := 0
-				for {
-					cat, ok := annotation.Values[fmt.Sprintf("%s-%d", valueProperty, idx)]
-					if !ok {
-						break
-					}
-					categories = append(categories, cat)
-					idx++
-				}
-				c.categories = categories
-			}
-			if c.categories == nil {
-				c.categories = []string{}
-			}
+		c.categories = []string{}
+		if a := c.Annotation(annotationCategory); a != nil {
+			c.categories = a.Array(valueProperty)
 		}
 	}
 	return c.categories
@@ -194,6 +182,58 @@ func (c *ClassMetadata) Label() string {
 	return c.BaseAnnotation.Label(c.ClassMap)
 }
 
+func (c *ClassMetadata) Description() string {
+	return c.BaseAnnotation.Description(c.ClassMap)
+}
+
+func (c *ClassMetadata) Experimental() bool {
+	return c.BaseAnnotation.Experimental(c.ClassMap)
+}
+
+// AllAnnotations returns all the class annotations, including custom ones.
+func (c *ClassMetadata) AllAnnotations() []*Annotation {
+	return c.BaseAnnotation.AllAnnotations(c.ClassMap)
+}
+
+// Annotation returns the class annotation of the given type name, or nil.
+func (c *ClassMetadata) Annotation(name string) *Annotation {
+	return c.BaseAnnotation.Annotation(c.ClassMap, name)
+}
+
+// AnnotatedName returns the value of the jdk.jfr.Name annotation, or "".
+func (c *ClassMetadata) AnnotatedName() string {
+	return c.BaseAnnotation.AnnotatedName(c.ClassMap)
+}
+
+// Enabled returns the default of the enabled setting declared by jdk.jfr.Enabled.
+func (c *ClassMetadata) Enabled() (enabled bool, ok bool) {
+	if a := c.Annotation(annotationEnabled); a != nil {
+		return a.Bool(), true
+	}
+	return false, false
+}
+
+// Threshold returns the default of the threshold setting declared by
+// jdk.jfr.Threshold, such as "20 ms", or "".
+func (c *ClassMetadata) Threshold() string {
+	return c.BaseAnnotation.annotationValue(c.ClassMap, annotationThreshold)
+}
+
+// Period returns the default of the period setting declared by jdk.jfr.Period,
+// such as "everyChunk", or "".
+func (c *ClassMetadata) Period() string {
+	return c.BaseAnnotation.annotationValue(c.ClassMap, annotationPeriod)
+}
+
+// StackTrace returns the default of the stackTrace setting declared by
+// jdk.jfr.StackTrace.
+func (c *ClassMetadata) StackTrace() (enabled bool, ok bool) {
+	if a := c.Annotation(annotationStackTrace); a != nil {
+		return a.Bool(), true
+	}
+	return false, false
+}
+
 func (c *ClassMetadata) Unit(fieldName string) *units.Unit {
 	fieldMeta := c.GetField(fieldName)
 	if fieldMeta == nil {
@@ -368,7 +408,9 @@ func (m *ChunkMetadata) Parse(r Reader) (err error) {
 	if err != nil {
 		return fmt.Errorf("unable to parse metadata event's number of strings: %w", err)
 	}
-	// TODO: assert n is small enough
+	if err := checkLength(r, n, readerLimits(r).ArrayLength, "metadata strings"); err != nil {
+		return fmt.Errorf("invalid metadata event's number of strings: %w", err)
+	}
 	strings := make([]string, n)
 	for i := 0; i < int(n); i++ {
 		if x, err := r.String(); err != nil {
@@ -390,9 +432,52 @@ func (m *ChunkMetadata) Parse(r Reader) (err error) {
 	if err = parseElement(r, strings, m.Header, m.Root); err != nil {
 		return fmt.Errorf("unable to parse metadata element tree: %w", err)
 	}
+	if m.Root.Metadata == nil {
+		return fmt.Errorf("missing metadata element")
+	}
 
 	m.buildClassMap()
 
+	return m.checkInlineCycles()
+}
+
+// checkInlineCycles rejects classes containing themselves through fields which
+// are not constant pool references, they can't be parsed without unbounded recursion.
+func (m *ChunkMetadata) checkInlineCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[int64]int, len(m.ClassMap))
+	var visit func(id int64) error
+	visit = func(id int64) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("class %d contains itself", id)
+		case done:
+			return nil
+		}
+		class, ok := m.ClassMap[id]
+		if !ok {
+			return nil
+		}
+		state[id] = visiting
+		for _, f := range class.Fields {
+			if f.ConstantPool {
+				continue
+			}
+			if err := visit(f.ClassID); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		return nil
+	}
+	for id := range m.ClassMap {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -428,6 +513,9 @@ func (p *Parser) readMeta(pos int) error {
 	if err != nil {
 		return err
 	}
+	if err := p.checkLength(int(nstr), p.options.ArrayLengthLimit, "metadata strings"); err != nil {
+		return err
+	}
 	strings := make([]string, nstr)
 	for i := 0; i < int(nstr); i++ {
 		strings[i], err = p.string()
@@ -525,7 +613,9 @@ func parseElement(r Reader, s []string, chunkHeader *Header, e Element) error {
 	if err != nil {
 		return fmt.Errorf("unable to parse element count: %w", err)
 	}
-	// TODO: assert n is small enough
+	if err := checkLength(r, n, 0, "metadata elements"); err != nil {
+		return fmt.Errorf("invalid element count: %w", err)
+	}
 	for i := 0; i < int(n); i++ {
 		name, err := parseName(r, s)
 		if err != nil {
@@ -555,6 +645,9 @@ func (p *Parser) readElement(strings []string, needAttributes bool) (element, er
 	if err != nil {
 		return element{}, err
 	}
+	if err := p.checkLength(int(attributeCount), p.options.ArrayLengthLimit, "metadata attributes"); err != nil {
+		return element{}, err
+	}
 	var attributes map[string]string
 	if needAttributes {
 		attributes = make(map[string]string, attributeCount)
@@ -585,6 +678,9 @@ func (p *Parser) readElement(strings []string, needAttributes bool) (element, er
 	if err != nil {
 		return element{}, err
 	}
+	if err := p.checkLength(int(childCount), p.options.ArrayLengthLimit, "metadata elements"); err != nil {
+		return element{}, err
+	}
 	return element{
 		name:       name,
 		attr:       attributes,
@@ -598,7 +694,7 @@ func parseName(r Reader, s []string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("unable to parse string name index: %w", err)
 	}
-	if int(n) >= len(s) {
+	if n < 0 || int(n) >= len(s) {
 		return "", fmt.Errorf("invalid name index %d, only %d names available", n, len(s))
 	}
 	return s[int(n)], nil
@@ -16,3 +16,106 @@ func TestClassMetadata_Category(t *testing.T) {
 	}
 
 }
+
+func TestClassMetadata_Annotations(t *testing.T) {
+	chunks, err := ParseFile("./testdata/FastSlow_2024_01_16_180855.jfr.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classMap := chunks[0].Metadata.ClassMap
+	var sample, park *ClassMetadata
+	for _, class := range classMap {
+		switch class.Name {
+		case "jdk.ExecutionSample":
+			sample = class
+		case "jdk.ThreadPark":
+			park = class
+		}
+	}
+	if sample == nil || park == nil {
+		t.Fatal("missing event types")
+	}
+
+	if cat := sample.Category(); len(cat) != 2 || cat[0] != "Java Virtual Machine" || cat[1] != "Profiling" {
+		t.Errorf("unexpected category %v", cat)
+	}
+	if label := sample.Label(); label != "Method Profiling Sample" {
+		t.Errorf("unexpected label %q", label)
+	}
+	if sample.Experimental() {
+		t.Errorf("jdk.ExecutionSample is not experimental")
+	}
+	address := park.GetField("address")
+	if !address.MemoryAddress(classMap) || !address.Unsigned(classMap) {
+		t.Errorf("expected an unsigned memory address")
+	}
+	if ct := park.GetField("timeout").ContentType(classMap); ct != "jdk.jfr.Timespan" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
+
+func TestClassMetadata_SyntheticAnnotations(t *testing.T) {
+	classMap := ClassMap{}
+	annotationType := func(id int64, name string, meta ...int64) {
+		class := &ClassMetadata{ID: id, Name: name, SuperType: "java.lang.annotation.Annotation", ClassMap: classMap}
+		for _, m := range meta {
+			class.ClassAnnotation.Annotations = append(class.ClassAnnotation.Annotations, &AnnotationMetadata{ClassID: m})
+		}
+		classMap[id] = class
+	}
+	annotationType(1, annotationName)
+	annotationType(2, annotationEnabled)
+	annotationType(3, annotationThreshold)
+	annotationType(4, annotationPeriod)
+	annotationType(5, annotationRelational)
+	annotationType(6, "jdk.jfr.events.ThreadId", 5)
+	annotationType(7, annotationTransitionFrom)
+	annotationType(8, annotationExperimental)
+	annotationType(9, "com.example.Team")
+
+	class := &ClassMetadata{ID: 100, Name: "com.example.Event", ClassMap: classMap}
+	class.ClassAnnotation.Annotations = []*AnnotationMetadata{
+		{ClassID: 1, Values: map[string]string{"value": "example.Event"}},
+		{ClassID: 2, Values: map[string]string{"value": "false"}},
+		{ClassID: 3, Values: map[string]string{"value": "20 ms"}},
+		{ClassID: 4, Values: map[string]string{"value": "everyChunk"}},
+		{ClassID: 8},
+		{ClassID: 9, Values: map[string]string{"value": "profiling"}},
+	}
+	field := &FieldMetadata{Name: "fromThread"}
+	field.Annotations = []*AnnotationMetadata{{ClassID: 6}, {ClassID: 7}}
+	class.Fields = []*FieldMetadata{field}
+	classMap[100] = class
+
+	if name := class.AnnotatedName(); name != "example.Event" {
+		t.Errorf("unexpected name %q", name)
+	}
+	if enabled, ok := class.Enabled(); enabled || !ok {
+		t.Errorf("expected enabled to default to false")
+	}
+	if threshold := class.Threshold(); threshold != "20 ms" {
+		t.Errorf("unexpected threshold %q", threshold)
+	}
+	if period := class.Period(); period != "everyChunk" {
+		t.Errorf("unexpected period %q", period)
+	}
+	if _, ok := class.StackTrace(); ok {
+		t.Errorf("unexpected stackTrace default")
+	}
+	if !class.Experimental() {
+		t.Errorf("expected an experimental event")
+	}
+	if a := class.Annotation("com.example.Team"); a == nil || a.Value() != "profiling" {
+		t.Errorf("custom annotation not found")
+	}
+	if len(class.AllAnnotations()) != 6 {
+		t.Errorf("expected 6 annotations, got %d", len(class.AllAnnotations()))
+	}
+	f := class.GetField("fromThread")
+	if rel := f.Relation(classMap); rel != "jdk.jfr.events.ThreadId" {
+		t.Errorf("unexpected relation %q", rel)
+	}
+	if !f.TransitionFrom(classMap) || f.TransitionTo(classMap) {
+		t.Errorf("expected a transition from field")
+	}
+}
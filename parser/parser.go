@@ -2,9 +2,12 @@ package parser
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"time"
 	"unsafe"
 
 	types2 "github.com/grafana/jfr-parser/parser/types"
@@ -31,16 +34,27 @@ func Parse(r io.Reader) ([]*Chunk, error) {
 
 func ParseWithOptions(r io.Reader, options *ChunkParseOptions) ([]*Chunk, error) {
 	var chunks []*Chunk
+	var prev *Chunk
 	for {
 		chunk := new(Chunk)
-		err := chunk.Parse(r, options)
+		err := chunk.parse(r, options, prev)
 		if err == io.EOF {
 			return chunks, nil
 		}
+		var recovered *RecoveredError
+		if errors.As(err, &recovered) {
+			// the events of the unfinished chunk read until the error are kept
+			return append(chunks, chunk), err
+		}
 		if err != nil {
-			return chunks, fmt.Errorf("unable to parse chunk: %w", err)
+			err = fmt.Errorf("unable to parse chunk: %w", err)
+			if options.Recover && len(chunks) > 0 && errors.Is(err, io.ErrUnexpectedEOF) {
+				return chunks, &RecoveredError{Err: err}
+			}
+			return chunks, err
 		}
 		chunks = append(chunks, chunk)
+		prev = chunk
 	}
 }
 
@@ -61,6 +75,36 @@ type ChunkHeader struct {
 	Features           uint32
 }
 
+// ticksToNanos converts a timestamp in ticks of the chunk to nanoseconds since epoch.
+func (c *ChunkHeader) ticksToNanos(ticks uint64) int64 {
+	if c.TicksPerSecond == 0 {
+		return int64(c.StartNanos)
+	}
+	d := int64(ticks - c.StartTicks)
+	tps := int64(c.TicksPerSecond)
+	return int64(c.StartNanos) + d/tps*1e9 + d%tps*1e9/tps
+}
+
+// nanosToTicks converts nanoseconds since epoch to ticks of the chunk, clamped
+// to the range of uint64.
+func (c *ChunkHeader) nanosToTicks(nanos int64) uint64 {
+	if c.TicksPerSecond == 0 {
+		return c.StartTicks
+	}
+	d := nanos - int64(c.StartNanos)
+	tps := int64(c.TicksPerSecond)
+	if s := d / 1e9; s > math.MaxInt64/tps/2 {
+		return math.MaxUint64
+	} else if s < -math.MaxInt64/tps/2 {
+		return 0
+	}
+	t := int64(c.StartTicks) + d/1e9*tps + d%1e9*tps/1e9
+	if t < 0 {
+		return 0
+	}
+	return uint64(t)
+}
+
 func (c *ChunkHeader) String() string {
 	return fmt.Sprintf("ChunkHeader{Magic: %x, Version: %x, Size: %d, OffsetConstantPool: %d, OffsetMeta: %d, StartNanos: %d, DurationNanos: %d, StartTicks: %d, TicksPerSecond: %d, Features: %d}", c.Magic, c.Version, c.Size, c.OffsetConstantPool, c.OffsetMeta, c.StartNanos, c.DurationNanos, c.StartTicks, c.TicksPerSecond, c.Features)
 }
@@ -70,6 +114,36 @@ type SymbolProcessor func(ref *types2.SymbolList)
 type Options struct {
 	ChunkSizeLimit  int
 	SymbolProcessor SymbolProcessor
+
+	// Limits on lengths read from the recording, turning hostile input into
+	// errors rather than huge allocations. Zero means no limit besides the size
+	// of the input itself.
+	StringLengthLimit int
+	ArrayLengthLimit  int
+	ConstantPoolLimit int
+
+	// Recover makes the parser lenient towards truncated or partially corrupt
+	// recordings, e.g. the last chunk written by a crashed JVM. Events of all
+	// complete chunks are returned, a chunk which was never finalised is scanned
+	// up to the end of the buffer, reusing the metadata and constant pools of the
+	// previous chunk when its own are missing. The first error is then reported
+	// by Parser.RecoveredError and ParseEvent returns io.EOF. Errors before the
+	// first valid chunk header, such as input which is not a recording, are
+	// still returned by ParseEvent.
+	Recover bool
+
+	// Events starting before WindowStart or from WindowEnd on are skipped
+	// without being decoded, a zero time leaves the window open on that side.
+	WindowStart time.Time
+	WindowEnd   time.Time
+	// ThreadFilter, when set, skips the events of threads it returns false for,
+	// by Java name, OS thread ID, virtual flag... The thread is nil when the
+	// constant pool does not contain it. Decisions are cached per chunk.
+	//
+	// Active settings and Datadog endpoints, which describe other events, are
+	// never skipped, by the window nor the thread filter. Exception statistics
+	// count the throwables of the whole JVM, only the window applies to them.
+	ThreadFilter func(thread *types2.Thread) bool
 }
 
 type Parser struct {
@@ -83,14 +157,56 @@ type Parser struct {
 	LogLevels    types2.LogLevelList
 	Stacktrace   types2.StackTraceList
 
+	// constant pools of the reference chains of old object samples
+	OldObjects           types2.OldObjectList
+	References           types2.ReferenceList
+	OldObjectArrays      types2.OldObjectArrayList
+	OldObjectFields      types2.OldObjectFieldList
+	OldObjectGcRoots     types2.OldObjectGcRootList
+	OldObjectRootSystems types2.OldObjectRootSystemList
+	OldObjectRootTypes   types2.OldObjectRootTypeList
+
 	ExecutionSample             types2.ExecutionSample
 	ObjectAllocationInNewTLAB   types2.ObjectAllocationInNewTLAB
 	ObjectAllocationOutsideTLAB types2.ObjectAllocationOutsideTLAB
 	JavaMonitorEnter            types2.JavaMonitorEnter
 	ThreadPark                  types2.ThreadPark
 	LiveObject                  types2.LiveObject
+	Malloc                      types2.Malloc
+	Free                        types2.Free
 	ActiveSetting               types2.ActiveSetting
 
+	DatadogExecutionSample types2.DatadogExecutionSample
+	DatadogMethodSample    types2.DatadogMethodSample
+	DatadogObjectSample    types2.DatadogObjectSample
+	DatadogHeapLiveObject  types2.DatadogHeapLiveObject
+	DatadogExceptionSample types2.DatadogExceptionSample
+	DatadogEndpoint        types2.DatadogEndpoint
+
+	JavaExceptionThrow  types2.JavaExceptionThrow
+	JavaErrorThrow      types2.JavaErrorThrow
+	ExceptionStatistics types2.ExceptionStatistics
+
+	FileRead    types2.FileRead
+	FileWrite   types2.FileWrite
+	SocketRead  types2.SocketRead
+	SocketWrite types2.SocketWrite
+
+	JavaMonitorWait types2.JavaMonitorWait
+	ThreadSleep     types2.ThreadSleep
+	ClassLoad       types2.ClassLoad
+
+	OldObjectSample types2.OldObjectSample
+
+	VirtualThreadPinned       types2.VirtualThreadPinned
+	VirtualThreadSubmitFailed types2.VirtualThreadSubmitFailed
+
+	ContainerConfiguration types2.ContainerConfiguration
+	ContainerCPUUsage      types2.ContainerCPUUsage
+	ContainerCPUThrottling types2.ContainerCPUThrottling
+	ContainerMemoryUsage   types2.ContainerMemoryUsage
+	ContainerIOUsage       types2.ContainerIOUsage
+
 	header   ChunkHeader
 	options  Options
 	buf      []byte
@@ -98,6 +214,13 @@ type Parser struct {
 	metaSize uint32
 	chunkEnd int
 
+	unfinished   bool
+	recoveredErr error
+
+	windowStartTicks uint64
+	windowEndTicks   uint64
+	threadAllowed    map[types2.ThreadRef]bool
+
 	TypeMap def.TypeMap
 
 	bindFrameType   *types2.BindFrameType
@@ -111,6 +234,14 @@ type Parser struct {
 	bindStackFrame  *types2.BindStackFrame
 	bindStackTrace  *types2.BindStackTrace
 
+	bindOldObject           *types2.BindOldObject
+	bindReference           *types2.BindReference
+	bindOldObjectArray      *types2.BindOldObjectArray
+	bindOldObjectField      *types2.BindOldObjectField
+	bindOldObjectGcRoot     *types2.BindOldObjectGcRoot
+	bindOldObjectRootSystem *types2.BindOldObjectRootSystem
+	bindOldObjectRootType   *types2.BindOldObjectRootType
+
 	bindExecutionSample *types2.BindExecutionSample
 
 	bindAllocInNewTLAB   *types2.BindObjectAllocationInNewTLAB
@@ -118,7 +249,40 @@ type Parser struct {
 	bindMonitorEnter     *types2.BindJavaMonitorEnter
 	bindThreadPark       *types2.BindThreadPark
 	bindLiveObject       *types2.BindLiveObject
+	bindMalloc           *types2.BindMalloc
+	bindFree             *types2.BindFree
 	bindActiveSetting    *types2.BindActiveSetting
+
+	bindDatadogExecutionSample *types2.BindDatadogExecutionSample
+	bindDatadogMethodSample    *types2.BindDatadogMethodSample
+	bindDatadogObjectSample    *types2.BindDatadogObjectSample
+	bindDatadogHeapLiveObject  *types2.BindDatadogHeapLiveObject
+	bindDatadogExceptionSample *types2.BindDatadogExceptionSample
+	bindDatadogEndpoint        *types2.BindDatadogEndpoint
+
+	bindJavaExceptionThrow  *types2.BindJavaExceptionThrow
+	bindJavaErrorThrow      *types2.BindJavaErrorThrow
+	bindExceptionStatistics *types2.BindExceptionStatistics
+
+	bindFileRead    *types2.BindFileRead
+	bindFileWrite   *types2.BindFileWrite
+	bindSocketRead  *types2.BindSocketRead
+	bindSocketWrite *types2.BindSocketWrite
+
+	bindJavaMonitorWait *types2.BindJavaMonitorWait
+	bindThreadSleep     *types2.BindThreadSleep
+	bindClassLoad       *types2.BindClassLoad
+
+	bindOldObjectSample *types2.BindOldObjectSample
+
+	bindVirtualThreadPinned       *types2.BindVirtualThreadPinned
+	bindVirtualThreadSubmitFailed *types2.BindVirtualThreadSubmitFailed
+
+	bindContainerConfiguration *types2.BindContainerConfiguration
+	bindContainerCPUUsage      *types2.BindContainerCPUUsage
+	bindContainerCPUThrottling *types2.BindContainerCPUThrottling
+	bindContainerMemoryUsage   *types2.BindContainerMemoryUsage
+	bindContainerIOUsage       *types2.BindContainerIOUsage
 }
 
 func NewParser(buf []byte, options Options) *Parser {
@@ -126,10 +290,32 @@ func NewParser(buf []byte, options Options) *Parser {
 		options: options,
 		buf:     buf,
 	}
+	p.TypeMap.Limits = def.Limits{
+		StringLength:        options.StringLengthLimit,
+		ArrayLength:         options.ArrayLengthLimit,
+		ConstantPoolEntries: options.ConstantPoolLimit,
+	}
 	return p
 }
 
 func (p *Parser) ParseEvent() (def.TypeID, error) {
+	typ, err := p.parseEvent()
+	if err != nil && err != io.EOF && p.options.Recover && p.header.Magic == chunkMagic {
+		p.recoveredErr = err
+		p.pos = len(p.buf)
+		p.chunkEnd = len(p.buf)
+		return 0, io.EOF
+	}
+	return typ, err
+}
+
+// RecoveredError returns the error which ended parsing in recovery mode, or nil
+// if the recording was read completely.
+func (p *Parser) RecoveredError() error {
+	return p.recoveredErr
+}
+
+func (p *Parser) parseEvent() (def.TypeID, error) {
 	for {
 		if p.pos == p.chunkEnd {
 			if p.pos == len(p.buf) {
@@ -138,6 +324,7 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 			if err := p.readChunk(p.pos); err != nil {
 				return 0, err
 			}
+			p.resetFilters()
 		}
 		pp := p.pos
 		size, err := p.varLong()
@@ -147,6 +334,9 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 		if size == 0 {
 			return 0, def.ErrIntOverflow
 		}
+		if size > uint64(p.chunkEnd-pp) {
+			return 0, io.ErrUnexpectedEOF
+		}
 		typ, err := p.varLong()
 		if err != nil {
 			return 0, err
@@ -156,7 +346,7 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 		ttyp := def.TypeID(typ)
 		switch ttyp {
 		case p.TypeMap.T_EXECUTION_SAMPLE:
-			if p.bindExecutionSample == nil {
+			if p.bindExecutionSample == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -165,9 +355,12 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ExecutionSample.SampledThread) {
+				continue
+			}
 			return ttyp, nil
 		case p.TypeMap.T_ALLOC_IN_NEW_TLAB:
-			if p.bindAllocInNewTLAB == nil {
+			if p.bindAllocInNewTLAB == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -176,9 +369,12 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ObjectAllocationInNewTLAB.EventThread) {
+				continue
+			}
 			return ttyp, nil
 		case p.TypeMap.T_ALLOC_OUTSIDE_TLAB:
-			if p.bindAllocOutsideTLAB == nil {
+			if p.bindAllocOutsideTLAB == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -187,9 +383,12 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ObjectAllocationOutsideTLAB.EventThread) {
+				continue
+			}
 			return ttyp, nil
 		case p.TypeMap.T_LIVE_OBJECT:
-			if p.bindLiveObject == nil {
+			if p.bindLiveObject == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -198,9 +397,40 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.LiveObject.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_MALLOC:
+			if p.bindMalloc == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.Malloc.Parse(p.buf[p.pos:], p.bindMalloc, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.Malloc.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_FREE:
+			if p.bindFree == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.Free.Parse(p.buf[p.pos:], p.bindFree, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.Free.EventThread) {
+				continue
+			}
 			return ttyp, nil
 		case p.TypeMap.T_MONITOR_ENTER:
-			if p.bindMonitorEnter == nil {
+			if p.bindMonitorEnter == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -209,9 +439,12 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.JavaMonitorEnter.EventThread) {
+				continue
+			}
 			return ttyp, nil
 		case p.TypeMap.T_THREAD_PARK:
-			if p.bindThreadPark == nil {
+			if p.bindThreadPark == nil || p.outsideWindow(ttyp) {
 				p.pos = pp + int(size) // skip
 				continue
 			}
@@ -220,6 +453,313 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 				return 0, err
 			}
 			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ThreadPark.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_DD_EXECUTION_SAMPLE:
+			if p.bindDatadogExecutionSample == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogExecutionSample.Parse(p.buf[p.pos:], p.bindDatadogExecutionSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.DatadogExecutionSample.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_DD_METHOD_SAMPLE:
+			if p.bindDatadogMethodSample == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogMethodSample.Parse(p.buf[p.pos:], p.bindDatadogMethodSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.DatadogMethodSample.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_DD_OBJECT_SAMPLE:
+			if p.bindDatadogObjectSample == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogObjectSample.Parse(p.buf[p.pos:], p.bindDatadogObjectSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.DatadogObjectSample.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_DD_HEAP_LIVE_OBJECT:
+			if p.bindDatadogHeapLiveObject == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogHeapLiveObject.Parse(p.buf[p.pos:], p.bindDatadogHeapLiveObject, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.DatadogHeapLiveObject.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_DD_EXCEPTION_SAMPLE:
+			if p.bindDatadogExceptionSample == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogExceptionSample.Parse(p.buf[p.pos:], p.bindDatadogExceptionSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.DatadogExceptionSample.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_EXCEPTION_THROW:
+			if p.bindJavaExceptionThrow == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JavaExceptionThrow.Parse(p.buf[p.pos:], p.bindJavaExceptionThrow, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.JavaExceptionThrow.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_ERROR_THROW:
+			if p.bindJavaErrorThrow == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JavaErrorThrow.Parse(p.buf[p.pos:], p.bindJavaErrorThrow, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.JavaErrorThrow.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_FILE_READ:
+			if p.bindFileRead == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.FileRead.Parse(p.buf[p.pos:], p.bindFileRead, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.FileRead.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_FILE_WRITE:
+			if p.bindFileWrite == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.FileWrite.Parse(p.buf[p.pos:], p.bindFileWrite, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.FileWrite.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_SOCKET_READ:
+			if p.bindSocketRead == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SocketRead.Parse(p.buf[p.pos:], p.bindSocketRead, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.SocketRead.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_SOCKET_WRITE:
+			if p.bindSocketWrite == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.SocketWrite.Parse(p.buf[p.pos:], p.bindSocketWrite, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.SocketWrite.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_MONITOR_WAIT:
+			if p.bindJavaMonitorWait == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.JavaMonitorWait.Parse(p.buf[p.pos:], p.bindJavaMonitorWait, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.JavaMonitorWait.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_THREAD_SLEEP:
+			if p.bindThreadSleep == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ThreadSleep.Parse(p.buf[p.pos:], p.bindThreadSleep, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ThreadSleep.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_CLASS_LOAD:
+			if p.bindClassLoad == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ClassLoad.Parse(p.buf[p.pos:], p.bindClassLoad, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.ClassLoad.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_OLD_OBJECT_SAMPLE:
+			if p.bindOldObjectSample == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.OldObjectSample.Parse(p.buf[p.pos:], p.bindOldObjectSample, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.OldObjectSample.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_PINNED:
+			if p.bindVirtualThreadPinned == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadPinned.Parse(p.buf[p.pos:], p.bindVirtualThreadPinned, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.VirtualThreadPinned.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED:
+			if p.bindVirtualThreadSubmitFailed == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.VirtualThreadSubmitFailed.Parse(p.buf[p.pos:], p.bindVirtualThreadSubmitFailed, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			if !p.threadAccepted(p.VirtualThreadSubmitFailed.EventThread) {
+				continue
+			}
+			return ttyp, nil
+		case p.TypeMap.T_CONTAINER_CONFIGURATION:
+			if p.bindContainerConfiguration == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ContainerConfiguration.Parse(p.buf[p.pos:], p.bindContainerConfiguration, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CONTAINER_CPU_USAGE:
+			if p.bindContainerCPUUsage == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ContainerCPUUsage.Parse(p.buf[p.pos:], p.bindContainerCPUUsage, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CONTAINER_CPU_THROTTLING:
+			if p.bindContainerCPUThrottling == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ContainerCPUThrottling.Parse(p.buf[p.pos:], p.bindContainerCPUThrottling, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CONTAINER_MEMORY_USAGE:
+			if p.bindContainerMemoryUsage == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ContainerMemoryUsage.Parse(p.buf[p.pos:], p.bindContainerMemoryUsage, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_CONTAINER_IO_USAGE:
+			if p.bindContainerIOUsage == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ContainerIOUsage.Parse(p.buf[p.pos:], p.bindContainerIOUsage, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
+		case p.TypeMap.T_EXCEPTION_STATISTICS:
+			if p.bindExceptionStatistics == nil || p.outsideWindow(ttyp) {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.ExceptionStatistics.Parse(p.buf[p.pos:], p.bindExceptionStatistics, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
 			return ttyp, nil
 
 		case p.TypeMap.T_ACTIVE_SETTING:
@@ -233,6 +773,17 @@ func (p *Parser) ParseEvent() (def.TypeID, error) {
 			}
 			p.pos = pp + int(size)
 			return ttyp, nil
+		case p.TypeMap.T_DD_ENDPOINT:
+			if p.bindDatadogEndpoint == nil {
+				p.pos = pp + int(size) // skip
+				continue
+			}
+			_, err := p.DatadogEndpoint.Parse(p.buf[p.pos:], p.bindDatadogEndpoint, &p.TypeMap)
+			if err != nil {
+				return 0, err
+			}
+			p.pos = pp + int(size)
+			return ttyp, nil
 		default:
 			//fmt.Printf("skipping %s %v\n", def.TypeID2Sym(ttyp), ttyp)
 			p.pos = pp + int(size)
@@ -244,6 +795,76 @@ func (p *Parser) ChunkHeader() ChunkHeader {
 	return p.header
 }
 
+// TicksToTime converts an event timestamp of the current chunk, such as
+// StartTime, to wall-clock time.
+func (p *Parser) TicksToTime(ticks uint64) time.Time {
+	return time.Unix(0, p.header.ticksToNanos(ticks))
+}
+
+// TicksToDuration converts an event duration of the current chunk.
+func (p *Parser) TicksToDuration(ticks uint64) time.Duration {
+	tps := p.header.TicksPerSecond
+	if tps == 0 {
+		return 0
+	}
+	return time.Duration(ticks/tps*1e9 + ticks%tps*1e9/tps)
+}
+
+// resetFilters prepares the window and thread filter for a new chunk.
+func (p *Parser) resetFilters() {
+	p.windowStartTicks = 0
+	p.windowEndTicks = math.MaxUint64
+	if !p.options.WindowStart.IsZero() {
+		p.windowStartTicks = p.header.nanosToTicks(p.options.WindowStart.UnixNano())
+	}
+	if !p.options.WindowEnd.IsZero() {
+		p.windowEndTicks = p.header.nanosToTicks(p.options.WindowEnd.UnixNano())
+	}
+	p.threadAllowed = nil
+}
+
+// outsideWindow peeks at the start time of the event, the first field of the
+// events which have one.
+func (p *Parser) outsideWindow(typ def.TypeID) bool {
+	if p.options.WindowStart.IsZero() && p.options.WindowEnd.IsZero() {
+		return false
+	}
+	c := p.TypeMap.IDMap[typ]
+	if c == nil || len(c.Fields) == 0 || c.Fields[0].Name != "startTime" {
+		return false
+	}
+	pos := p.pos
+	ts, err := p.varLong()
+	p.pos = pos
+	if err != nil {
+		return false // reported when decoding the event
+	}
+	return ts < p.windowStartTicks || ts >= p.windowEndTicks
+}
+
+func (p *Parser) threadAccepted(ref types2.ThreadRef) bool {
+	if p.options.ThreadFilter == nil {
+		return true
+	}
+	if p.threadAllowed == nil {
+		p.threadAllowed = make(map[types2.ThreadRef]bool)
+	}
+	allowed, ok := p.threadAllowed[ref]
+	if !ok {
+		allowed = p.options.ThreadFilter(p.GetThread(ref))
+		p.threadAllowed[ref] = allowed
+	}
+	return allowed
+}
+
+func (p *Parser) GetThread(ref types2.ThreadRef) *types2.Thread {
+	idx, ok := p.Threads.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.Threads.Thread[idx]
+}
+
 func (p *Parser) GetStacktrace(stID types2.StackTraceRef) *types2.StackTrace {
 	idx, ok := p.Stacktrace.IDMap[stID]
 	if !ok {
@@ -287,6 +908,62 @@ func (p *Parser) GetClass(cID types2.ClassRef) *types2.Class {
 	return &p.Classes.Class[idx]
 }
 
+func (p *Parser) GetOldObject(ref types2.OldObjectRef) *types2.OldObject {
+	idx, ok := p.OldObjects.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjects.OldObject[idx]
+}
+
+func (p *Parser) GetReference(ref types2.ReferenceRef) *types2.Reference {
+	idx, ok := p.References.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.References.Reference[idx]
+}
+
+func (p *Parser) GetOldObjectArray(ref types2.OldObjectArrayRef) *types2.OldObjectArray {
+	idx, ok := p.OldObjectArrays.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjectArrays.OldObjectArray[idx]
+}
+
+func (p *Parser) GetOldObjectField(ref types2.OldObjectFieldRef) *types2.OldObjectField {
+	idx, ok := p.OldObjectFields.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjectFields.OldObjectField[idx]
+}
+
+func (p *Parser) GetOldObjectGcRoot(ref types2.OldObjectGcRootRef) *types2.OldObjectGcRoot {
+	idx, ok := p.OldObjectGcRoots.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjectGcRoots.OldObjectGcRoot[idx]
+}
+
+func (p *Parser) GetOldObjectRootSystem(ref types2.OldObjectRootSystemRef) *types2.OldObjectRootSystem {
+	idx, ok := p.OldObjectRootSystems.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjectRootSystems.OldObjectRootSystem[idx]
+}
+
+func (p *Parser) GetOldObjectRootType(ref types2.OldObjectRootTypeRef) *types2.OldObjectRootType {
+	idx, ok := p.OldObjectRootTypes.IDMap[ref]
+	if !ok {
+		return nil
+	}
+	return &p.OldObjectRootTypes.OldObjectRootType[idx]
+}
+
 func (p *Parser) GetSymbol(sID types2.SymbolRef) *types2.Symbol {
 	idx, ok := p.Symbols.IDMap[sID]
 	if !ok {
@@ -307,6 +984,9 @@ func (p *Parser) readChunk(pos int) error {
 	if err := p.readChunkHeader(pos); err != nil {
 		return fmt.Errorf("error reading chunk header: %w", err)
 	}
+	if p.unfinished {
+		return p.readUnfinishedChunk(pos)
+	}
 
 	if err := p.readMeta(pos + p.header.OffsetMeta); err != nil {
 		return fmt.Errorf("error reading metadata: %w", err)
@@ -322,8 +1002,39 @@ func (p *Parser) readChunk(pos int) error {
 	return nil
 }
 
+// readUnfinishedChunk prepares a chunk the JVM never finalised. Its own metadata
+// and constant pools are read when the header already points at them, otherwise
+// the ones of the previous chunk are kept.
+func (p *Parser) readUnfinishedChunk(pos int) error {
+	size := p.chunkEnd - pos
+	if p.header.OffsetMeta >= chunkHeaderSize && p.header.OffsetMeta < size &&
+		p.header.OffsetConstantPool >= chunkHeaderSize && p.header.OffsetConstantPool < size {
+		// reading replaces rather than mutates the type map, binds and lists,
+		// so a shallow copy is enough to fall back to the previous chunk
+		prev := *p
+		err := p.readMeta(pos + p.header.OffsetMeta)
+		if err == nil {
+			err = p.readConstantPool(pos + p.header.OffsetConstantPool)
+		}
+		if err == nil {
+			pp := p.options.SymbolProcessor
+			if pp != nil {
+				pp(&p.Symbols)
+			}
+		} else if prev.TypeMap.IDMap == nil {
+			return fmt.Errorf("error reading unfinished chunk @ %d: %w", pos, err)
+		} else {
+			*p = prev
+		}
+	} else if p.TypeMap.IDMap == nil {
+		return fmt.Errorf("unfinished chunk @ %d without metadata", pos)
+	}
+	p.pos = pos + chunkHeaderSize
+	return nil
+}
+
 func (p *Parser) seek(pos int) error {
-	if pos < len(p.buf) {
+	if pos >= 0 && pos < len(p.buf) {
 		p.pos = pos
 		return nil
 	}
@@ -378,30 +1089,47 @@ func (p *Parser) varLong() (uint64, error) {
 	return v64_, nil
 }
 
+// string reads a string in any of the JFR encodings. Null is read as
+// def.Null, an empty string def.IsNull tells apart.
 func (p *Parser) string() (string, error) {
 	if p.pos >= len(p.buf) {
 		return "", io.ErrUnexpectedEOF
 	}
 	b := p.buf[p.pos]
 	p.pos++
-	switch b { //todo implement 2
-	case 0:
-		return "", nil //todo this should be nil
-	case 1:
+	switch b {
+	case StringEncodingNull:
+		return def.Null, nil
+	case StringEncodingEmptyString:
 		return "", nil
-	case 3:
+	case StringEncodingConstantPool:
+		idx, err := p.varLong()
+		if err != nil {
+			return "", err
+		}
+		s, ok := p.TypeMap.Strings[idx]
+		if !ok {
+			return "", fmt.Errorf("string constant %d not found", idx)
+		}
+		return s, nil
+	case StringEncodingUtf8ByteArray:
 		bs, err := p.bytes()
 		if err != nil {
 			return "", err
 		}
 		str := *(*string)(unsafe.Pointer(&bs))
 		return str, nil
-	case 4:
+	case StringEncodingCharArray:
 		return p.charArrayString()
+	case StringEncodingLatin1ByteArray:
+		bs, err := p.bytes()
+		if err != nil {
+			return "", err
+		}
+		return def.DecodeLatin1(bs), nil
 	default:
 		return "", fmt.Errorf("unknown string type %d", b)
 	}
-
 }
 
 func (p *Parser) charArrayString() (string, error) {
@@ -409,20 +1137,15 @@ func (p *Parser) charArrayString() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if l < 0 {
-		return "", def.ErrIntOverflow
+	if err := p.checkLength(int(l), p.options.StringLengthLimit, "string length"); err != nil {
+		return "", err
 	}
-	buf := make([]rune, int(l))
-	for i := 0; i < int(l); i++ {
-		c, err := p.varInt()
-		if err != nil {
-			return "", err
-		}
-		buf[i] = rune(c)
+	s, pos, err := def.DecodeCharArray(p.buf, p.pos, int(l))
+	if err != nil {
+		return "", err
 	}
-
-	res := string(buf)
-	return res, nil
+	p.pos = pos
+	return s, nil
 }
 
 func (p *Parser) bytes() ([]byte, error) {
@@ -430,17 +1153,35 @@ func (p *Parser) bytes() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	if l < 0 {
-		return nil, def.ErrIntOverflow
-	}
-	if p.pos+int(l) > len(p.buf) {
-		return nil, io.ErrUnexpectedEOF
+	if err := p.checkLength(int(l), p.options.StringLengthLimit, "string length"); err != nil {
+		return nil, err
 	}
 	bs := p.buf[p.pos : p.pos+int(l)]
 	p.pos += int(l)
 	return bs, nil
 }
 
+// checkLength bounds a length read from the input by the remaining data, every
+// element taking at least a byte, and by the configured limit.
+func (p *Parser) checkLength(n int, limit int, what string) error {
+	if n > len(p.buf)-p.pos {
+		return io.ErrUnexpectedEOF
+	}
+	if limit > 0 && n > limit {
+		return fmt.Errorf("%s %d: %w", what, n, def.ErrLimitExceeded)
+	}
+	return nil
+}
+
+// optionalTypeID is the ID of a type, 0 when the recording does not declare
+// it.
+func optionalTypeID(c *def.Class) def.TypeID {
+	if c == nil {
+		return 0
+	}
+	return c.ID
+}
+
 func (p *Parser) checkTypes() error {
 
 	tint := p.TypeMap.NameMap["int"]
@@ -469,6 +1210,7 @@ func (p *Parser) checkTypes() error {
 	p.TypeMap.T_FLOAT = tfloat.ID
 	p.TypeMap.T_BOOLEAN = tboolean.ID
 	p.TypeMap.T_STRING = tstring.ID
+	p.TypeMap.T_SHORT = optionalTypeID(p.TypeMap.NameMap["short"])
 
 	typeCPFrameType := p.TypeMap.NameMap["jdk.types.FrameType"]
 	typeCPThreadState := p.TypeMap.NameMap["jdk.types.ThreadState"]
@@ -545,13 +1287,85 @@ func (p *Parser) checkTypes() error {
 	p.bindStackTrace = types2.NewBindStackTrace(typeCPStackTrace, &p.TypeMap)
 	p.bindStackFrame = types2.NewBindStackFrame(typeStackFrame, &p.TypeMap)
 
+	// the old object pools are optional, jdk.OldObjectSample is disabled by
+	// default. They refer to each other, so all their IDs are set before
+	// binding their fields.
+	typeCPOldObject := p.TypeMap.NameMap["jdk.types.OldObject"]
+	typeCPReference := p.TypeMap.NameMap["jdk.types.Reference"]
+	typeCPOldObjectArray := p.TypeMap.NameMap["jdk.types.OldObjectArray"]
+	typeCPOldObjectField := p.TypeMap.NameMap["jdk.types.OldObjectField"]
+	typeCPOldObjectGcRoot := p.TypeMap.NameMap["jdk.types.OldObjectGcRoot"]
+	typeCPOldObjectRootSystem := p.TypeMap.NameMap["jdk.types.OldObjectRootSystem"]
+	typeCPOldObjectRootType := p.TypeMap.NameMap["jdk.types.OldObjectRootType"]
+	p.TypeMap.T_OLD_OBJECT = optionalTypeID(typeCPOldObject)
+	p.TypeMap.T_REFERENCE = optionalTypeID(typeCPReference)
+	p.TypeMap.T_OLD_OBJECT_ARRAY = optionalTypeID(typeCPOldObjectArray)
+	p.TypeMap.T_OLD_OBJECT_FIELD = optionalTypeID(typeCPOldObjectField)
+	p.TypeMap.T_OLD_OBJECT_GC_ROOT = optionalTypeID(typeCPOldObjectGcRoot)
+	p.TypeMap.T_OLD_OBJECT_ROOT_SYSTEM = optionalTypeID(typeCPOldObjectRootSystem)
+	p.TypeMap.T_OLD_OBJECT_ROOT_TYPE = optionalTypeID(typeCPOldObjectRootType)
+	p.bindOldObject = nil
+	if typeCPOldObject != nil {
+		p.bindOldObject = types2.NewBindOldObject(typeCPOldObject, &p.TypeMap)
+	}
+	p.bindReference = nil
+	if typeCPReference != nil {
+		p.bindReference = types2.NewBindReference(typeCPReference, &p.TypeMap)
+	}
+	p.bindOldObjectArray = nil
+	if typeCPOldObjectArray != nil {
+		p.bindOldObjectArray = types2.NewBindOldObjectArray(typeCPOldObjectArray, &p.TypeMap)
+	}
+	p.bindOldObjectField = nil
+	if typeCPOldObjectField != nil {
+		p.bindOldObjectField = types2.NewBindOldObjectField(typeCPOldObjectField, &p.TypeMap)
+	}
+	p.bindOldObjectGcRoot = nil
+	if typeCPOldObjectGcRoot != nil {
+		p.bindOldObjectGcRoot = types2.NewBindOldObjectGcRoot(typeCPOldObjectGcRoot, &p.TypeMap)
+	}
+	p.bindOldObjectRootSystem = nil
+	if typeCPOldObjectRootSystem != nil {
+		p.bindOldObjectRootSystem = types2.NewBindOldObjectRootSystem(typeCPOldObjectRootSystem, &p.TypeMap)
+	}
+	p.bindOldObjectRootType = nil
+	if typeCPOldObjectRootType != nil {
+		p.bindOldObjectRootType = types2.NewBindOldObjectRootType(typeCPOldObjectRootType, &p.TypeMap)
+	}
+
 	typeExecutionSample := p.TypeMap.NameMap["jdk.ExecutionSample"]
 	typeAllocInNewTLAB := p.TypeMap.NameMap["jdk.ObjectAllocationInNewTLAB"]
 	typeALlocOutsideTLAB := p.TypeMap.NameMap["jdk.ObjectAllocationOutsideTLAB"]
 	typeMonitorEnter := p.TypeMap.NameMap["jdk.JavaMonitorEnter"]
 	typeThreadPark := p.TypeMap.NameMap["jdk.ThreadPark"]
 	typeLiveObject := p.TypeMap.NameMap["profiler.LiveObject"]
+	typeMalloc := p.TypeMap.NameMap["profiler.Malloc"]
+	typeFree := p.TypeMap.NameMap["profiler.Free"]
 	typeActiveSetting := p.TypeMap.NameMap["jdk.ActiveSetting"]
+	typeDatadogExecutionSample := p.TypeMap.NameMap["datadog.ExecutionSample"]
+	typeDatadogMethodSample := p.TypeMap.NameMap["datadog.MethodSample"]
+	typeDatadogObjectSample := p.TypeMap.NameMap["datadog.ObjectSample"]
+	typeDatadogHeapLiveObject := p.TypeMap.NameMap["datadog.HeapLiveObject"]
+	typeDatadogExceptionSample := p.TypeMap.NameMap["datadog.ExceptionSample"]
+	typeDatadogEndpoint := p.TypeMap.NameMap["datadog.Endpoint"]
+	typeJavaExceptionThrow := p.TypeMap.NameMap["jdk.JavaExceptionThrow"]
+	typeJavaErrorThrow := p.TypeMap.NameMap["jdk.JavaErrorThrow"]
+	typeExceptionStatistics := p.TypeMap.NameMap["jdk.ExceptionStatistics"]
+	typeOldObjectSample := p.TypeMap.NameMap["jdk.OldObjectSample"]
+	typeVirtualThreadPinned := p.TypeMap.NameMap["jdk.VirtualThreadPinned"]
+	typeVirtualThreadSubmitFailed := p.TypeMap.NameMap["jdk.VirtualThreadSubmitFailed"]
+	typeContainerConfiguration := p.TypeMap.NameMap["jdk.ContainerConfiguration"]
+	typeContainerCPUUsage := p.TypeMap.NameMap["jdk.ContainerCPUUsage"]
+	typeContainerCPUThrottling := p.TypeMap.NameMap["jdk.ContainerCPUThrottling"]
+	typeContainerMemoryUsage := p.TypeMap.NameMap["jdk.ContainerMemoryUsage"]
+	typeContainerIOUsage := p.TypeMap.NameMap["jdk.ContainerIOUsage"]
+	typeJavaMonitorWait := p.TypeMap.NameMap["jdk.JavaMonitorWait"]
+	typeThreadSleep := p.TypeMap.NameMap["jdk.ThreadSleep"]
+	typeClassLoad := p.TypeMap.NameMap["jdk.ClassLoad"]
+	typeFileRead := p.TypeMap.NameMap["jdk.FileRead"]
+	typeFileWrite := p.TypeMap.NameMap["jdk.FileWrite"]
+	typeSocketRead := p.TypeMap.NameMap["jdk.SocketRead"]
+	typeSocketWrite := p.TypeMap.NameMap["jdk.SocketWrite"]
 
 	if typeExecutionSample != nil {
 		p.TypeMap.T_EXECUTION_SAMPLE = typeExecutionSample.ID
@@ -577,19 +1391,113 @@ func (p *Parser) checkTypes() error {
 		p.TypeMap.T_LIVE_OBJECT = typeLiveObject.ID
 		p.bindLiveObject = types2.NewBindLiveObject(typeLiveObject, &p.TypeMap)
 	}
+	if typeMalloc != nil {
+		p.TypeMap.T_MALLOC = typeMalloc.ID
+		p.bindMalloc = types2.NewBindMalloc(typeMalloc, &p.TypeMap)
+	}
+	if typeFree != nil {
+		p.TypeMap.T_FREE = typeFree.ID
+		p.bindFree = types2.NewBindFree(typeFree, &p.TypeMap)
+	}
 	if typeActiveSetting != nil {
 		p.TypeMap.T_ACTIVE_SETTING = typeActiveSetting.ID
 		p.bindActiveSetting = types2.NewBindActiveSetting(typeActiveSetting, &p.TypeMap)
 	}
-
-	p.FrameTypes.IDMap = nil
-	p.ThreadStates.IDMap = nil
-	p.Threads.IDMap = nil
-	p.Classes.IDMap = nil
-	p.Methods.IDMap.Slice = nil
-	p.Packages.IDMap = nil
-	p.Symbols.IDMap = nil
-	p.LogLevels.IDMap = nil
-	p.Stacktrace.IDMap = nil
+	if typeDatadogExecutionSample != nil {
+		p.TypeMap.T_DD_EXECUTION_SAMPLE = typeDatadogExecutionSample.ID
+		p.bindDatadogExecutionSample = types2.NewBindDatadogExecutionSample(typeDatadogExecutionSample, &p.TypeMap)
+	}
+	if typeDatadogMethodSample != nil {
+		p.TypeMap.T_DD_METHOD_SAMPLE = typeDatadogMethodSample.ID
+		p.bindDatadogMethodSample = types2.NewBindDatadogMethodSample(typeDatadogMethodSample, &p.TypeMap)
+	}
+	if typeDatadogObjectSample != nil {
+		p.TypeMap.T_DD_OBJECT_SAMPLE = typeDatadogObjectSample.ID
+		p.bindDatadogObjectSample = types2.NewBindDatadogObjectSample(typeDatadogObjectSample, &p.TypeMap)
+	}
+	if typeDatadogHeapLiveObject != nil {
+		p.TypeMap.T_DD_HEAP_LIVE_OBJECT = typeDatadogHeapLiveObject.ID
+		p.bindDatadogHeapLiveObject = types2.NewBindDatadogHeapLiveObject(typeDatadogHeapLiveObject, &p.TypeMap)
+	}
+	if typeDatadogExceptionSample != nil {
+		p.TypeMap.T_DD_EXCEPTION_SAMPLE = typeDatadogExceptionSample.ID
+		p.bindDatadogExceptionSample = types2.NewBindDatadogExceptionSample(typeDatadogExceptionSample, &p.TypeMap)
+	}
+	if typeDatadogEndpoint != nil {
+		p.TypeMap.T_DD_ENDPOINT = typeDatadogEndpoint.ID
+		p.bindDatadogEndpoint = types2.NewBindDatadogEndpoint(typeDatadogEndpoint, &p.TypeMap)
+	}
+	if typeJavaExceptionThrow != nil {
+		p.TypeMap.T_EXCEPTION_THROW = typeJavaExceptionThrow.ID
+		p.bindJavaExceptionThrow = types2.NewBindJavaExceptionThrow(typeJavaExceptionThrow, &p.TypeMap)
+	}
+	if typeJavaErrorThrow != nil {
+		p.TypeMap.T_ERROR_THROW = typeJavaErrorThrow.ID
+		p.bindJavaErrorThrow = types2.NewBindJavaErrorThrow(typeJavaErrorThrow, &p.TypeMap)
+	}
+	if typeExceptionStatistics != nil {
+		p.TypeMap.T_EXCEPTION_STATISTICS = typeExceptionStatistics.ID
+		p.bindExceptionStatistics = types2.NewBindExceptionStatistics(typeExceptionStatistics, &p.TypeMap)
+	}
+	if typeFileRead != nil {
+		p.TypeMap.T_FILE_READ = typeFileRead.ID
+		p.bindFileRead = types2.NewBindFileRead(typeFileRead, &p.TypeMap)
+	}
+	if typeFileWrite != nil {
+		p.TypeMap.T_FILE_WRITE = typeFileWrite.ID
+		p.bindFileWrite = types2.NewBindFileWrite(typeFileWrite, &p.TypeMap)
+	}
+	if typeSocketRead != nil {
+		p.TypeMap.T_SOCKET_READ = typeSocketRead.ID
+		p.bindSocketRead = types2.NewBindSocketRead(typeSocketRead, &p.TypeMap)
+	}
+	if typeSocketWrite != nil {
+		p.TypeMap.T_SOCKET_WRITE = typeSocketWrite.ID
+		p.bindSocketWrite = types2.NewBindSocketWrite(typeSocketWrite, &p.TypeMap)
+	}
+	if typeJavaMonitorWait != nil {
+		p.TypeMap.T_MONITOR_WAIT = typeJavaMonitorWait.ID
+		p.bindJavaMonitorWait = types2.NewBindJavaMonitorWait(typeJavaMonitorWait, &p.TypeMap)
+	}
+	if typeThreadSleep != nil {
+		p.TypeMap.T_THREAD_SLEEP = typeThreadSleep.ID
+		p.bindThreadSleep = types2.NewBindThreadSleep(typeThreadSleep, &p.TypeMap)
+	}
+	if typeClassLoad != nil {
+		p.TypeMap.T_CLASS_LOAD = typeClassLoad.ID
+		p.bindClassLoad = types2.NewBindClassLoad(typeClassLoad, &p.TypeMap)
+	}
+	if typeOldObjectSample != nil {
+		p.TypeMap.T_OLD_OBJECT_SAMPLE = typeOldObjectSample.ID
+		p.bindOldObjectSample = types2.NewBindOldObjectSample(typeOldObjectSample, &p.TypeMap)
+	}
+	if typeVirtualThreadPinned != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_PINNED = typeVirtualThreadPinned.ID
+		p.bindVirtualThreadPinned = types2.NewBindVirtualThreadPinned(typeVirtualThreadPinned, &p.TypeMap)
+	}
+	if typeVirtualThreadSubmitFailed != nil {
+		p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED = typeVirtualThreadSubmitFailed.ID
+		p.bindVirtualThreadSubmitFailed = types2.NewBindVirtualThreadSubmitFailed(typeVirtualThreadSubmitFailed, &p.TypeMap)
+	}
+	if typeContainerConfiguration != nil {
+		p.TypeMap.T_CONTAINER_CONFIGURATION = typeContainerConfiguration.ID
+		p.bindContainerConfiguration = types2.NewBindContainerConfiguration(typeContainerConfiguration, &p.TypeMap)
+	}
+	if typeContainerCPUUsage != nil {
+		p.TypeMap.T_CONTAINER_CPU_USAGE = typeContainerCPUUsage.ID
+		p.bindContainerCPUUsage = types2.NewBindContainerCPUUsage(typeContainerCPUUsage, &p.TypeMap)
+	}
+	if typeContainerCPUThrottling != nil {
+		p.TypeMap.T_CONTAINER_CPU_THROTTLING = typeContainerCPUThrottling.ID
+		p.bindContainerCPUThrottling = types2.NewBindContainerCPUThrottling(typeContainerCPUThrottling, &p.TypeMap)
+	}
+	if typeContainerMemoryUsage != nil {
+		p.TypeMap.T_CONTAINER_MEMORY_USAGE = typeContainerMemoryUsage.ID
+		p.bindContainerMemoryUsage = types2.NewBindContainerMemoryUsage(typeContainerMemoryUsage, &p.TypeMap)
+	}
+	if typeContainerIOUsage != nil {
+		p.TypeMap.T_CONTAINER_IO_USAGE = typeContainerIOUsage.ID
+		p.bindContainerIOUsage = types2.NewBindContainerIOUsage(typeContainerIOUsage, &p.TypeMap)
+	}
 	return nil
 }
@@ -4,10 +4,18 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	types2 "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
 )
 
 func TestParseUncompressed(t *testing.T) {
@@ -73,3 +81,318 @@ func readGzipFile(fname string) ([]byte, error) {
 	defer r.Close()
 	return ioutil.ReadAll(r)
 }
+
+func TestParseRecover(t *testing.T) {
+	jfr, err := readGzipFile("./testdata/FastSlow_2024_01_16_180855.jfr.gz")
+	if err != nil {
+		t.Fatalf("Unable to read JFR file: %s", err)
+	}
+	chunk := jfr[:127450]
+	// a crashed JVM leaves a truncated last chunk behind, either with the header
+	// of its last flush or with the header of a chunk which was never finalised
+	truncated := append(append([]byte{}, chunk...), chunk[:20000]...)
+	unfinished := append([]byte{}, truncated...)
+	for i := len(chunk) + 8; i < len(chunk)+32; i++ {
+		unfinished[i] = 0 // size, constant pool and metadata offsets
+	}
+
+	countEvents := func(buf []byte, options Options) (int, error) {
+		p := NewParser(buf, options)
+		n := 0
+		for {
+			_, err := p.ParseEvent()
+			if err == io.EOF {
+				return n, p.RecoveredError()
+			}
+			if err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+
+	complete, err := countEvents(chunk, Options{})
+	if err != nil {
+		t.Fatalf("Unable to parse complete chunk: %s", err)
+	}
+	for name, buf := range map[string][]byte{"truncated": truncated, "unfinished": unfinished} {
+		if _, err := countEvents(buf, Options{}); err == nil {
+			t.Errorf("%s: expected an error parsing without recovery", name)
+		}
+		n, err := countEvents(buf, Options{Recover: true})
+		if n <= complete || n >= 2*complete {
+			t.Errorf("%s: expected part of the last chunk to be recovered, got %d events, %d per complete chunk", name, n, complete)
+		}
+		if err == nil {
+			t.Errorf("%s: expected the truncated event to be reported", name)
+		}
+
+		if _, err := ParseWithOptions(bytes.NewReader(buf), &ChunkParseOptions{}); err == nil {
+			t.Errorf("%s: expected an error parsing without recovery", name)
+		}
+		chunks, err := ParseWithOptions(bytes.NewReader(buf), &ChunkParseOptions{Recover: true})
+		var recovered *RecoveredError
+		if !errors.As(err, &recovered) {
+			t.Fatalf("%s: expected the truncated event to be reported, got %v", name, err)
+		}
+		if len(chunks) != 2 {
+			t.Fatalf("%s: expected 2 chunks, got %d", name, len(chunks))
+		}
+		events := func(c *Chunk) (n int) {
+			for _, ec := range c.ChunkEvents {
+				n += len(ec.Events)
+			}
+			return n
+		}
+		if n, complete := events(chunks[1]), events(chunks[0]); n == 0 || n >= complete {
+			t.Errorf("%s: expected part of the last chunk to be recovered, got %d events, %d per complete chunk", name, n, complete)
+		}
+	}
+
+	// a truncated header after the last chunk
+	chunks, err := ParseWithOptions(bytes.NewReader(append(append([]byte{}, chunk...), chunk[:30]...)), &ChunkParseOptions{Recover: true})
+	var recovered *RecoveredError
+	if !errors.As(err, &recovered) || len(chunks) != 1 {
+		t.Errorf("truncated header: expected 1 chunk and the truncation to be reported, got %d chunks and %v", len(chunks), err)
+	}
+
+	// input which is not a recording is not recovered from
+	garbage := bytes.Repeat([]byte{0x42}, 5000)
+	if _, err := countEvents(garbage, Options{Recover: true}); err == nil || errors.As(err, &recovered) {
+		t.Errorf("garbage: expected an error, got %v", err)
+	}
+	if _, err := ParseWithOptions(bytes.NewReader(garbage), &ChunkParseOptions{Recover: true}); err == nil || errors.As(err, &recovered) {
+		t.Errorf("garbage: expected an error, got %v", err)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	jfr, err := readGzipFile("./testdata/FastSlow_2024_01_16_180855.jfr.gz")
+	if err != nil {
+		t.Fatalf("Unable to read JFR file: %s", err)
+	}
+	for name, options := range map[string]Options{
+		"string":        {StringLengthLimit: 4},
+		"array":         {ArrayLengthLimit: 4},
+		"constant pool": {ConstantPoolLimit: 4},
+	} {
+		p := NewParser(jfr, options)
+		for err == nil {
+			_, err = p.ParseEvent()
+		}
+		if !errors.Is(err, def.ErrLimitExceeded) {
+			t.Errorf("%s: expected the limit to be exceeded, got %v", name, err)
+		}
+		err = nil
+
+		_, err = ParseWithOptions(bytes.NewReader(jfr), &ChunkParseOptions{
+			StringLengthLimit: options.StringLengthLimit,
+			ArrayLengthLimit:  options.ArrayLengthLimit,
+			ConstantPoolLimit: options.ConstantPoolLimit,
+		})
+		if !errors.Is(err, def.ErrLimitExceeded) {
+			t.Errorf("%s: expected the limit to be exceeded, got %v", name, err)
+		}
+		err = nil
+	}
+	_, err = ParseWithOptions(bytes.NewReader(jfr), &ChunkParseOptions{ChunkSizeLimit: 1 << 10})
+	if err == nil {
+		t.Errorf("expected the chunk size limit to be exceeded")
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	jfr, err := readGzipFile("./testdata/FastSlow_2024_01_16_180855.jfr.gz")
+	if err != nil {
+		t.Fatalf("Unable to read JFR file: %s", err)
+	}
+	type sample struct {
+		start  time.Time
+		thread string
+	}
+	samples := func(options Options) []sample {
+		var res []sample
+		p := NewParser(jfr, options)
+		for {
+			typ, err := p.ParseEvent()
+			if err == io.EOF {
+				return res
+			}
+			if err != nil {
+				t.Fatalf("Unable to parse JFR file: %s", err)
+			}
+			if typ != p.TypeMap.T_EXECUTION_SAMPLE {
+				continue
+			}
+			s := sample{start: p.TicksToTime(p.ExecutionSample.StartTime)}
+			if thread := p.GetThread(p.ExecutionSample.SampledThread); thread != nil {
+				s.thread = thread.JavaName
+			}
+			res = append(res, s)
+		}
+	}
+
+	all := samples(Options{})
+	if len(all) < 3 {
+		t.Fatalf("expected execution samples, got %d", len(all))
+	}
+	first, last := all[0].start, all[0].start
+	for _, s := range all {
+		if s.start.Before(first) {
+			first = s.start
+		}
+		if s.start.After(last) {
+			last = s.start
+		}
+	}
+	if first.Year() != 2024 {
+		t.Errorf("expected samples recorded in 2024, got %s", first)
+	}
+	start, end := first.Add(last.Sub(first)/3), first.Add(last.Sub(first)*2/3)
+	expected := 0
+	for _, s := range all {
+		if !s.start.Before(start) && s.start.Before(end) {
+			expected++
+		}
+	}
+	windowed := samples(Options{WindowStart: start, WindowEnd: end})
+	if len(windowed) != expected || expected == 0 {
+		t.Errorf("expected %d samples in the window, got %d", expected, len(windowed))
+	}
+	for _, s := range windowed {
+		if s.start.Before(start) || !s.start.Before(end) {
+			t.Errorf("sample at %s outside of the window [%s, %s)", s.start, start, end)
+		}
+	}
+	if n := len(samples(Options{WindowStart: last.Add(time.Second)})); n != 0 {
+		t.Errorf("expected no samples after the recording, got %d", n)
+	}
+
+	name := all[0].thread
+	expected = 0
+	for _, s := range all {
+		if s.thread == name {
+			expected++
+		}
+	}
+	filtered := samples(Options{ThreadFilter: func(thread *types2.Thread) bool {
+		return thread != nil && thread.JavaName == name
+	}})
+	if len(filtered) != expected {
+		t.Errorf("expected %d samples of thread %q, got %d", expected, name, len(filtered))
+	}
+	for _, s := range filtered {
+		if s.thread != name {
+			t.Errorf("expected samples of thread %q only, got %q", name, s.thread)
+		}
+	}
+}
+
+func TestTicksConversion(t *testing.T) {
+	h := ChunkHeader{StartNanos: 1_700_000_000_000_000_000, StartTicks: 1000, TicksPerSecond: 1_000_000}
+	p := &Parser{header: h}
+	if got := p.TicksToTime(1000 + 1_500_000); got.UnixNano() != 1_700_000_001_500_000_000 {
+		t.Errorf("unexpected time %d", got.UnixNano())
+	}
+	if got := p.TicksToDuration(2500); got != 2500*time.Microsecond {
+		t.Errorf("unexpected duration %s", got)
+	}
+	if got := h.nanosToTicks(1_700_000_001_500_000_000); got != 1000+1_500_000 {
+		t.Errorf("unexpected ticks %d", got)
+	}
+	if got := h.nanosToTicks(0); got != 0 {
+		t.Errorf("expected ticks before the chunk to be clamped, got %d", got)
+	}
+	h.TicksPerSecond = 1e9
+	if got := h.nanosToTicks(math.MaxInt64); got != math.MaxUint64 {
+		t.Errorf("expected ticks far after the chunk to be clamped, got %d", got)
+	}
+}
+
+func TestParseDatadogEndpoints(t *testing.T) {
+	testcases := []struct {
+		file      string
+		endpoints int
+	}{
+		{"./testdata/ddtrace.jfr", 2713},
+		// lz4 compressed, with the endpoints in the java.lang.String constant pool
+		{"./testdata/prof.jfr", 3},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.file, func(t *testing.T) {
+			f, err := os.Open(tc.file)
+			if err != nil {
+				t.Fatalf("Unable to open JFR file: %s", err)
+			}
+			defer f.Close()
+			r, err := Decompress(f)
+			if err != nil {
+				t.Fatalf("Unable to decompress JFR file: %s", err)
+			}
+			jfr, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Unable to read JFR file: %s", err)
+			}
+			p := NewParser(jfr, Options{})
+			endpoints := 0
+			for {
+				typ, err := p.ParseEvent()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Unable to parse JFR file: %s", err)
+				}
+				if typ != p.TypeMap.T_DD_ENDPOINT {
+					continue
+				}
+				endpoints++
+				if p.DatadogEndpoint.Endpoint == "" || p.DatadogEndpoint.LocalRootSpanId == 0 {
+					t.Fatalf("expected an endpoint and a root span, got %+v", p.DatadogEndpoint)
+				}
+			}
+			if endpoints != tc.endpoints {
+				t.Errorf("expected %d endpoints, got %d", tc.endpoints, endpoints)
+			}
+		})
+	}
+}
+
+func TestParseVirtualThreadSubmitFailed(t *testing.T) {
+	const submitFailed = 100
+	r := jfrtest.New()
+	r.EventClass(submitFailed, "jdk.VirtualThreadSubmitFailed",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "javaThreadId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "exceptionMessage", Class: jfrtest.String})
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.Event(submitFailed, uint64(jfrtest.StartTicks+1), uint64(0), uint64(1), uint64(0), uint64(42), "Scheduler rejected the task")
+
+	p := NewParser(r.Bytes(), Options{})
+	var events []types2.VirtualThreadSubmitFailed
+	for {
+		typ, err := p.ParseEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Unable to parse JFR file: %s", err)
+		}
+		if typ == p.TypeMap.T_VIRTUAL_THREAD_SUBMIT_FAILED {
+			events = append(events, p.VirtualThreadSubmitFailed)
+		}
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 jdk.VirtualThreadSubmitFailed event, got %d", len(events))
+	}
+	e := events[0]
+	if e.JavaThreadId != 42 || e.ExceptionMessage != "Scheduler rejected the task" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if thread := p.GetThread(e.EventThread); thread == nil || thread.JavaName != "main" {
+		t.Errorf("unexpected event thread %+v", thread)
+	}
+}
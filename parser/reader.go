@@ -4,7 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"unicode/utf16"
 
+	"github.com/grafana/jfr-parser/parser/types/def"
 	reader2 "github.com/grafana/jfr-parser/reader"
 )
 
@@ -40,10 +42,15 @@ type InputReader interface {
 
 type reader struct {
 	InputReader
-	varR reader2.VarReader
+	varR   reader2.VarReader
+	limits def.Limits
 }
 
 func NewReader(r InputReader, compressed bool) Reader {
+	return newReader(r, compressed, def.Limits{})
+}
+
+func newReader(r InputReader, compressed bool, limits def.Limits) Reader {
 	var varR reader2.VarReader
 	if compressed {
 		varR = reader2.NewCompressed(r)
@@ -53,7 +60,33 @@ func NewReader(r InputReader, compressed bool) Reader {
 	return reader{
 		InputReader: r,
 		varR:        varR,
+		limits:      limits,
+	}
+}
+
+// readerLimits returns the limits r was created with.
+func readerLimits(r Reader) def.Limits {
+	if rd, ok := r.(reader); ok {
+		return rd.limits
+	}
+	return def.Limits{}
+}
+
+// checkLength bounds a length read from r by the remaining input when it is
+// known, every element taking at least a byte, and by the given limit.
+func checkLength(r Reader, n int32, limit int, what string) error {
+	if n < 0 {
+		return def.ErrIntOverflow
 	}
+	if rd, ok := r.(reader); ok {
+		if in, ok := rd.InputReader.(interface{ Len() int }); ok && int(n) > in.Len() {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	if limit > 0 && int(n) > limit {
+		return fmt.Errorf("%s %d: %w", what, n, def.ErrLimitExceeded)
+	}
+	return nil
 }
 
 func (r reader) Boolean() (bool, error) {
@@ -101,7 +134,6 @@ func (r reader) Double() (float64, error) {
 	return n, err
 }
 
-// TODO: Should we differentiate between null and empty?
 func (r reader) String() (*String, error) {
 	s := new(String)
 	enc, err := r.Byte()
@@ -110,6 +142,7 @@ func (r reader) String() (*String, error) {
 	}
 	switch enc {
 	case StringEncodingNull:
+		s.null = true
 		return s, nil
 	case StringEncodingEmptyString:
 		return s, nil
@@ -120,13 +153,27 @@ func (r reader) String() (*String, error) {
 		}
 		s.constantRef = &constantReference{index: idx}
 		return s, nil
-	case StringEncodingUtf8ByteArray, StringEncodingCharArray, StringEncodingLatin1ByteArray:
-		str, err := r.utf8()
+	case StringEncodingUtf8ByteArray:
+		b, err := r.byteArray()
+		if err != nil {
+			return nil, err
+		}
+		s.s = string(b)
+		return s, nil
+	case StringEncodingCharArray:
+		str, err := r.charArray()
 		if err != nil {
 			return nil, err
 		}
 		s.s = str
 		return s, nil
+	case StringEncodingLatin1ByteArray:
+		b, err := r.byteArray()
+		if err != nil {
+			return nil, err
+		}
+		s.s = def.DecodeLatin1(b)
+		return s, nil
 	default:
 		return nil, fmt.Errorf("unsupported string type :%d", enc)
 	}
@@ -144,13 +191,34 @@ func (r reader) VarLong() (int64, error) {
 	return r.varR.VarLong()
 }
 
-func (r reader) utf8() (string, error) {
+func (r reader) byteArray() ([]byte, error) {
 	n, err := r.varR.VarInt()
 	if err != nil {
-		return "", nil
+		return nil, err
+	}
+	if err := checkLength(r, n, r.limits.StringLength, "string length"); err != nil {
+		return nil, err
 	}
-	// TODO: make sure n is reasonable
 	b := make([]byte, n)
 	_, err = io.ReadFull(r, b)
-	return string(b), err
+	return b, err
+}
+
+func (r reader) charArray() (string, error) {
+	n, err := r.varR.VarInt()
+	if err != nil {
+		return "", err
+	}
+	if err := checkLength(r, n, r.limits.StringLength, "string length"); err != nil {
+		return "", err
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		c, err := r.varR.VarInt()
+		if err != nil {
+			return "", err
+		}
+		units[i] = uint16(c)
+	}
+	return string(utf16.Decode(units)), nil
 }
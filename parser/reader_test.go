@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf16"
+
+	gtypes "github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+)
+
+type stringCase struct {
+	name     string
+	data     []byte
+	expected string
+	null     bool
+}
+
+func appendVarInt(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func encodeCharArray(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := appendVarInt([]byte{StringEncodingCharArray}, uint64(len(units)))
+	for _, u := range units {
+		b = appendVarInt(b, uint64(u))
+	}
+	return b
+}
+
+func stringCases() []stringCase {
+	utf8 := "java/lang/Kläss$日本"
+	latin1 := []byte{'K', 'l', 0xe4, 's', 's', 'e'}
+	return []stringCase{
+		{name: "null", data: []byte{StringEncodingNull}, null: true},
+		{name: "empty", data: []byte{StringEncodingEmptyString}},
+		{name: "constant pool", data: []byte{StringEncodingConstantPool, 7}, expected: "pooled"},
+		{name: "utf8", data: append(appendVarInt([]byte{StringEncodingUtf8ByteArray}, uint64(len(utf8))), utf8...), expected: utf8},
+		{name: "char array", data: encodeCharArray("Kläss 𝄞"), expected: "Kläss 𝄞"},
+		{name: "latin1", data: append(appendVarInt([]byte{StringEncodingLatin1ByteArray}, uint64(len(latin1))), latin1...), expected: "Klässe"},
+	}
+}
+
+func TestReaderString(t *testing.T) {
+	pool := &CPool{Pool: map[int64]ParseResolvable{7: &String{s: "pooled"}}}
+	for _, tc := range stringCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewReader(bytes.NewReader(tc.data), true).String()
+			if err != nil {
+				t.Fatalf("unable to read string: %s", err)
+			}
+			if err := s.Resolve(nil, PoolMap{0: pool}); err != nil {
+				t.Fatalf("unable to resolve string: %s", err)
+			}
+			if s.s != tc.expected || s.IsNull() != tc.null {
+				t.Fatalf("expected %q (null %v), got %q (null %v)", tc.expected, tc.null, s.s, s.IsNull())
+			}
+		})
+	}
+}
+
+func TestParserString(t *testing.T) {
+	for _, tc := range stringCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Parser{buf: tc.data}
+			p.TypeMap.Strings = map[uint64]string{7: "pooled"}
+			s, err := p.string()
+			if err != nil {
+				t.Fatalf("unable to read string: %s", err)
+			}
+			if s != tc.expected || def.IsNull(s) != tc.null {
+				t.Fatalf("expected %q (null %v), got %q (null %v)", tc.expected, tc.null, s, def.IsNull(s))
+			}
+			if p.pos != len(tc.data) {
+				t.Fatalf("expected to consume %d bytes, consumed %d", len(tc.data), p.pos)
+			}
+		})
+	}
+}
+
+func TestGeneratedString(t *testing.T) {
+	typeMap := &def.TypeMap{T_STRING: 20, Strings: map[uint64]string{7: "pooled"}}
+	class := &def.Class{Name: "jdk.types.Symbol", ID: 30, Fields: []def.Field{{Name: "string", Type: typeMap.T_STRING}}}
+	cases := stringCases()
+	data := appendVarInt(nil, uint64(len(cases)))
+	for i, tc := range cases {
+		data = appendVarInt(data, uint64(i))
+		data = append(data, tc.data...)
+	}
+	data = append(data, []byte{1, 2, 8}...) // one more symbol with an unknown reference
+	data[0]++
+
+	symbols := gtypes.SymbolList{}
+	n, err := symbols.Parse(data, gtypes.NewBindSymbol(class, typeMap), typeMap)
+	if err != nil {
+		t.Fatalf("unable to parse symbols: %s", err)
+	}
+	if n != len(data) {
+		t.Fatalf("expected to consume %d bytes, consumed %d", len(data), n)
+	}
+	for i, tc := range cases {
+		if s := symbols.Symbol[i].String; s != tc.expected || def.IsNull(s) != tc.null {
+			t.Errorf("%s: expected %q (null %v), got %q (null %v)", tc.name, tc.expected, tc.null, s, def.IsNull(s))
+		}
+	}
+	if typeMap.UnresolvedStrings != 1 {
+		t.Errorf("expected 1 unresolved string, got %d", typeMap.UnresolvedStrings)
+	}
+}
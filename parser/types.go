@@ -47,6 +47,15 @@ var types = map[types2.FieldClass]func() ParseResolvable{
 	types2.GCWhen:               func() ParseResolvable { return SetPfFunc(new(GCWhen)) },
 	types2.ReferenceType:        func() ParseResolvable { return SetPfFunc(new(ReferenceType)) },
 	types2.MetadataType:         func() ParseResolvable { return SetPfFunc(new(MetadataType)) },
+	types2.DeoptimizationReason: func() ParseResolvable { return SetPfFunc(new(DeoptimizationReason)) },
+	types2.DeoptimizationAction: func() ParseResolvable { return SetPfFunc(new(DeoptimizationAction)) },
+	types2.OldObject:            func() ParseResolvable { return SetPfFunc(new(OldObject)) },
+	types2.Reference:            func() ParseResolvable { return SetPfFunc(new(Reference)) },
+	types2.OldObjectArray:       func() ParseResolvable { return SetPfFunc(new(OldObjectArray)) },
+	types2.OldObjectField:       func() ParseResolvable { return SetPfFunc(new(OldObjectField)) },
+	types2.OldObjectGcRoot:      func() ParseResolvable { return SetPfFunc(new(OldObjectGcRoot)) },
+	types2.OldObjectRootSystem:  func() ParseResolvable { return SetPfFunc(new(OldObjectRootSystem)) },
+	types2.OldObjectRootType:    func() ParseResolvable { return SetPfFunc(new(OldObjectRootType)) },
 	types2.LogLevel:             func() ParseResolvable { return SetPfFunc(new(LogLevel)) },
 	types2.AttributeValue:       func() ParseResolvable { return SetPfFunc(new(AttributeValue)) },
 }
@@ -79,6 +88,15 @@ var (
 	_ ParseResolveFielder = (*GCWhen)(nil)
 	_ ParseResolveFielder = (*ReferenceType)(nil)
 	_ ParseResolveFielder = (*MetadataType)(nil)
+	_ ParseResolveFielder = (*DeoptimizationReason)(nil)
+	_ ParseResolveFielder = (*DeoptimizationAction)(nil)
+	_ ParseResolveFielder = (*OldObject)(nil)
+	_ ParseResolveFielder = (*Reference)(nil)
+	_ ParseResolveFielder = (*OldObjectArray)(nil)
+	_ ParseResolveFielder = (*OldObjectField)(nil)
+	_ ParseResolveFielder = (*OldObjectGcRoot)(nil)
+	_ ParseResolveFielder = (*OldObjectRootSystem)(nil)
+	_ ParseResolveFielder = (*OldObjectRootType)(nil)
 	_ ParseResolveFielder = (*LogLevel)(nil)
 	_ ParseResolveFielder = (*AttributeValue)(nil)
 	_ ParseResolveFielder = (*InflateCause)(nil)
@@ -217,7 +235,9 @@ func (b *BaseStructType) parseFields(r Reader, classes ClassMap, cpools PoolMap,
 			if err != nil {
 				return fmt.Errorf("failed to parse %s: unable to read array length: %w", class.Name, err)
 			}
-			// done: assert n is small enough
+			if err := checkLength(r, n, readerLimits(r).ArrayLength, "array length"); err != nil {
+				return fmt.Errorf("failed to parse %s: invalid array length: %w", class.Name, err)
+			}
 			for i := int64(0); i < int64(n); i++ {
 				p, err := ParseClass(r, classes, cpools, f.ClassID)
 				if err != nil {
@@ -327,7 +347,9 @@ func parseFields(r Reader, classes ClassMap, cpools PoolMap, class *ClassMetadat
 			if err != nil {
 				return fmt.Errorf("failed to parse %s: unable to read array length: %w", class.Name, err)
 			}
-			// done: assert n is small enough
+			if err := checkLength(r, n, readerLimits(r).ArrayLength, "array length"); err != nil {
+				return fmt.Errorf("failed to parse %s: invalid array length: %w", class.Name, err)
+			}
 			for i := int64(0); i < int64(n); i++ {
 				p, err := ParseClass(r, classes, cpools, f.ClassID)
 				if err != nil {
@@ -514,6 +536,14 @@ func (s *Short) Parse(r Reader, _ ClassMap, _ PoolMap, _ *ClassMetadata) error {
 
 func (*Short) Resolve(ClassMap, PoolMap) error { return nil }
 
+func toShort(p Parseable) (int16, error) {
+	x, ok := p.(*Short)
+	if !ok {
+		return 0, errors.New("not a Short")
+	}
+	return int16(*x), nil
+}
+
 type UShort uint16
 
 func (u *UShort) Parse(r Reader, _ ClassMap, _ PoolMap, _ *ClassMetadata) error {
@@ -560,9 +590,16 @@ func toClass(p ParseResolvable) (*Class, error) {
 
 type String struct {
 	s           string
+	null        bool
 	constantRef *constantReference
 }
 
+// IsNull reports whether the string was encoded as null rather than as the
+// empty string.
+func (s *String) IsNull() bool {
+	return s.null
+}
+
 func (s *String) Parse(r Reader, classMap ClassMap, pools PoolMap, classMetadata *ClassMetadata) error {
 	if classMap[classMetadata.ID].Name != "java.lang.String" {
 		return fmt.Errorf("expect type of java.lang.String, got type %s", classMap[classMetadata.ID].Name)
@@ -614,6 +651,8 @@ type Thread struct {
 	OsThreadID   int64
 	JavaName     string
 	JavaThreadID int64
+	// Virtual tells the virtual threads of JDK 21 and later.
+	Virtual bool
 }
 
 func (t *Thread) setField(name string, p ParseResolvable) (err error) {
@@ -626,6 +665,8 @@ func (t *Thread) setField(name string, p ParseResolvable) (err error) {
 		t.JavaName, err = ToString(p)
 	case "javaThreadId":
 		t.JavaThreadID, err = toLong(p)
+	case "virtual":
+		t.Virtual, err = toBoolean(p)
 	}
 	return err
 }
@@ -670,7 +711,7 @@ type CodeBlobType struct {
 
 func (cbt *CodeBlobType) setField(name string, p ParseResolvable) (err error) {
 	switch name {
-	case "string":
+	case "type":
 		cbt.String, err = ToString(p)
 	}
 	return err
@@ -691,7 +732,8 @@ type FlagValueOrigin struct {
 
 func (fvo *FlagValueOrigin) setField(name string, p ParseResolvable) (err error) {
 	switch name {
-	case "description":
+	// the JVM names the field origin
+	case "description", "origin":
 		fvo.String, err = ToString(p)
 	}
 	return err
@@ -754,7 +796,7 @@ type GCName struct {
 
 func (gn *GCName) setField(name string, p ParseResolvable) (err error) {
 	switch name {
-	case "string":
+	case "name":
 		gn.String, err = ToString(p)
 	}
 	return err
@@ -1139,6 +1181,200 @@ func (m *MetadataType) setField(name string, p ParseResolvable) error {
 	return setStringField(name, "type", p, &m.Type)
 }
 
+// DeoptimizationReason jdk.types.DeoptimizationReason
+type DeoptimizationReason struct {
+	BaseStructType
+	Reason string
+}
+
+func (d *DeoptimizationReason) setField(name string, p ParseResolvable) error {
+	return setStringField(name, "reason", p, &d.Reason)
+}
+
+// DeoptimizationAction jdk.types.DeoptimizationAction
+type DeoptimizationAction struct {
+	BaseStructType
+	Action string
+}
+
+func (d *DeoptimizationAction) setField(name string, p ParseResolvable) error {
+	return setStringField(name, "action", p, &d.Action)
+}
+
+// OldObject jdk.types.OldObject, an object of an old object sample or of
+// its reference chain to a GC root.
+type OldObject struct {
+	BaseStructType
+	Address     int64
+	Type        *Class
+	Description string
+	// Referrer is the reference to the object from the next object of the
+	// chain, nil for the last object.
+	Referrer *Reference
+}
+
+func (o *OldObject) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "address":
+		o.Address, err = toLong(p)
+	case "type":
+		o.Type, err = toClass(p)
+	case "description":
+		o.Description, err = ToString(p)
+	case "referrer":
+		o.Referrer, err = toReference(p)
+	}
+	return err
+}
+
+func toOldObject(p ParseResolvable) (*OldObject, error) {
+	o, ok := p.(*OldObject)
+	if !ok {
+		return nil, errors.New("not an OldObject")
+	}
+	return o, nil
+}
+
+// Reference jdk.types.Reference, a field or array element of Object
+// referencing the previous object of a chain.
+type Reference struct {
+	BaseStructType
+	Array  *OldObjectArray
+	Field  *OldObjectField
+	Object *OldObject
+	// Skip is the number of objects of the chain left out between Object and
+	// the previous object.
+	Skip int32
+}
+
+func (r *Reference) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "array":
+		r.Array, err = toOldObjectArray(p)
+	case "field":
+		r.Field, err = toOldObjectField(p)
+	case "object":
+		r.Object, err = toOldObject(p)
+	case "skip":
+		r.Skip, err = toInt(p)
+	}
+	return err
+}
+
+func toReference(p ParseResolvable) (*Reference, error) {
+	r, ok := p.(*Reference)
+	if !ok {
+		return nil, errors.New("not a Reference")
+	}
+	return r, nil
+}
+
+// OldObjectArray jdk.types.OldObjectArray
+type OldObjectArray struct {
+	BaseStructType
+	Size  int32
+	Index int32
+}
+
+func (a *OldObjectArray) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "size":
+		a.Size, err = toInt(p)
+	case "index":
+		a.Index, err = toInt(p)
+	}
+	return err
+}
+
+func toOldObjectArray(p ParseResolvable) (*OldObjectArray, error) {
+	a, ok := p.(*OldObjectArray)
+	if !ok {
+		return nil, errors.New("not an OldObjectArray")
+	}
+	return a, nil
+}
+
+// OldObjectField jdk.types.OldObjectField
+type OldObjectField struct {
+	BaseStructType
+	Name      string
+	Modifiers int16
+}
+
+func (f *OldObjectField) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "name":
+		f.Name, err = ToString(p)
+	case "modifiers":
+		f.Modifiers, err = toShort(p)
+	}
+	return err
+}
+
+func toOldObjectField(p ParseResolvable) (*OldObjectField, error) {
+	f, ok := p.(*OldObjectField)
+	if !ok {
+		return nil, errors.New("not an OldObjectField")
+	}
+	return f, nil
+}
+
+// OldObjectGcRoot jdk.types.OldObjectGcRoot
+type OldObjectGcRoot struct {
+	BaseStructType
+	Description string
+	System      *OldObjectRootSystem
+	Type        *OldObjectRootType
+}
+
+func (r *OldObjectGcRoot) setField(name string, p ParseResolvable) (err error) {
+	switch name {
+	case "description":
+		r.Description, err = ToString(p)
+	case "system":
+		r.System, err = toOldObjectRootSystem(p)
+	case "type":
+		r.Type, err = toOldObjectRootType(p)
+	}
+	return err
+}
+
+// OldObjectRootSystem jdk.types.OldObjectRootSystem
+type OldObjectRootSystem struct {
+	BaseStructType
+	System string
+}
+
+func (s *OldObjectRootSystem) setField(name string, p ParseResolvable) error {
+	return setStringField(name, "system", p, &s.System)
+}
+
+func toOldObjectRootSystem(p ParseResolvable) (*OldObjectRootSystem, error) {
+	s, ok := p.(*OldObjectRootSystem)
+	if !ok {
+		return nil, errors.New("not an OldObjectRootSystem")
+	}
+	return s, nil
+}
+
+// OldObjectRootType jdk.types.OldObjectRootType
+type OldObjectRootType struct {
+	BaseStructType
+	Type string
+}
+
+func (t *OldObjectRootType) setField(name string, p ParseResolvable) error {
+	return setStringField(name, "type", p, &t.Type)
+}
+
+func toOldObjectRootType(p ParseResolvable) (*OldObjectRootType, error) {
+	t, ok := p.(*OldObjectRootType)
+	if !ok {
+		return nil, errors.New("not an OldObjectRootType")
+	}
+	return t, nil
+}
+
 // LogLevel profiler.types.LogLevel
 type LogLevel struct {
 	BaseStructType
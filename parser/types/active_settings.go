@@ -58,13 +58,13 @@ func NewBindActiveSetting(typ *def.Class, typeMap *def.TypeMap) *BindActiveSetti
 				res.Fields = append(res.Fields, BindFieldActiveSetting{Field: &typ.Fields[i]}) // skip changed field
 			}
 		case "name":
-			if typ.Fields[i].Equals(&def.Field{Name: "name", Type: typeMap.T_STRING, ConstantPool: false, Array: false}) {
+			if typ.Fields[i].Equals(&def.Field{Name: "name", Type: typeMap.T_STRING, ConstantPool: typ.Fields[i].ConstantPool, Array: false}) {
 				res.Fields = append(res.Fields, BindFieldActiveSetting{Field: &typ.Fields[i], string: &res.Temp.Name})
 			} else {
 				res.Fields = append(res.Fields, BindFieldActiveSetting{Field: &typ.Fields[i]}) // skip changed field
 			}
 		case "value":
-			if typ.Fields[i].Equals(&def.Field{Name: "value", Type: typeMap.T_STRING, ConstantPool: false, Array: false}) {
+			if typ.Fields[i].Equals(&def.Field{Name: "value", Type: typeMap.T_STRING, ConstantPool: typ.Fields[i].ConstantPool, Array: false}) {
 				res.Fields = append(res.Fields, BindFieldActiveSetting{Field: &typ.Fields[i], string: &res.Temp.Value})
 			} else {
 				res.Fields = append(res.Fields, BindFieldActiveSetting{Field: &typ.Fields[i]}) // skip changed field
@@ -117,6 +117,12 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 				}
 			}
 			bindArraySize = int(v32_)
+			if bindArraySize > l-pos {
+				return 0, io.ErrUnexpectedEOF
+			}
+			if typeMap.Limits.ArrayLength > 0 && bindArraySize > typeMap.Limits.ArrayLength {
+				return 0, fmt.Errorf("array length %d: %w", bindArraySize, def.ErrLimitExceeded)
+			}
 		}
 		for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
 			if bind.Fields[bindFieldIndex].Field.ConstantPool {
@@ -144,6 +150,14 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 					if bind.Fields[bindFieldIndex].StackTraceRef != nil {
 						*bind.Fields[bindFieldIndex].StackTraceRef = StackTraceRef(v32_)
 					}
+				case typeMap.T_STRING:
+					if bind.Fields[bindFieldIndex].string != nil {
+						if s, ok := typeMap.Strings[uint64(v32_)]; ok {
+							*bind.Fields[bindFieldIndex].string = s
+						} else {
+							typeMap.UnresolvedStrings++
+						}
+					}
 				}
 			} else {
 				bindFieldTypeID := bind.Fields[bindFieldIndex].Field.Type
@@ -157,9 +171,32 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 					pos++
 					switch b_ {
 					case 0:
-						break
+						s_ = def.Null
 					case 1:
 						break
+					case 2:
+						v64_ = 0
+						for shift = uint(0); shift <= 56; shift += 7 {
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							if shift == 56 {
+								v64_ |= uint64(b_&0xFF) << shift
+								break
+							} else {
+								v64_ |= uint64(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+						}
+						if s, ok := typeMap.Strings[v64_]; ok {
+							s_ = s
+						} else {
+							typeMap.UnresolvedStrings++
+						}
 					case 3:
 						v32_ = uint32(0)
 						for shift = uint(0); ; shift += 7 {
@@ -176,12 +213,64 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 								break
 							}
 						}
-						if pos+int(v32_) > l {
+						if int(v32_) > l-pos {
 							return 0, io.ErrUnexpectedEOF
 						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
 						bs := data[pos : pos+int(v32_)]
 						s_ = *(*string)(unsafe.Pointer(&bs))
 						pos += int(v32_)
+					case 4:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if int(v32_) > l-pos {
+							return 0, io.ErrUnexpectedEOF
+						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
+						if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+							return 0, err
+						}
+					case 5:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if int(v32_) > l-pos {
+							return 0, io.ErrUnexpectedEOF
+						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
+						s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+						pos += int(v32_)
 					default:
 						return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 					}
@@ -234,6 +323,13 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 					pos++
 					// skipping
 				case typeMap.T_FLOAT:
+					if pos+4 > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+					pos += 4
+					// skipping
+				case typeMap.T_SHORT:
 					v32_ = uint32(0)
 					for shift = uint(0); ; shift += 7 {
 						if shift >= 32 {
@@ -302,9 +398,32 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 								pos++
 								switch b_ {
 								case 0:
-									break
+									s_ = def.Null
 								case 1:
 									break
+								case 2:
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+									if s, ok := typeMap.Strings[v64_]; ok {
+										s_ = s
+									} else {
+										typeMap.UnresolvedStrings++
+									}
 								case 3:
 									v32_ = uint32(0)
 									for shift = uint(0); ; shift += 7 {
@@ -321,32 +440,68 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 											break
 										}
 									}
-									if pos+int(v32_) > l {
+									if int(v32_) > l-pos {
 										return 0, io.ErrUnexpectedEOF
 									}
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
 									bs := data[pos : pos+int(v32_)]
 									s_ = *(*string)(unsafe.Pointer(&bs))
 									pos += int(v32_)
-								default:
-									return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
-								}
-							} else if bindSkipFieldType == typeMap.T_INT {
-								v32_ = uint32(0)
-								for shift = uint(0); ; shift += 7 {
-									if shift >= 32 {
-										return 0, def.ErrIntOverflow
+								case 4:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
 									}
-									if pos >= l {
+									if int(v32_) > l-pos {
 										return 0, io.ErrUnexpectedEOF
 									}
-									b_ = data[pos]
-									pos++
-									v32_ |= uint32(b_&0x7F) << shift
-									if b_ < 0x80 {
-										break
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
+									if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+										return 0, err
+									}
+								case 5:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if int(v32_) > l-pos {
+										return 0, io.ErrUnexpectedEOF
 									}
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
+									s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+									pos += int(v32_)
+								default:
+									return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 								}
-							} else if bindSkipFieldType == typeMap.T_FLOAT {
+							} else if bindSkipFieldType == typeMap.T_INT || bindSkipFieldType == typeMap.T_SHORT {
 								v32_ = uint32(0)
 								for shift = uint(0); ; shift += 7 {
 									if shift >= 32 {
@@ -362,6 +517,12 @@ func (this *ActiveSetting) Parse(data []byte, bind *BindActiveSetting, typeMap *
 										break
 									}
 								}
+							} else if bindSkipFieldType == typeMap.T_FLOAT {
+								if pos+4 > l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+								pos += 4
 							} else if bindSkipFieldType == typeMap.T_LONG {
 								v64_ = 0
 								for shift = uint(0); shift <= 56; shift += 7 {
@@ -87,8 +87,18 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 		}
 	}
 	n := int(v32_)
-	this.IDMap = make(map[ClassRef]uint32, n)
-	this.Class = make([]Class, n)
+	if n > l-pos {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if typeMap.Limits.ConstantPoolEntries > 0 && n > typeMap.Limits.ConstantPoolEntries {
+		return 0, fmt.Errorf("constant pool entries %d: %w", n, def.ErrLimitExceeded)
+	}
+	if this.IDMap == nil {
+		this.IDMap = make(map[ClassRef]uint32, n)
+		this.Class = make([]Class, 0, n)
+	}
+	base := len(this.Class)
+	this.Class = append(this.Class, make([]Class, n)...)
 	for i := 0; i < n; i++ {
 		v32_ = uint32(0)
 		for shift = uint(0); ; shift += 7 {
@@ -125,6 +135,12 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 					}
 				}
 				bindArraySize = int(v32_)
+				if bindArraySize > l-pos {
+					return 0, io.ErrUnexpectedEOF
+				}
+				if typeMap.Limits.ArrayLength > 0 && bindArraySize > typeMap.Limits.ArrayLength {
+					return 0, fmt.Errorf("array length %d: %w", bindArraySize, def.ErrLimitExceeded)
+				}
 			}
 			for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
 				if bind.Fields[bindFieldIndex].Field.ConstantPool {
@@ -169,9 +185,32 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 						pos++
 						switch b_ {
 						case 0:
-							break
+							s_ = def.Null
 						case 1:
 							break
+						case 2:
+							v64_ = 0
+							for shift = uint(0); shift <= 56; shift += 7 {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								if shift == 56 {
+									v64_ |= uint64(b_&0xFF) << shift
+									break
+								} else {
+									v64_ |= uint64(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							}
+							if s, ok := typeMap.Strings[v64_]; ok {
+								s_ = s
+							} else {
+								typeMap.UnresolvedStrings++
+							}
 						case 3:
 							v32_ = uint32(0)
 							for shift = uint(0); ; shift += 7 {
@@ -188,12 +227,64 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 									break
 								}
 							}
-							if pos+int(v32_) > l {
+							if int(v32_) > l-pos {
 								return 0, io.ErrUnexpectedEOF
 							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
 							bs := data[pos : pos+int(v32_)]
 							s_ = *(*string)(unsafe.Pointer(&bs))
 							pos += int(v32_)
+						case 4:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+								return 0, err
+							}
+						case 5:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+							pos += int(v32_)
 						default:
 							return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 						}
@@ -244,6 +335,13 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 						pos++
 						// skipping
 					case typeMap.T_FLOAT:
+						if pos+4 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+						pos += 4
+						// skipping
+					case typeMap.T_SHORT:
 						v32_ = uint32(0)
 						for shift = uint(0); ; shift += 7 {
 							if shift >= 32 {
@@ -312,9 +410,32 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 									pos++
 									switch b_ {
 									case 0:
-										break
+										s_ = def.Null
 									case 1:
 										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if s, ok := typeMap.Strings[v64_]; ok {
+											s_ = s
+										} else {
+											typeMap.UnresolvedStrings++
+										}
 									case 3:
 										v32_ = uint32(0)
 										for shift = uint(0); ; shift += 7 {
@@ -331,32 +452,68 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 												break
 											}
 										}
-										if pos+int(v32_) > l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
 										bs := data[pos : pos+int(v32_)]
 										s_ = *(*string)(unsafe.Pointer(&bs))
 										pos += int(v32_)
-									default:
-										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
-									}
-								} else if bindSkipFieldType == typeMap.T_INT {
-									v32_ = uint32(0)
-									for shift = uint(0); ; shift += 7 {
-										if shift >= 32 {
-											return 0, def.ErrIntOverflow
+									case 4:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
 										}
-										if pos >= l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
-										b_ = data[pos]
-										pos++
-										v32_ |= uint32(b_&0x7F) << shift
-										if b_ < 0x80 {
-											break
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
+										if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+											return 0, err
 										}
+									case 5:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if int(v32_) > l-pos {
+											return 0, io.ErrUnexpectedEOF
+										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
+										s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+										pos += int(v32_)
+									default:
+										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 									}
-								} else if bindSkipFieldType == typeMap.T_FLOAT {
+								} else if bindSkipFieldType == typeMap.T_INT || bindSkipFieldType == typeMap.T_SHORT {
 									v32_ = uint32(0)
 									for shift = uint(0); ; shift += 7 {
 										if shift >= 32 {
@@ -372,6 +529,12 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 											break
 										}
 									}
+								} else if bindSkipFieldType == typeMap.T_FLOAT {
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
 								} else if bindSkipFieldType == typeMap.T_LONG {
 									v64_ = 0
 									for shift = uint(0); shift <= 56; shift += 7 {
@@ -405,8 +568,8 @@ func (this *ClassList) Parse(data []byte, bind *BindClass, typeMap *def.TypeMap)
 				}
 			}
 		}
-		this.Class[i] = bind.Temp
-		this.IDMap[id] = uint32(i)
+		this.Class[base+i] = bind.Temp
+		this.IDMap[id] = uint32(base + i)
 	}
 	return pos, nil
 }
@@ -0,0 +1,595 @@
+// Code generated by gen/main.go. DO NOT EDIT.
+
+package types
+
+import (
+	"fmt"
+	"github.com/grafana/jfr-parser/parser/types/def"
+	"io"
+	"unsafe"
+)
+
+type BindDatadogExceptionSample struct {
+	Temp   DatadogExceptionSample
+	Fields []BindFieldDatadogExceptionSample
+}
+
+type BindFieldDatadogExceptionSample struct {
+	Field         *def.Field
+	uint64        *uint64
+	ThreadRef     *ThreadRef
+	StackTraceRef *StackTraceRef
+	string        *string
+	uint32        *uint32
+	bool          *bool
+}
+
+func NewBindDatadogExceptionSample(typ *def.Class, typeMap *def.TypeMap) *BindDatadogExceptionSample {
+	res := new(BindDatadogExceptionSample)
+	res.Fields = make([]BindFieldDatadogExceptionSample, 0, len(typ.Fields))
+	for i := 0; i < len(typ.Fields); i++ {
+		switch typ.Fields[i].Name {
+		case "startTime":
+			if typ.Fields[i].Equals(&def.Field{Name: "startTime", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], uint64: &res.Temp.StartTime})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "duration":
+			if typ.Fields[i].Equals(&def.Field{Name: "duration", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], uint64: &res.Temp.Duration})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "eventThread":
+			if typ.Fields[i].Equals(&def.Field{Name: "eventThread", Type: typeMap.T_THREAD, ConstantPool: true, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], ThreadRef: &res.Temp.EventThread})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "stackTrace":
+			if typ.Fields[i].Equals(&def.Field{Name: "stackTrace", Type: typeMap.T_STACK_TRACE, ConstantPool: true, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], StackTraceRef: &res.Temp.StackTrace})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "type":
+			if typ.Fields[i].Equals(&def.Field{Name: "type", Type: typeMap.T_STRING, ConstantPool: typ.Fields[i].ConstantPool, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], string: &res.Temp.Type})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "message":
+			if typ.Fields[i].Equals(&def.Field{Name: "message", Type: typeMap.T_STRING, ConstantPool: typ.Fields[i].ConstantPool, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], string: &res.Temp.Message})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "stackDepth":
+			if typ.Fields[i].Equals(&def.Field{Name: "stackDepth", Type: typeMap.T_INT, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], uint32: &res.Temp.StackDepth})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "sampled":
+			if typ.Fields[i].Equals(&def.Field{Name: "sampled", Type: typeMap.T_BOOLEAN, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], bool: &res.Temp.Sampled})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "firstOccurrence":
+			if typ.Fields[i].Equals(&def.Field{Name: "firstOccurrence", Type: typeMap.T_BOOLEAN, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], bool: &res.Temp.FirstOccurrence})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "localRootSpanId":
+			if typ.Fields[i].Equals(&def.Field{Name: "localRootSpanId", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], uint64: &res.Temp.LocalRootSpanId})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		case "spanId":
+			if typ.Fields[i].Equals(&def.Field{Name: "spanId", Type: typeMap.T_LONG, ConstantPool: false, Array: false}) {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i], uint64: &res.Temp.SpanId})
+			} else {
+				res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip changed field
+			}
+		default:
+			res.Fields = append(res.Fields, BindFieldDatadogExceptionSample{Field: &typ.Fields[i]}) // skip unknown new field
+		}
+	}
+	return res
+}
+
+type DatadogExceptionSample struct {
+	StartTime       uint64
+	Duration        uint64
+	EventThread     ThreadRef
+	StackTrace      StackTraceRef
+	Type            string
+	Message         string
+	StackDepth      uint32
+	Sampled         bool
+	FirstOccurrence bool
+	LocalRootSpanId uint64
+	SpanId          uint64
+}
+
+func (this *DatadogExceptionSample) Parse(data []byte, bind *BindDatadogExceptionSample, typeMap *def.TypeMap) (pos int, err error) {
+	var (
+		v64_  uint64
+		v32_  uint32
+		s_    string
+		b_    byte
+		shift = uint(0)
+		l     = len(data)
+	)
+	_ = v64_
+	_ = v32_
+	_ = s_
+	for bindFieldIndex := 0; bindFieldIndex < len(bind.Fields); bindFieldIndex++ {
+		bindArraySize := 1
+		if bind.Fields[bindFieldIndex].Field.Array {
+			v32_ = uint32(0)
+			for shift = uint(0); ; shift += 7 {
+				if shift >= 32 {
+					return 0, def.ErrIntOverflow
+				}
+				if pos >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b_ = data[pos]
+				pos++
+				v32_ |= uint32(b_&0x7F) << shift
+				if b_ < 0x80 {
+					break
+				}
+			}
+			bindArraySize = int(v32_)
+			if bindArraySize > l-pos {
+				return 0, io.ErrUnexpectedEOF
+			}
+			if typeMap.Limits.ArrayLength > 0 && bindArraySize > typeMap.Limits.ArrayLength {
+				return 0, fmt.Errorf("array length %d: %w", bindArraySize, def.ErrLimitExceeded)
+			}
+		}
+		for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
+			if bind.Fields[bindFieldIndex].Field.ConstantPool {
+				v32_ = uint32(0)
+				for shift = uint(0); ; shift += 7 {
+					if shift >= 32 {
+						return 0, def.ErrIntOverflow
+					}
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					v32_ |= uint32(b_&0x7F) << shift
+					if b_ < 0x80 {
+						break
+					}
+				}
+				switch bind.Fields[bindFieldIndex].Field.Type {
+				case typeMap.T_THREAD:
+					if bind.Fields[bindFieldIndex].ThreadRef != nil {
+						*bind.Fields[bindFieldIndex].ThreadRef = ThreadRef(v32_)
+					}
+				case typeMap.T_STACK_TRACE:
+					if bind.Fields[bindFieldIndex].StackTraceRef != nil {
+						*bind.Fields[bindFieldIndex].StackTraceRef = StackTraceRef(v32_)
+					}
+				case typeMap.T_STRING:
+					if bind.Fields[bindFieldIndex].string != nil {
+						if s, ok := typeMap.Strings[uint64(v32_)]; ok {
+							*bind.Fields[bindFieldIndex].string = s
+						} else {
+							typeMap.UnresolvedStrings++
+						}
+					}
+				}
+			} else {
+				bindFieldTypeID := bind.Fields[bindFieldIndex].Field.Type
+				switch bindFieldTypeID {
+				case typeMap.T_STRING:
+					s_ = ""
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					switch b_ {
+					case 0:
+						s_ = def.Null
+					case 1:
+						break
+					case 2:
+						v64_ = 0
+						for shift = uint(0); shift <= 56; shift += 7 {
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							if shift == 56 {
+								v64_ |= uint64(b_&0xFF) << shift
+								break
+							} else {
+								v64_ |= uint64(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+						}
+						if s, ok := typeMap.Strings[v64_]; ok {
+							s_ = s
+						} else {
+							typeMap.UnresolvedStrings++
+						}
+					case 3:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if int(v32_) > l-pos {
+							return 0, io.ErrUnexpectedEOF
+						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
+						bs := data[pos : pos+int(v32_)]
+						s_ = *(*string)(unsafe.Pointer(&bs))
+						pos += int(v32_)
+					case 4:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if int(v32_) > l-pos {
+							return 0, io.ErrUnexpectedEOF
+						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
+						if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+							return 0, err
+						}
+					case 5:
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						if int(v32_) > l-pos {
+							return 0, io.ErrUnexpectedEOF
+						}
+						if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+							return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+						}
+						s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+						pos += int(v32_)
+					default:
+						return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+					}
+					if bind.Fields[bindFieldIndex].string != nil {
+						*bind.Fields[bindFieldIndex].string = s_
+					}
+				case typeMap.T_INT:
+					v32_ = uint32(0)
+					for shift = uint(0); ; shift += 7 {
+						if shift >= 32 {
+							return 0, def.ErrIntOverflow
+						}
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						v32_ |= uint32(b_&0x7F) << shift
+						if b_ < 0x80 {
+							break
+						}
+					}
+					if bind.Fields[bindFieldIndex].uint32 != nil {
+						*bind.Fields[bindFieldIndex].uint32 = v32_
+					}
+				case typeMap.T_LONG:
+					v64_ = 0
+					for shift = uint(0); shift <= 56; shift += 7 {
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						if shift == 56 {
+							v64_ |= uint64(b_&0xFF) << shift
+							break
+						} else {
+							v64_ |= uint64(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+					}
+					if bind.Fields[bindFieldIndex].uint64 != nil {
+						*bind.Fields[bindFieldIndex].uint64 = v64_
+					}
+				case typeMap.T_BOOLEAN:
+					if pos >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b_ = data[pos]
+					pos++
+					if bind.Fields[bindFieldIndex].bool != nil {
+						*bind.Fields[bindFieldIndex].bool = b_ != 0
+					}
+				case typeMap.T_FLOAT:
+					if pos+4 > l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+					pos += 4
+					// skipping
+				case typeMap.T_SHORT:
+					v32_ = uint32(0)
+					for shift = uint(0); ; shift += 7 {
+						if shift >= 32 {
+							return 0, def.ErrIntOverflow
+						}
+						if pos >= l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						b_ = data[pos]
+						pos++
+						v32_ |= uint32(b_&0x7F) << shift
+						if b_ < 0x80 {
+							break
+						}
+					}
+					// skipping
+				default:
+					bindFieldType := typeMap.IDMap[bind.Fields[bindFieldIndex].Field.Type]
+					if bindFieldType == nil || len(bindFieldType.Fields) == 0 {
+						return 0, fmt.Errorf("unknown type %d", bind.Fields[bindFieldIndex].Field.Type)
+					}
+					bindSkipObjects := 1
+					if bind.Fields[bindFieldIndex].Field.Array {
+						v32_ = uint32(0)
+						for shift = uint(0); ; shift += 7 {
+							if shift >= 32 {
+								return 0, def.ErrIntOverflow
+							}
+							if pos >= l {
+								return 0, io.ErrUnexpectedEOF
+							}
+							b_ = data[pos]
+							pos++
+							v32_ |= uint32(b_&0x7F) << shift
+							if b_ < 0x80 {
+								break
+							}
+						}
+						bindSkipObjects = int(v32_)
+					}
+					for bindSkipObjectIndex := 0; bindSkipObjectIndex < bindSkipObjects; bindSkipObjectIndex++ {
+						for bindskipFieldIndex := 0; bindskipFieldIndex < len(bindFieldType.Fields); bindskipFieldIndex++ {
+							bindSkipFieldType := bindFieldType.Fields[bindskipFieldIndex].Type
+							if bindFieldType.Fields[bindskipFieldIndex].ConstantPool {
+								v32_ = uint32(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 32 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v32_ |= uint32(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_STRING {
+								s_ = ""
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								switch b_ {
+								case 0:
+									s_ = def.Null
+								case 1:
+									break
+								case 2:
+									v64_ = 0
+									for shift = uint(0); shift <= 56; shift += 7 {
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										if shift == 56 {
+											v64_ |= uint64(b_&0xFF) << shift
+											break
+										} else {
+											v64_ |= uint64(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+									}
+									if s, ok := typeMap.Strings[v64_]; ok {
+										s_ = s
+									} else {
+										typeMap.UnresolvedStrings++
+									}
+								case 3:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if int(v32_) > l-pos {
+										return 0, io.ErrUnexpectedEOF
+									}
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
+									bs := data[pos : pos+int(v32_)]
+									s_ = *(*string)(unsafe.Pointer(&bs))
+									pos += int(v32_)
+								case 4:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if int(v32_) > l-pos {
+										return 0, io.ErrUnexpectedEOF
+									}
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
+									if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+										return 0, err
+									}
+								case 5:
+									v32_ = uint32(0)
+									for shift = uint(0); ; shift += 7 {
+										if shift >= 32 {
+											return 0, def.ErrIntOverflow
+										}
+										if pos >= l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										b_ = data[pos]
+										pos++
+										v32_ |= uint32(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+									if int(v32_) > l-pos {
+										return 0, io.ErrUnexpectedEOF
+									}
+									if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+										return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+									}
+									s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+									pos += int(v32_)
+								default:
+									return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
+								}
+							} else if bindSkipFieldType == typeMap.T_INT || bindSkipFieldType == typeMap.T_SHORT {
+								v32_ = uint32(0)
+								for shift = uint(0); ; shift += 7 {
+									if shift >= 32 {
+										return 0, def.ErrIntOverflow
+									}
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									v32_ |= uint32(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_FLOAT {
+								if pos+4 > l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+								pos += 4
+							} else if bindSkipFieldType == typeMap.T_LONG {
+								v64_ = 0
+								for shift = uint(0); shift <= 56; shift += 7 {
+									if pos >= l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									b_ = data[pos]
+									pos++
+									if shift == 56 {
+										v64_ |= uint64(b_&0xFF) << shift
+										break
+									} else {
+										v64_ |= uint64(b_&0x7F) << shift
+										if b_ < 0x80 {
+											break
+										}
+									}
+								}
+							} else if bindSkipFieldType == typeMap.T_BOOLEAN {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+							} else {
+								return 0, fmt.Errorf("nested objects not implemented. ")
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	*this = bind.Temp
+	return pos, nil
+}
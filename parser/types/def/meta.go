@@ -7,6 +7,7 @@ import (
 
 var ErrIntOverflow = fmt.Errorf("int overflow")
 var ErrNameEmpty = fmt.Errorf("class/field name is empty")
+var ErrLimitExceeded = fmt.Errorf("limit exceeded")
 
 type Class struct {
 	Name   string
@@ -0,0 +1,64 @@
+package def
+
+import (
+	"io"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var nullString byte
+
+// Null is the string the fast parser reads for a string encoded as null. It
+// is equal to the empty string, so only the callers that tell them apart need
+// IsNull.
+var Null = unsafe.String(&nullString, 0)
+
+// IsNull reports whether s was read from a string encoded as null rather than
+// from an empty one.
+func IsNull(s string) bool {
+	return unsafe.StringData(s) == &nullString
+}
+
+// DecodeCharArray decodes n varint encoded UTF-16 code units starting at
+// data[pos] and returns the string along with the position after them.
+func DecodeCharArray(data []byte, pos int, n int) (string, int, error) {
+	units := make([]uint16, n)
+	for i := 0; i < n; i++ {
+		v := uint32(0)
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 32 {
+				return "", 0, ErrIntOverflow
+			}
+			if pos >= len(data) {
+				return "", 0, io.ErrUnexpectedEOF
+			}
+			b := data[pos]
+			pos++
+			v |= uint32(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		units[i] = uint16(v)
+	}
+	return string(utf16.Decode(units)), pos, nil
+}
+
+// DecodeLatin1 converts ISO-8859-1 bytes to a UTF-8 string.
+func DecodeLatin1(b []byte) string {
+	ascii := true
+	for _, c := range b {
+		if c >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
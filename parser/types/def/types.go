@@ -2,25 +2,50 @@ package def
 
 type TypeID uint64
 
+// Limits bound the allocations driven by lengths read from a recording.
+// Zero means no limit besides the size of the input itself.
+type Limits struct {
+	StringLength        int
+	ArrayLength         int
+	ConstantPoolEntries int
+}
+
 type TypeMap struct {
 	IDMap   map[TypeID]*Class
 	NameMap map[string]*Class
 
+	Limits Limits
+
+	// Strings holds the java.lang.String constant pool of the current chunk,
+	// resolving strings encoded as constant pool references.
+	Strings map[uint64]string
+	// UnresolvedStrings counts string references missing from Strings. They
+	// are read as empty strings.
+	UnresolvedStrings int
+
 	T_STRING  TypeID
 	T_INT     TypeID
 	T_LONG    TypeID
 	T_FLOAT   TypeID
 	T_BOOLEAN TypeID
+	T_SHORT   TypeID
 
-	T_CLASS        TypeID
-	T_THREAD       TypeID
-	T_FRAME_TYPE   TypeID
-	T_THREAD_STATE TypeID
-	T_STACK_TRACE  TypeID
-	T_METHOD       TypeID
-	T_PACKAGE      TypeID
-	T_SYMBOL       TypeID
-	T_LOG_LEVEL    TypeID
+	T_CLASS                  TypeID
+	T_THREAD                 TypeID
+	T_FRAME_TYPE             TypeID
+	T_THREAD_STATE           TypeID
+	T_STACK_TRACE            TypeID
+	T_METHOD                 TypeID
+	T_PACKAGE                TypeID
+	T_SYMBOL                 TypeID
+	T_LOG_LEVEL              TypeID
+	T_OLD_OBJECT             TypeID
+	T_REFERENCE              TypeID
+	T_OLD_OBJECT_ARRAY       TypeID
+	T_OLD_OBJECT_FIELD       TypeID
+	T_OLD_OBJECT_GC_ROOT     TypeID
+	T_OLD_OBJECT_ROOT_SYSTEM TypeID
+	T_OLD_OBJECT_ROOT_TYPE   TypeID
 
 	T_STACK_FRAME  TypeID
 	T_CLASS_LOADER TypeID
@@ -32,4 +57,38 @@ type TypeMap struct {
 	T_MONITOR_ENTER      TypeID
 	T_THREAD_PARK        TypeID
 	T_ACTIVE_SETTING     TypeID
+
+	T_DD_EXECUTION_SAMPLE TypeID
+	T_DD_METHOD_SAMPLE    TypeID
+	T_DD_OBJECT_SAMPLE    TypeID
+	T_DD_HEAP_LIVE_OBJECT TypeID
+	T_DD_EXCEPTION_SAMPLE TypeID
+	T_DD_ENDPOINT         TypeID
+
+	T_EXCEPTION_THROW      TypeID
+	T_ERROR_THROW          TypeID
+	T_EXCEPTION_STATISTICS TypeID
+
+	T_FILE_READ    TypeID
+	T_FILE_WRITE   TypeID
+	T_SOCKET_READ  TypeID
+	T_SOCKET_WRITE TypeID
+
+	T_MONITOR_WAIT TypeID
+	T_THREAD_SLEEP TypeID
+	T_CLASS_LOAD   TypeID
+
+	T_OLD_OBJECT_SAMPLE TypeID
+
+	T_VIRTUAL_THREAD_PINNED        TypeID
+	T_VIRTUAL_THREAD_SUBMIT_FAILED TypeID
+
+	T_CONTAINER_CONFIGURATION  TypeID
+	T_CONTAINER_CPU_USAGE      TypeID
+	T_CONTAINER_CPU_THROTTLING TypeID
+	T_CONTAINER_MEMORY_USAGE   TypeID
+	T_CONTAINER_IO_USAGE       TypeID
+
+	T_MALLOC TypeID
+	T_FREE   TypeID
 }
@@ -25,7 +25,7 @@ func NewBindLogLevel(typ *def.Class, typeMap *def.TypeMap) *BindLogLevel {
 	for i := 0; i < len(typ.Fields); i++ {
 		switch typ.Fields[i].Name {
 		case "name":
-			if typ.Fields[i].Equals(&def.Field{Name: "name", Type: typeMap.T_STRING, ConstantPool: false, Array: false}) {
+			if typ.Fields[i].Equals(&def.Field{Name: "name", Type: typeMap.T_STRING, ConstantPool: typ.Fields[i].ConstantPool, Array: false}) {
 				res.Fields = append(res.Fields, BindFieldLogLevel{Field: &typ.Fields[i], string: &res.Temp.Name})
 			} else {
 				res.Fields = append(res.Fields, BindFieldLogLevel{Field: &typ.Fields[i]}) // skip changed field
@@ -75,8 +75,18 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 		}
 	}
 	n := int(v32_)
-	this.IDMap = make(map[LogLevelRef]uint32, n)
-	this.LogLevel = make([]LogLevel, n)
+	if n > l-pos {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if typeMap.Limits.ConstantPoolEntries > 0 && n > typeMap.Limits.ConstantPoolEntries {
+		return 0, fmt.Errorf("constant pool entries %d: %w", n, def.ErrLimitExceeded)
+	}
+	if this.IDMap == nil {
+		this.IDMap = make(map[LogLevelRef]uint32, n)
+		this.LogLevel = make([]LogLevel, 0, n)
+	}
+	base := len(this.LogLevel)
+	this.LogLevel = append(this.LogLevel, make([]LogLevel, n)...)
 	for i := 0; i < n; i++ {
 		v32_ = uint32(0)
 		for shift = uint(0); ; shift += 7 {
@@ -113,6 +123,12 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 					}
 				}
 				bindArraySize = int(v32_)
+				if bindArraySize > l-pos {
+					return 0, io.ErrUnexpectedEOF
+				}
+				if typeMap.Limits.ArrayLength > 0 && bindArraySize > typeMap.Limits.ArrayLength {
+					return 0, fmt.Errorf("array length %d: %w", bindArraySize, def.ErrLimitExceeded)
+				}
 			}
 			for bindArrayIndex := 0; bindArrayIndex < bindArraySize; bindArrayIndex++ {
 				if bind.Fields[bindFieldIndex].Field.ConstantPool {
@@ -131,6 +147,16 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 							break
 						}
 					}
+					switch bind.Fields[bindFieldIndex].Field.Type {
+					case typeMap.T_STRING:
+						if bind.Fields[bindFieldIndex].string != nil {
+							if s, ok := typeMap.Strings[uint64(v32_)]; ok {
+								*bind.Fields[bindFieldIndex].string = s
+							} else {
+								typeMap.UnresolvedStrings++
+							}
+						}
+					}
 				} else {
 					bindFieldTypeID := bind.Fields[bindFieldIndex].Field.Type
 					switch bindFieldTypeID {
@@ -143,9 +169,32 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 						pos++
 						switch b_ {
 						case 0:
-							break
+							s_ = def.Null
 						case 1:
 							break
+						case 2:
+							v64_ = 0
+							for shift = uint(0); shift <= 56; shift += 7 {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								if shift == 56 {
+									v64_ |= uint64(b_&0xFF) << shift
+									break
+								} else {
+									v64_ |= uint64(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							}
+							if s, ok := typeMap.Strings[v64_]; ok {
+								s_ = s
+							} else {
+								typeMap.UnresolvedStrings++
+							}
 						case 3:
 							v32_ = uint32(0)
 							for shift = uint(0); ; shift += 7 {
@@ -162,12 +211,64 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 									break
 								}
 							}
-							if pos+int(v32_) > l {
+							if int(v32_) > l-pos {
 								return 0, io.ErrUnexpectedEOF
 							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
 							bs := data[pos : pos+int(v32_)]
 							s_ = *(*string)(unsafe.Pointer(&bs))
 							pos += int(v32_)
+						case 4:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+								return 0, err
+							}
+						case 5:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+							pos += int(v32_)
 						default:
 							return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 						}
@@ -218,6 +319,13 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 						pos++
 						// skipping
 					case typeMap.T_FLOAT:
+						if pos+4 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+						pos += 4
+						// skipping
+					case typeMap.T_SHORT:
 						v32_ = uint32(0)
 						for shift = uint(0); ; shift += 7 {
 							if shift >= 32 {
@@ -286,9 +394,32 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 									pos++
 									switch b_ {
 									case 0:
-										break
+										s_ = def.Null
 									case 1:
 										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if s, ok := typeMap.Strings[v64_]; ok {
+											s_ = s
+										} else {
+											typeMap.UnresolvedStrings++
+										}
 									case 3:
 										v32_ = uint32(0)
 										for shift = uint(0); ; shift += 7 {
@@ -305,32 +436,68 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 												break
 											}
 										}
-										if pos+int(v32_) > l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
 										bs := data[pos : pos+int(v32_)]
 										s_ = *(*string)(unsafe.Pointer(&bs))
 										pos += int(v32_)
-									default:
-										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
-									}
-								} else if bindSkipFieldType == typeMap.T_INT {
-									v32_ = uint32(0)
-									for shift = uint(0); ; shift += 7 {
-										if shift >= 32 {
-											return 0, def.ErrIntOverflow
+									case 4:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
 										}
-										if pos >= l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
-										b_ = data[pos]
-										pos++
-										v32_ |= uint32(b_&0x7F) << shift
-										if b_ < 0x80 {
-											break
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
 										}
+										if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+											return 0, err
+										}
+									case 5:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if int(v32_) > l-pos {
+											return 0, io.ErrUnexpectedEOF
+										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
+										s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+										pos += int(v32_)
+									default:
+										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 									}
-								} else if bindSkipFieldType == typeMap.T_FLOAT {
+								} else if bindSkipFieldType == typeMap.T_INT || bindSkipFieldType == typeMap.T_SHORT {
 									v32_ = uint32(0)
 									for shift = uint(0); ; shift += 7 {
 										if shift >= 32 {
@@ -346,6 +513,12 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 											break
 										}
 									}
+								} else if bindSkipFieldType == typeMap.T_FLOAT {
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
 								} else if bindSkipFieldType == typeMap.T_LONG {
 									v64_ = 0
 									for shift = uint(0); shift <= 56; shift += 7 {
@@ -379,8 +552,8 @@ func (this *LogLevelList) Parse(data []byte, bind *BindLogLevel, typeMap *def.Ty
 				}
 			}
 		}
-		this.LogLevel[i] = bind.Temp
-		this.IDMap[id] = uint32(i)
+		this.LogLevel[base+i] = bind.Temp
+		this.IDMap[id] = uint32(base + i)
 	}
 	return pos, nil
 }
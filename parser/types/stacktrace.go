@@ -83,8 +83,18 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 		}
 	}
 	n := int(v32_)
-	this.IDMap = make(map[StackTraceRef]uint32, n)
-	this.StackTrace = make([]StackTrace, n)
+	if n > l-pos {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if typeMap.Limits.ConstantPoolEntries > 0 && n > typeMap.Limits.ConstantPoolEntries {
+		return 0, fmt.Errorf("constant pool entries %d: %w", n, def.ErrLimitExceeded)
+	}
+	if this.IDMap == nil {
+		this.IDMap = make(map[StackTraceRef]uint32, n)
+		this.StackTrace = make([]StackTrace, 0, n)
+	}
+	base := len(this.StackTrace)
+	this.StackTrace = append(this.StackTrace, make([]StackTrace, n)...)
 	for i := 0; i < n; i++ {
 		v32_ = uint32(0)
 		for shift = uint(0); ; shift += 7 {
@@ -121,7 +131,13 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 					}
 				}
 				bindArraySize = int(v32_)
-				if bind.Fields[bindFieldIndex].Field.Type == typeMap.T_STACK_FRAME {
+				if bindArraySize > l-pos {
+					return 0, io.ErrUnexpectedEOF
+				}
+				if typeMap.Limits.ArrayLength > 0 && bindArraySize > typeMap.Limits.ArrayLength {
+					return 0, fmt.Errorf("array length %d: %w", bindArraySize, def.ErrLimitExceeded)
+				}
+				if bind.Fields[bindFieldIndex].Field.Type == typeMap.T_STACK_FRAME && bind.Fields[bindFieldIndex].StackFrame != nil {
 					*bind.Fields[bindFieldIndex].StackFrame = make([]StackFrame, 0, bindArraySize)
 				}
 			}
@@ -154,9 +170,32 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 						pos++
 						switch b_ {
 						case 0:
-							break
+							s_ = def.Null
 						case 1:
 							break
+						case 2:
+							v64_ = 0
+							for shift = uint(0); shift <= 56; shift += 7 {
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								if shift == 56 {
+									v64_ |= uint64(b_&0xFF) << shift
+									break
+								} else {
+									v64_ |= uint64(b_&0x7F) << shift
+									if b_ < 0x80 {
+										break
+									}
+								}
+							}
+							if s, ok := typeMap.Strings[v64_]; ok {
+								s_ = s
+							} else {
+								typeMap.UnresolvedStrings++
+							}
 						case 3:
 							v32_ = uint32(0)
 							for shift = uint(0); ; shift += 7 {
@@ -173,12 +212,64 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 									break
 								}
 							}
-							if pos+int(v32_) > l {
+							if int(v32_) > l-pos {
 								return 0, io.ErrUnexpectedEOF
 							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
 							bs := data[pos : pos+int(v32_)]
 							s_ = *(*string)(unsafe.Pointer(&bs))
 							pos += int(v32_)
+						case 4:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+								return 0, err
+							}
+						case 5:
+							v32_ = uint32(0)
+							for shift = uint(0); ; shift += 7 {
+								if shift >= 32 {
+									return 0, def.ErrIntOverflow
+								}
+								if pos >= l {
+									return 0, io.ErrUnexpectedEOF
+								}
+								b_ = data[pos]
+								pos++
+								v32_ |= uint32(b_&0x7F) << shift
+								if b_ < 0x80 {
+									break
+								}
+							}
+							if int(v32_) > l-pos {
+								return 0, io.ErrUnexpectedEOF
+							}
+							if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+								return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+							}
+							s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+							pos += int(v32_)
 						default:
 							return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 						}
@@ -229,6 +320,13 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 							*bind.Fields[bindFieldIndex].bool = b_ != 0
 						}
 					case typeMap.T_FLOAT:
+						if pos+4 > l {
+							return 0, io.ErrUnexpectedEOF
+						}
+						v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+						pos += 4
+						// skipping
+					case typeMap.T_SHORT:
 						v32_ = uint32(0)
 						for shift = uint(0); ; shift += 7 {
 							if shift >= 32 {
@@ -265,6 +363,12 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 									}
 								}
 								bindStackFrameArraySize = int(v32_)
+								if bindStackFrameArraySize > l-pos {
+									return 0, io.ErrUnexpectedEOF
+								}
+								if typeMap.Limits.ArrayLength > 0 && bindStackFrameArraySize > typeMap.Limits.ArrayLength {
+									return 0, fmt.Errorf("array length %d: %w", bindStackFrameArraySize, def.ErrLimitExceeded)
+								}
 							}
 							for bindStackFrameArrayIndex := 0; bindStackFrameArrayIndex < bindStackFrameArraySize; bindStackFrameArrayIndex++ {
 								if bindStackFrame.Fields[bindStackFrameFieldIndex].Field.ConstantPool {
@@ -305,9 +409,32 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 										pos++
 										switch b_ {
 										case 0:
-											break
+											s_ = def.Null
 										case 1:
 											break
+										case 2:
+											v64_ = 0
+											for shift = uint(0); shift <= 56; shift += 7 {
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												if shift == 56 {
+													v64_ |= uint64(b_&0xFF) << shift
+													break
+												} else {
+													v64_ |= uint64(b_&0x7F) << shift
+													if b_ < 0x80 {
+														break
+													}
+												}
+											}
+											if s, ok := typeMap.Strings[v64_]; ok {
+												s_ = s
+											} else {
+												typeMap.UnresolvedStrings++
+											}
 										case 3:
 											v32_ = uint32(0)
 											for shift = uint(0); ; shift += 7 {
@@ -324,12 +451,64 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 													break
 												}
 											}
-											if pos+int(v32_) > l {
+											if int(v32_) > l-pos {
 												return 0, io.ErrUnexpectedEOF
 											}
+											if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+												return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+											}
 											bs := data[pos : pos+int(v32_)]
 											s_ = *(*string)(unsafe.Pointer(&bs))
 											pos += int(v32_)
+										case 4:
+											v32_ = uint32(0)
+											for shift = uint(0); ; shift += 7 {
+												if shift >= 32 {
+													return 0, def.ErrIntOverflow
+												}
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												v32_ |= uint32(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+											if int(v32_) > l-pos {
+												return 0, io.ErrUnexpectedEOF
+											}
+											if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+												return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+											}
+											if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+												return 0, err
+											}
+										case 5:
+											v32_ = uint32(0)
+											for shift = uint(0); ; shift += 7 {
+												if shift >= 32 {
+													return 0, def.ErrIntOverflow
+												}
+												if pos >= l {
+													return 0, io.ErrUnexpectedEOF
+												}
+												b_ = data[pos]
+												pos++
+												v32_ |= uint32(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+											if int(v32_) > l-pos {
+												return 0, io.ErrUnexpectedEOF
+											}
+											if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+												return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+											}
+											s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+											pos += int(v32_)
 										default:
 											return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 										}
@@ -380,6 +559,13 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 										pos++
 										// skipping
 									case typeMap.T_FLOAT:
+										if pos+4 > l {
+											return 0, io.ErrUnexpectedEOF
+										}
+										v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+										pos += 4
+										// skipping
+									case typeMap.T_SHORT:
 										v32_ = uint32(0)
 										for shift = uint(0); ; shift += 7 {
 											if shift >= 32 {
@@ -448,9 +634,32 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 													pos++
 													switch b_ {
 													case 0:
-														break
+														s_ = def.Null
 													case 1:
 														break
+													case 2:
+														v64_ = 0
+														for shift = uint(0); shift <= 56; shift += 7 {
+															if pos >= l {
+																return 0, io.ErrUnexpectedEOF
+															}
+															b_ = data[pos]
+															pos++
+															if shift == 56 {
+																v64_ |= uint64(b_&0xFF) << shift
+																break
+															} else {
+																v64_ |= uint64(b_&0x7F) << shift
+																if b_ < 0x80 {
+																	break
+																}
+															}
+														}
+														if s, ok := typeMap.Strings[v64_]; ok {
+															s_ = s
+														} else {
+															typeMap.UnresolvedStrings++
+														}
 													case 3:
 														v32_ = uint32(0)
 														for shift = uint(0); ; shift += 7 {
@@ -467,32 +676,68 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 																break
 															}
 														}
-														if pos+int(v32_) > l {
+														if int(v32_) > l-pos {
 															return 0, io.ErrUnexpectedEOF
 														}
+														if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+															return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+														}
 														bs := data[pos : pos+int(v32_)]
 														s_ = *(*string)(unsafe.Pointer(&bs))
 														pos += int(v32_)
-													default:
-														return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
-													}
-												} else if bindStackFrameSkipFieldType == typeMap.T_INT {
-													v32_ = uint32(0)
-													for shift = uint(0); ; shift += 7 {
-														if shift >= 32 {
-															return 0, def.ErrIntOverflow
+													case 4:
+														v32_ = uint32(0)
+														for shift = uint(0); ; shift += 7 {
+															if shift >= 32 {
+																return 0, def.ErrIntOverflow
+															}
+															if pos >= l {
+																return 0, io.ErrUnexpectedEOF
+															}
+															b_ = data[pos]
+															pos++
+															v32_ |= uint32(b_&0x7F) << shift
+															if b_ < 0x80 {
+																break
+															}
 														}
-														if pos >= l {
+														if int(v32_) > l-pos {
 															return 0, io.ErrUnexpectedEOF
 														}
-														b_ = data[pos]
-														pos++
-														v32_ |= uint32(b_&0x7F) << shift
-														if b_ < 0x80 {
-															break
+														if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+															return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+														}
+														if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+															return 0, err
 														}
+													case 5:
+														v32_ = uint32(0)
+														for shift = uint(0); ; shift += 7 {
+															if shift >= 32 {
+																return 0, def.ErrIntOverflow
+															}
+															if pos >= l {
+																return 0, io.ErrUnexpectedEOF
+															}
+															b_ = data[pos]
+															pos++
+															v32_ |= uint32(b_&0x7F) << shift
+															if b_ < 0x80 {
+																break
+															}
+														}
+														if int(v32_) > l-pos {
+															return 0, io.ErrUnexpectedEOF
+														}
+														if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+															return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+														}
+														s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+														pos += int(v32_)
+													default:
+														return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 													}
-												} else if bindStackFrameSkipFieldType == typeMap.T_FLOAT {
+												} else if bindStackFrameSkipFieldType == typeMap.T_INT || bindStackFrameSkipFieldType == typeMap.T_SHORT {
 													v32_ = uint32(0)
 													for shift = uint(0); ; shift += 7 {
 														if shift >= 32 {
@@ -508,6 +753,12 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 															break
 														}
 													}
+												} else if bindStackFrameSkipFieldType == typeMap.T_FLOAT {
+													if pos+4 > l {
+														return 0, io.ErrUnexpectedEOF
+													}
+													v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+													pos += 4
 												} else if bindStackFrameSkipFieldType == typeMap.T_LONG {
 													v64_ = 0
 													for shift = uint(0); shift <= 56; shift += 7 {
@@ -596,9 +847,32 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 									pos++
 									switch b_ {
 									case 0:
-										break
+										s_ = def.Null
 									case 1:
 										break
+									case 2:
+										v64_ = 0
+										for shift = uint(0); shift <= 56; shift += 7 {
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											if shift == 56 {
+												v64_ |= uint64(b_&0xFF) << shift
+												break
+											} else {
+												v64_ |= uint64(b_&0x7F) << shift
+												if b_ < 0x80 {
+													break
+												}
+											}
+										}
+										if s, ok := typeMap.Strings[v64_]; ok {
+											s_ = s
+										} else {
+											typeMap.UnresolvedStrings++
+										}
 									case 3:
 										v32_ = uint32(0)
 										for shift = uint(0); ; shift += 7 {
@@ -615,32 +889,68 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 												break
 											}
 										}
-										if pos+int(v32_) > l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
 										bs := data[pos : pos+int(v32_)]
 										s_ = *(*string)(unsafe.Pointer(&bs))
 										pos += int(v32_)
-									default:
-										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
-									}
-								} else if bindSkipFieldType == typeMap.T_INT {
-									v32_ = uint32(0)
-									for shift = uint(0); ; shift += 7 {
-										if shift >= 32 {
-											return 0, def.ErrIntOverflow
+									case 4:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
 										}
-										if pos >= l {
+										if int(v32_) > l-pos {
 											return 0, io.ErrUnexpectedEOF
 										}
-										b_ = data[pos]
-										pos++
-										v32_ |= uint32(b_&0x7F) << shift
-										if b_ < 0x80 {
-											break
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
+										if s_, pos, err = def.DecodeCharArray(data, pos, int(v32_)); err != nil {
+											return 0, err
+										}
+									case 5:
+										v32_ = uint32(0)
+										for shift = uint(0); ; shift += 7 {
+											if shift >= 32 {
+												return 0, def.ErrIntOverflow
+											}
+											if pos >= l {
+												return 0, io.ErrUnexpectedEOF
+											}
+											b_ = data[pos]
+											pos++
+											v32_ |= uint32(b_&0x7F) << shift
+											if b_ < 0x80 {
+												break
+											}
+										}
+										if int(v32_) > l-pos {
+											return 0, io.ErrUnexpectedEOF
 										}
+										if typeMap.Limits.StringLength > 0 && int(v32_) > typeMap.Limits.StringLength {
+											return 0, fmt.Errorf("string length %d: %w", int(v32_), def.ErrLimitExceeded)
+										}
+										s_ = def.DecodeLatin1(data[pos : pos+int(v32_)])
+										pos += int(v32_)
+									default:
+										return 0, fmt.Errorf("unknown string type %d at %d", b_, pos)
 									}
-								} else if bindSkipFieldType == typeMap.T_FLOAT {
+								} else if bindSkipFieldType == typeMap.T_INT || bindSkipFieldType == typeMap.T_SHORT {
 									v32_ = uint32(0)
 									for shift = uint(0); ; shift += 7 {
 										if shift >= 32 {
@@ -656,6 +966,12 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 											break
 										}
 									}
+								} else if bindSkipFieldType == typeMap.T_FLOAT {
+									if pos+4 > l {
+										return 0, io.ErrUnexpectedEOF
+									}
+									v32_ = uint32(data[pos])<<24 | uint32(data[pos+1])<<16 | uint32(data[pos+2])<<8 | uint32(data[pos+3])
+									pos += 4
 								} else if bindSkipFieldType == typeMap.T_LONG {
 									v64_ = 0
 									for shift = uint(0); shift <= 56; shift += 7 {
@@ -689,8 +1005,8 @@ func (this *StackTraceList) Parse(data []byte, bind *BindStackTrace, bindStackFr
 				}
 			}
 		}
-		this.StackTrace[i] = bind.Temp
-		this.IDMap[id] = uint32(i)
+		this.StackTrace[base+i] = bind.Temp
+		this.IDMap[id] = uint32(base + i)
 	}
 	return pos, nil
 }
@@ -1,6 +1,7 @@
 package pprof
 
 import (
+	"strconv"
 	"time"
 
 	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
@@ -9,10 +10,82 @@ import (
 
 type Labels []*typesv1.LabelPair
 
+// ParseInput overrides the time range and sampling rate of a recording. Zero
+// values leave them to the recording: the time range of its chunks and the
+// sampling intervals of its active settings.
 type ParseInput struct {
-	StartTime  time.Time
-	EndTime    time.Time
+	StartTime time.Time
+	EndTime   time.Time
+	// SampleRate in Hz of the cpu and wall profiles
 	SampleRate int64
+
+	// BucketDuration slices the profiles into windows of this length, aligned
+	// to the start of the time range. Zero keeps a single window.
+	BucketDuration time.Duration
+	// SampleTimestamps keeps a sample per event, labelled with the event time
+	// in nanoseconds since epoch, instead of aggregating equal stacks.
+	SampleTimestamps bool
+
+	// LabelExtractor labels the samples of each tracing context, TraceLabels
+	// when nil.
+	LabelExtractor LabelExtractor
+
+	// IO adds the file_io and socket_io profiles of the jdk.FileRead,
+	// jdk.FileWrite, jdk.SocketRead and jdk.SocketWrite events, weighted by
+	// duration and by bytes and labelled with the path or the host:port.
+	IO bool
+	// IOIncludeRMI keeps the socket I/O of the RMI transport in the socket_io
+	// and offcpu profiles, the JMX connections of tools such as JMC. It is
+	// left out by default.
+	IOIncludeRMI bool
+	// OffCPU adds the offcpu profile of the time threads spent blocked: in
+	// monitors, parked, sleeping, in file and socket I/O and loading classes.
+	// Its samples end with a frame naming what they waited for, such as
+	// [park] or [socket read host:port]. The jdk.Compilation events are left
+	// out, they block the compiler threads rather than the application.
+	OffCPU bool
+	// NativeMemLeaks turns the nativemem profile of the async-profiler
+	// profiler.Malloc events into a profile of the allocations not freed by
+	// the end of the recording, matching the profiler.Free events to them by
+	// address. Its sample types are inuse_objects and inuse_space instead of
+	// malloc_objects and malloc_space.
+	NativeMemLeaks bool
+
+	// Recover keeps the profiles of the complete chunks and of the events read
+	// from a truncated last chunk, such as the one left by a crashed JVM.
+	// ParseJFR then returns them along with a *parser.RecoveredError.
+	Recover bool
+}
+
+// SampleContext is the tracing context of the events of a sample.
+type SampleContext struct {
+	// ContextID of async-profiler, which the LabelsSnapshot already resolves.
+	ContextID uint64
+	// SpanID and LocalRootSpanID of the Datadog events.
+	SpanID          uint64
+	LocalRootSpanID uint64
+	// Endpoint of the local root span, joined from the datadog.Endpoint events.
+	Endpoint string
+}
+
+// LabelExtractor returns the labels of the samples taken in a context. It is
+// called once per context, after all the events are read.
+type LabelExtractor func(ctx SampleContext) Labels
+
+// TraceLabels labels the samples of Datadog events with their span_id,
+// local_root_span_id and trace_endpoint, when known.
+func TraceLabels(ctx SampleContext) Labels {
+	var labels Labels
+	if ctx.SpanID != 0 {
+		labels = append(labels, &typesv1.LabelPair{Name: "span_id", Value: strconv.FormatUint(ctx.SpanID, 10)})
+	}
+	if ctx.LocalRootSpanID != 0 {
+		labels = append(labels, &typesv1.LabelPair{Name: "local_root_span_id", Value: strconv.FormatUint(ctx.LocalRootSpanID, 10)})
+	}
+	if ctx.Endpoint != "" {
+		labels = append(labels, &typesv1.LabelPair{Name: "trace_endpoint", Value: ctx.Endpoint})
+	}
+	return labels
 }
 
 type Profiles struct {
@@ -3,10 +3,17 @@ package pprof
 import (
 	"fmt"
 	"io"
+	"math"
+	"net"
+	"strconv"
 
 	"github.com/grafana/jfr-parser/parser"
 )
 
+// ParseJFR converts a recording to pprof profiles. The ParseInput values are
+// optional overrides of what the recording provides, pi may be nil. With
+// ParseInput.Recover, the profiles of a truncated recording are returned along
+// with a *parser.RecoveredError.
 func ParseJFR(body []byte, pi *ParseInput, jfrLabels *LabelsSnapshot) (res *Profiles, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -15,8 +22,13 @@ func ParseJFR(body []byte, pi *ParseInput, jfrLabels *LabelsSnapshot) (res *Prof
 	}()
 	p := parser.NewParser(body, parser.Options{
 		SymbolProcessor: parser.ProcessSymbols,
+		Recover:         pi != nil && pi.Recover,
 	})
-	return parse(p, pi, jfrLabels)
+	res, err = parse(p, pi, jfrLabels)
+	if err == nil && p.RecoveredError() != nil {
+		err = &parser.RecoveredError{Err: p.RecoveredError()}
+	}
+	return res, err
 }
 
 func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnapshot) (result *Profiles, err error) {
@@ -25,6 +37,7 @@ func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnaps
 	builders := newJfrPprofBuilders(parser, jfrLabels, piOriginal)
 
 	var values = [2]int64{1, 0}
+	chunk := parser.ChunkHeader()
 
 	for {
 		typ, err := parser.ParseEvent()
@@ -34,31 +47,113 @@ func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnaps
 			}
 			return nil, fmt.Errorf("jfr parser ParseEvent error: %w", err)
 		}
+		if h := parser.ChunkHeader(); h != chunk {
+			chunk = h
+			builders.newChunk()
+			builders.addChunk(h)
+		}
 
 		switch typ {
 		case parser.TypeMap.T_EXECUTION_SAMPLE:
 			ts := parser.GetThreadState(parser.ExecutionSample.State)
 			if ts != nil && ts.Name != "STATE_SLEEPING" {
-				builders.addStacktrace(sampleTypeCPU, parser.ExecutionSample.ContextId, parser.ExecutionSample.StackTrace, values[:1])
+				builders.addThreadStacktrace(sampleTypeCPU, parser.ExecutionSample.ContextId, parser.ExecutionSample.SampledThread, parser.ExecutionSample.StackTrace, parser.ExecutionSample.StartTime, values[:1])
 			}
 			if event == "wall" {
-				builders.addStacktrace(sampleTypeWall, parser.ExecutionSample.ContextId, parser.ExecutionSample.StackTrace, values[:1])
+				builders.addThreadStacktrace(sampleTypeWall, parser.ExecutionSample.ContextId, parser.ExecutionSample.SampledThread, parser.ExecutionSample.StackTrace, parser.ExecutionSample.StartTime, values[:1])
 			}
 		case parser.TypeMap.T_ALLOC_IN_NEW_TLAB:
 			values[1] = int64(parser.ObjectAllocationInNewTLAB.TlabSize)
-			builders.addStacktrace(sampleTypeInTLAB, parser.ObjectAllocationInNewTLAB.ContextId, parser.ObjectAllocationInNewTLAB.StackTrace, values[:2])
+			builders.addStacktrace(sampleTypeInTLAB, parser.ObjectAllocationInNewTLAB.ContextId, parser.ObjectAllocationInNewTLAB.StackTrace, parser.ObjectAllocationInNewTLAB.StartTime, values[:2])
 		case parser.TypeMap.T_ALLOC_OUTSIDE_TLAB:
 			values[1] = int64(parser.ObjectAllocationOutsideTLAB.AllocationSize)
-			builders.addStacktrace(sampleTypeOutTLAB, parser.ObjectAllocationOutsideTLAB.ContextId, parser.ObjectAllocationOutsideTLAB.StackTrace, values[:2])
+			builders.addStacktrace(sampleTypeOutTLAB, parser.ObjectAllocationOutsideTLAB.ContextId, parser.ObjectAllocationOutsideTLAB.StackTrace, parser.ObjectAllocationOutsideTLAB.StartTime, values[:2])
 		case parser.TypeMap.T_MONITOR_ENTER:
-			values[1] = int64(parser.JavaMonitorEnter.Duration)
-			builders.addStacktrace(sampleTypeLock, parser.JavaMonitorEnter.ContextId, parser.JavaMonitorEnter.StackTrace, values[:2])
+			values[1] = int64(parser.TicksToDuration(parser.JavaMonitorEnter.Duration))
+			builders.addStacktrace(sampleTypeLock, parser.JavaMonitorEnter.ContextId, parser.JavaMonitorEnter.StackTrace, parser.JavaMonitorEnter.StartTime, values[:2])
+			builders.addOffCPU(builders.classReason("monitor", parser.JavaMonitorEnter.MonitorClass), parser.JavaMonitorEnter.ContextId, parser.JavaMonitorEnter.StackTrace, parser.JavaMonitorEnter.StartTime, parser.JavaMonitorEnter.Duration)
 		case parser.TypeMap.T_THREAD_PARK:
-			values[1] = int64(parser.ThreadPark.Duration)
-			builders.addStacktrace(sampleTypeThreadPark, parser.ThreadPark.ContextId, parser.ThreadPark.StackTrace, values[:2])
+			values[1] = int64(parser.TicksToDuration(parser.ThreadPark.Duration))
+			builders.addStacktrace(sampleTypeThreadPark, parser.ThreadPark.ContextId, parser.ThreadPark.StackTrace, parser.ThreadPark.StartTime, values[:2])
+			builders.addOffCPU("[park]", parser.ThreadPark.ContextId, parser.ThreadPark.StackTrace, parser.ThreadPark.StartTime, parser.ThreadPark.Duration)
 		case parser.TypeMap.T_LIVE_OBJECT:
-			builders.addStacktrace(sampleTypeLiveObject, 0, parser.LiveObject.StackTrace, values[:1])
+			builders.addStacktrace(sampleTypeLiveObject, 0, parser.LiveObject.StackTrace, parser.LiveObject.StartTime, values[:1])
+		case parser.TypeMap.T_MALLOC:
+			builders.addMalloc(&parser.Malloc)
+		case parser.TypeMap.T_FREE:
+			builders.addFree(parser.Free.Address)
+		case parser.TypeMap.T_DD_EXECUTION_SAMPLE:
+			e := &parser.DatadogExecutionSample
+			values[0] = datadogWeight(e.Weight)
+			builders.addSpanStacktrace(sampleTypeCPU, 0, span{e.SpanId, e.LocalRootSpanId}, e.StackTrace, e.StartTime, values[:1])
+			values[0] = 1
+		case parser.TypeMap.T_DD_METHOD_SAMPLE:
+			e := &parser.DatadogMethodSample
+			values[0] = datadogWeight(e.Weight)
+			builders.addSpanStacktrace(sampleTypeWall, 0, span{e.SpanId, e.LocalRootSpanId}, e.StackTrace, e.StartTime, values[:1])
+			values[0] = 1
+		case parser.TypeMap.T_DD_OBJECT_SAMPLE:
+			e := &parser.DatadogObjectSample
+			values[0], values[1] = upscaleObjects(e.Weight, e.Size)
+			builders.addSpanStacktrace(sampleTypeAlloc, 0, span{e.SpanId, e.LocalRootSpanId}, e.StackTrace, e.StartTime, values[:2])
+			values[0] = 1
+		case parser.TypeMap.T_DD_HEAP_LIVE_OBJECT:
+			e := &parser.DatadogHeapLiveObject
+			values[0], values[1] = upscaleObjects(e.Weight, e.Size)
+			builders.addSpanStacktrace(sampleTypeLiveHeap, 0, span{e.SpanId, e.LocalRootSpanId}, e.StackTrace, e.StartTime, values[:2])
+			values[0] = 1
+		case parser.TypeMap.T_DD_EXCEPTION_SAMPLE:
+			e := &parser.DatadogExceptionSample
+			builders.addSpanStacktrace(sampleTypeException, 0, span{e.SpanId, e.LocalRootSpanId}, e.StackTrace, e.StartTime, values[:1])
+		case parser.TypeMap.T_EXCEPTION_THROW:
+			e := &parser.JavaExceptionThrow
+			builders.addThrowable(e.StackTrace, e.ThrownClass, e.StartTime, false)
+		case parser.TypeMap.T_ERROR_THROW:
+			e := &parser.JavaErrorThrow
+			builders.addThrowable(e.StackTrace, e.ThrownClass, e.StartTime, true)
+		case parser.TypeMap.T_MONITOR_WAIT:
+			e := &parser.JavaMonitorWait
+			builders.addOffCPU(builders.classReason("wait", e.MonitorClass), 0, e.StackTrace, e.StartTime, e.Duration)
+		case parser.TypeMap.T_THREAD_SLEEP:
+			e := &parser.ThreadSleep
+			builders.addOffCPU("[sleep]", 0, e.StackTrace, e.StartTime, e.Duration)
+		case parser.TypeMap.T_CLASS_LOAD:
+			e := &parser.ClassLoad
+			builders.addOffCPU(builders.classReason("class load", e.LoadedClass), 0, e.StackTrace, e.StartTime, e.Duration)
+		case parser.TypeMap.T_FILE_READ:
+			e := &parser.FileRead
+			target := ioTarget{name: e.Path}
+			if builders.ioAccepted(target, e.StackTrace) {
+				builders.addIO(target, e.StackTrace, e.StartTime, e.Duration, int64(e.BytesRead))
+				builders.addOffCPU(target.reason(), 0, e.StackTrace, e.StartTime, e.Duration)
+			}
+		case parser.TypeMap.T_FILE_WRITE:
+			e := &parser.FileWrite
+			target := ioTarget{write: true, name: e.Path}
+			if builders.ioAccepted(target, e.StackTrace) {
+				builders.addIO(target, e.StackTrace, e.StartTime, e.Duration, int64(e.BytesWritten))
+				builders.addOffCPU(target.reason(), 0, e.StackTrace, e.StartTime, e.Duration)
+			}
+		case parser.TypeMap.T_SOCKET_READ:
+			e := &parser.SocketRead
+			target := ioTarget{socket: true, name: socketAddress(e.Host, e.Address, e.Port)}
+			if builders.ioAccepted(target, e.StackTrace) {
+				builders.addIO(target, e.StackTrace, e.StartTime, e.Duration, int64(e.BytesRead))
+				builders.addOffCPU(target.reason(), 0, e.StackTrace, e.StartTime, e.Duration)
+			}
+		case parser.TypeMap.T_SOCKET_WRITE:
+			e := &parser.SocketWrite
+			target := ioTarget{socket: true, write: true, name: socketAddress(e.Host, e.Address, e.Port)}
+			if builders.ioAccepted(target, e.StackTrace) {
+				builders.addIO(target, e.StackTrace, e.StartTime, e.Duration, int64(e.BytesWritten))
+				builders.addOffCPU(target.reason(), 0, e.StackTrace, e.StartTime, e.Duration)
+			}
+		case parser.TypeMap.T_VIRTUAL_THREAD_PINNED:
+			builders.addPinned(&parser.VirtualThreadPinned)
+		case parser.TypeMap.T_DD_ENDPOINT:
+			builders.addEndpoint(&parser.DatadogEndpoint)
 		case parser.TypeMap.T_ACTIVE_SETTING:
+			builders.addSetting(&parser.ActiveSetting)
 			if parser.ActiveSetting.Name == "event" {
 				event = parser.ActiveSetting.Value
 			}
@@ -70,3 +165,34 @@ func parse(parser *parser.Parser, piOriginal *ParseInput, jfrLabels *LabelsSnaps
 
 	return result, nil
 }
+
+// datadogWeight is the number of samples a cpu or wall sample of the Datadog
+// profiler stands for, older versions do not record it.
+func datadogWeight(weight uint64) int64 {
+	if weight == 0 {
+		return 1
+	}
+	return int64(weight)
+}
+
+// upscaleObjects returns the number and the size of the objects a sampled
+// allocation of the Datadog profiler stands for.
+func upscaleObjects(weight float32, size uint64) (int64, int64) {
+	if weight <= 0 {
+		return 1, int64(size)
+	}
+	objects := int64(math.Round(float64(weight)))
+	if objects < 1 {
+		objects = 1
+	}
+	return objects, int64(math.Round(float64(weight) * float64(size)))
+}
+
+// socketAddress is the host:port of a socket event, the host is empty when
+// the JVM did not resolve the address.
+func socketAddress(host, address string, port uint32) string {
+	if host == "" {
+		host = address
+	}
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
@@ -2,16 +2,20 @@ package pprof
 
 import (
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	gpprof "github.com/google/pprof/profile"
+	"github.com/grafana/jfr-parser/internal/jfrtest"
+	"github.com/grafana/jfr-parser/parser"
 	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
 	"github.com/k0kubun/pp/v3"
 	"github.com/stretchr/testify/assert"
@@ -116,6 +120,208 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseRecordedPeriodAndTimeRange(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"async-profiler.jfr.gz")
+	p := parser.NewParser(jfr, parser.Options{})
+	_, err := p.ParseEvent()
+	require.NoError(t, err)
+	start := int64(p.ChunkHeader().StartNanos)
+	duration := int64(p.ChunkHeader().DurationNanos)
+
+	recorded, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	overridden, err := ParseJFR(jfr, parseInput, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(recorded.Profiles))
+
+	expected := map[string]*profilev1.Profile{}
+	for _, profile := range overridden.Profiles {
+		expected[sampleTypeName(profile.Profile)] = profile.Profile
+	}
+	periods := map[string]int64{}
+	for _, profile := range recorded.Profiles {
+		typ := sampleTypeName(profile.Profile)
+		periods[typ] = profile.Profile.Period
+		assert.Equal(t, start, profile.Profile.TimeNanos)
+		assert.Equal(t, duration, profile.Profile.DurationNanos)
+		// interval=10000000 matches the 100Hz of parseInput
+		assert.Equal(t, expected[typ].Period, profile.Profile.Period)
+		assert.Equal(t, stackCollapseProto(expected[typ], true), stackCollapseProto(profile.Profile, true))
+	}
+	assert.Equal(t, map[string]int64{
+		"cpu":                        10_000_000,
+		"alloc_in_new_tlab_objects":  524288,
+		"alloc_outside_tlab_objects": 524288,
+	}, periods)
+
+	// event=wall with usedWallInterval=10000000
+	recorded, err = ParseJFR(readGzipFile(t, testdataDir+"FastSlow_2024_01_16_180855.jfr.gz"), &ParseInput{}, nil)
+	require.NoError(t, err)
+	for _, profile := range recorded.Profiles {
+		assert.Equal(t, int64(10_000_000), profile.Profile.Period)
+	}
+}
+
+func TestParseBuckets(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"async-profiler.jfr.gz")
+	whole, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	expected := map[string]map[string][]int64{}
+	for _, profile := range whole.Profiles {
+		expected[sampleTypeName(profile.Profile)] = parseCollapsed(t, stackCollapseProto(profile.Profile, true))
+	}
+
+	const bucket = 10 * time.Second
+	buckets, err := ParseJFR(jfr, &ParseInput{BucketDuration: bucket}, nil)
+	require.NoError(t, err)
+	require.Greater(t, len(buckets.Profiles), len(whole.Profiles))
+	start := whole.Profiles[0].Profile.TimeNanos
+	actual := map[string]map[string][]int64{}
+	prev := int64(0)
+	for _, profile := range buckets.Profiles {
+		assert.Equal(t, int64(bucket), profile.Profile.DurationNanos)
+		assert.Equal(t, int64(0), (profile.Profile.TimeNanos-start)%int64(bucket))
+		assert.GreaterOrEqual(t, profile.Profile.TimeNanos, prev)
+		prev = profile.Profile.TimeNanos
+
+		typ := sampleTypeName(profile.Profile)
+		if actual[typ] == nil {
+			actual[typ] = map[string][]int64{}
+		}
+		addCollapsed(actual[typ], parseCollapsed(t, stackCollapseProto(profile.Profile, true)))
+	}
+	assert.Equal(t, expected, actual)
+
+	timestamped, err := ParseJFR(jfr, &ParseInput{SampleTimestamps: true}, nil)
+	require.NoError(t, err)
+	actual = map[string]map[string][]int64{}
+	for _, profile := range timestamped.Profiles {
+		p := profile.Profile
+		for _, sample := range p.Sample {
+			// the thread kind of the execution samples is also a label
+			var label *profilev1.Label
+			for _, l := range sample.Label {
+				if p.StringTable[l.Key] == "timestamp" {
+					label = l
+				}
+			}
+			require.NotNil(t, label)
+			assert.Equal(t, "nanoseconds", p.StringTable[label.NumUnit])
+			assert.GreaterOrEqual(t, label.Num, p.TimeNanos)
+			assert.LessOrEqual(t, label.Num, p.TimeNanos+p.DurationNanos)
+		}
+		typ := sampleTypeName(p)
+		actual[typ] = parseCollapsed(t, stackCollapseProto(p, true))
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func sampleTypeName(p *profilev1.Profile) string {
+	return p.StringTable[p.SampleType[0].Type]
+}
+
+func addCollapsed(dst, src map[string][]int64) {
+	for stack, values := range src {
+		if dst[stack] == nil {
+			dst[stack] = make([]int64, len(values))
+		}
+		for i, v := range values {
+			dst[stack][i] += v
+		}
+	}
+}
+
+func TestParseSettingDuration(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"20 ms":      20_000_000,
+		"1 s":        1_000_000_000,
+		"10 us":      10_000,
+		"everyChunk": 0,
+		"0 ms":       0,
+		"5 parsecs":  0,
+	} {
+		v, ok := parseSettingDuration(s)
+		assert.Equal(t, expected, v, s)
+		assert.Equal(t, expected != 0, ok, s)
+	}
+}
+
+func TestParseMultiChunk(t *testing.T) {
+	// the constant pool IDs of the two recordings overlap, concatenated they must
+	// still convert to the sum of their golden profiles
+	parts := []string{"cortex-dev-01__kafka-0__cpu__0", "cortex-dev-01__kafka-0__cpu__1"}
+	var jfr []byte
+	expected := map[string][]int64{}
+	for _, part := range parts {
+		jfr = append(jfr, readGzipFile(t, testdataDir+part+".jfr.gz")...)
+		collapsed := readGzipFile(t, fmt.Sprintf("%s%s_0_process_cpu_cpu__nanoseconds_expected_collapsed.txt.gz", testdataDir, part))
+		addCollapsed(expected, parseCollapsed(t, string(collapsed)))
+	}
+
+	profiles, err := ParseJFR(jfr, parseInput, new(LabelsSnapshot))
+	require.NoError(t, err)
+	gprofiles := toGoogleProfiles(t, profiles.Profiles)
+	require.Equal(t, 1, len(gprofiles))
+	assert.Equal(t, "process_cpu_cpu__nanoseconds", gprofiles[0].metric)
+	require.NoError(t, gprofiles[0].profile.CheckValid())
+
+	actual := parseCollapsed(t, stackCollapseProto(gprofiles[0].proto, true))
+	assert.Equal(t, expected, actual)
+
+	functions := map[string]bool{}
+	for _, f := range gprofiles[0].profile.Function {
+		assert.False(t, functions[f.Name], "duplicate function %s", f.Name)
+		functions[f.Name] = true
+	}
+}
+
+func TestParseRecover(t *testing.T) {
+	jfr := readGzipFile(t, testdataDir+"cortex-dev-01__kafka-0__cpu__0.jfr.gz")
+	// a crashed JVM leaves a truncated last chunk behind
+	truncated := append(append([]byte{}, jfr...), jfr[:len(jfr)/2]...)
+
+	_, err := ParseJFR(truncated, nil, nil)
+	require.Error(t, err)
+
+	profiles, err := ParseJFR(truncated, &ParseInput{Recover: true}, nil)
+	var recovered *parser.RecoveredError
+	require.ErrorAs(t, err, &recovered)
+	complete, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles.Profiles, 1)
+	assert.Greater(t, len(profiles.Profiles[0].Profile.Sample), 0)
+	assert.GreaterOrEqual(t, totalValue(profiles.Profiles[0].Profile), totalValue(complete.Profiles[0].Profile))
+
+	// input which is not a recording is not recovered from
+	_, err = ParseJFR(make([]byte, 5000), &ParseInput{Recover: true}, nil)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &recovered))
+}
+
+func totalValue(p *profilev1.Profile) int64 {
+	var total int64
+	for _, s := range p.Sample {
+		total += s.Value[0]
+	}
+	return total
+}
+
+func parseCollapsed(t *testing.T, collapsed string) map[string][]int64 {
+	res := map[string][]int64{}
+	for _, line := range strings.Split(collapsed, "\n") {
+		i := strings.LastIndex(line, " [")
+		require.True(t, i > 0 && strings.HasSuffix(line, "]"), line)
+		var values []int64
+		for _, f := range strings.Fields(line[i+2 : len(line)-1]) {
+			v, err := strconv.ParseInt(f, 10, 64)
+			require.NoError(t, err)
+			values = append(values, v)
+		}
+		res[line[:i]] = values
+	}
+	return res
+}
+
 func profileToString(t *testing.T, profile gprofile) string {
 	res := profile.profile.String()
 	re := regexp.MustCompile("\nTime: ([^\n]+)\n")
@@ -280,3 +486,568 @@ func stackCollapseProto(p *profilev1.Profile, lineNumbers bool) string {
 	}
 	return strings.Join(res, "\n")
 }
+
+// type IDs of the Datadog events of datadogRecording
+const (
+	ddExecutionSample = 116 + iota
+	ddMethodSample
+	ddObjectSample
+	ddHeapLiveObject
+	ddExceptionSample
+	ddEndpoint
+)
+
+func datadogRecording() *jfrtest.Recording {
+	r := jfrtest.New()
+	common := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+	}
+	sample := append(common[:3:3],
+		jfrtest.Field{Name: "spanId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "localRootSpanId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "weight", Class: jfrtest.Long})
+	object := append(common[:3:3],
+		jfrtest.Field{Name: "objectClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "size", Class: jfrtest.Long},
+		jfrtest.Field{Name: "weight", Class: jfrtest.Float},
+		jfrtest.Field{Name: "spanId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "localRootSpanId", Class: jfrtest.Long})
+	r.Class(ddExecutionSample, "datadog.ExecutionSample", sample...)
+	r.Class(ddMethodSample, "datadog.MethodSample", sample...)
+	r.Class(ddObjectSample, "datadog.ObjectSample", object...)
+	r.Class(ddHeapLiveObject, "datadog.HeapLiveObject", object...)
+	r.Class(ddExceptionSample, "datadog.ExceptionSample", append(common[:3:3],
+		jfrtest.Field{Name: "type", Class: jfrtest.String},
+		jfrtest.Field{Name: "localRootSpanId", Class: jfrtest.Long},
+		jfrtest.Field{Name: "spanId", Class: jfrtest.Long})...)
+	r.Class(ddEndpoint, "datadog.Endpoint",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "endpoint", Class: jfrtest.String},
+		jfrtest.Field{Name: "localRootSpanId", Class: jfrtest.Long})
+
+	r.Constant(jfrtest.Symbol, 1, "Foo")
+	r.Constant(jfrtest.Symbol, 2, "bar")
+	r.Constant(jfrtest.Symbol, 3, "baz")
+	r.Constant(jfrtest.Class, 1, uint64(1))
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(2))
+	r.Constant(jfrtest.Method, 2, uint64(1), uint64(3))
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.StackTrace(1, 1)
+	r.StackTrace(2, 2, 1)
+
+	r.Event(ddExecutionSample, uint64(1000), uint64(1), uint64(1), uint64(5), uint64(3), uint64(2))
+	r.Event(ddExecutionSample, uint64(2000), uint64(1), uint64(1), uint64(5), uint64(3), uint64(1))
+	r.Event(ddExecutionSample, uint64(3000), uint64(1), uint64(2), uint64(0), uint64(0), uint64(0))
+	r.Event(ddMethodSample, uint64(4000), uint64(1), uint64(2), uint64(7), uint64(3), uint64(1))
+	r.Event(ddObjectSample, uint64(5000), uint64(1), uint64(1), uint64(1), uint64(100), float32(2.5), uint64(0), uint64(0))
+	r.Event(ddHeapLiveObject, uint64(6000), uint64(1), uint64(2), uint64(1), uint64(64), float32(1), uint64(0), uint64(0))
+	r.Event(ddExceptionSample, uint64(7000), uint64(1), uint64(1), "java.lang.IllegalStateException", uint64(3), uint64(5))
+	return r
+}
+
+func TestParseDatadog(t *testing.T) {
+	r := datadogRecording()
+	profiles, err := ParseJFR(r.Bytes(), nil, nil)
+	require.NoError(t, err)
+	actual := labelledSamples(t, profiles)
+	metrics := map[string]string{}
+	for _, p := range profiles.Profiles {
+		metrics[sampleTypeName(p.Profile)] = p.Metric
+	}
+
+	const spanLabels = "span_id=5,local_root_span_id=3"
+	assert.Equal(t, map[string]map[labelledStack][]int64{
+		"cpu": {
+			{"Foo.bar", spanLabels}: {3 * defaultCPUPeriod},
+			{"Foo.bar;Foo.baz", ""}: {defaultCPUPeriod},
+		},
+		"wall": {
+			{"Foo.bar;Foo.baz", "span_id=7,local_root_span_id=3"}: {defaultWallPeriod},
+		},
+		"alloc_objects": {
+			{"Foo.bar", ""}: {3, 250},
+		},
+		"inuse_objects": {
+			{"Foo.bar;Foo.baz", ""}: {1, 64},
+		},
+		"exceptions": {
+			{"Foo.bar", spanLabels}: {1},
+		},
+	}, actual)
+	assert.Equal(t, map[string]string{
+		"cpu":           "process_cpu",
+		"wall":          "wall",
+		"alloc_objects": "memory",
+		"inuse_objects": "memory",
+		"exceptions":    "exceptions",
+	}, metrics)
+}
+
+func TestParseDatadogIntervals(t *testing.T) {
+	const ddActiveSetting = 130
+	r := datadogRecording()
+	r.Class(ddActiveSetting, "jdk.ActiveSetting",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "id", Class: jfrtest.Long},
+		jfrtest.Field{Name: "name", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	// the object sample interval comes last and must not be taken for the
+	// cpu interval
+	r.Event(ddActiveSetting, uint64(0), uint64(ddExecutionSample), "interval", "20000000")
+	r.Event(ddActiveSetting, uint64(0), uint64(ddMethodSample), "interval", "40000000")
+	r.Event(ddActiveSetting, uint64(0), uint64(ddObjectSample), "interval", "262144")
+	r.Event(ddActiveSetting, uint64(0), uint64(ddHeapLiveObject), "interval", "524288")
+
+	profiles, err := ParseJFR(r.Bytes(), nil, nil)
+	require.NoError(t, err)
+	periods := map[string]int64{}
+	for _, p := range profiles.Profiles {
+		periods[sampleTypeName(p.Profile)] = p.Profile.Period
+	}
+	assert.Equal(t, map[string]int64{
+		"cpu":           20_000_000,
+		"wall":          40_000_000,
+		"alloc_objects": 262144,
+		"inuse_objects": 524288,
+		"exceptions":    0,
+	}, periods)
+	cpu := labelledSamples(t, profiles)["cpu"]
+	assert.Equal(t, []int64{3 * 20_000_000}, cpu[labelledStack{"Foo.bar", "span_id=5,local_root_span_id=3"}])
+}
+
+func TestParseExceptions(t *testing.T) {
+	const (
+		exceptionThrow = 122 + iota
+		errorThrow
+	)
+	r := jfrtest.New()
+	throw := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "duration", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		{Name: "message", Class: jfrtest.String},
+		{Name: "thrownClass", Class: jfrtest.Class, CPool: true},
+	}
+	r.Class(exceptionThrow, "jdk.JavaExceptionThrow", throw...)
+	r.Class(errorThrow, "jdk.JavaErrorThrow", throw...)
+	for i, name := range []string{"java/io/IOException", "java/net/ConnectException", "java/lang/OutOfMemoryError", "java/lang/Throwable", "java/lang/Error", "Client"} {
+		r.Constant(jfrtest.Symbol, uint64(i+1), name)
+		r.Constant(jfrtest.Class, uint64(i+1), uint64(i+1))
+	}
+	r.Constant(jfrtest.Symbol, 10, "<init>")
+	r.Constant(jfrtest.Symbol, 11, "connect")
+	r.Constant(jfrtest.Method, 1, uint64(4), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(5), uint64(10))
+	r.Constant(jfrtest.Method, 3, uint64(6), uint64(11))
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.StackTrace(1, 1, 3)
+	r.StackTrace(2, 1, 2, 3)
+
+	r.Event(exceptionThrow, uint64(1000), uint64(0), uint64(1), uint64(1), "connection reset", uint64(1))
+	r.Event(exceptionThrow, uint64(2000), uint64(0), uint64(1), uint64(1), "", uint64(1))
+	r.Event(exceptionThrow, uint64(3000), uint64(0), uint64(1), uint64(1), "connection refused", uint64(2))
+	// the JVM records errors twice
+	r.Event(exceptionThrow, uint64(4000), uint64(0), uint64(1), uint64(2), "Java heap space", uint64(3))
+	r.Event(errorThrow, uint64(4000), uint64(0), uint64(1), uint64(2), "Java heap space", uint64(3))
+
+	profiles, err := ParseJFR(r.Bytes(), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles.Profiles, 1)
+	assert.Equal(t, "exceptions", profiles.Profiles[0].Metric)
+	assert.Equal(t, map[string]map[labelledStack][]int64{
+		"exceptions": {
+			{"Client.connect;java/lang/Throwable.<init>;java/io/IOException", "exception=java.io.IOException"}:                                      {2},
+			{"Client.connect;java/lang/Throwable.<init>;java/net/ConnectException", "exception=java.net.ConnectException"}:                          {1},
+			{"Client.connect;java/lang/Error.<init>;java/lang/Throwable.<init>;java/lang/OutOfMemoryError", "exception=java.lang.OutOfMemoryError"}: {1},
+		},
+	}, labelledSamples(t, profiles))
+}
+
+func TestParseIO(t *testing.T) {
+	const (
+		fileRead = 125 + iota
+		fileWrite
+		socketRead
+		socketWrite
+	)
+	r := jfrtest.New()
+	common := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "duration", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+	}
+	r.Class(fileRead, "jdk.FileRead", append(common[:4:4],
+		jfrtest.Field{Name: "path", Class: jfrtest.String},
+		jfrtest.Field{Name: "bytesRead", Class: jfrtest.Long},
+		jfrtest.Field{Name: "endOfFile", Class: jfrtest.Boolean})...)
+	r.Class(fileWrite, "jdk.FileWrite", append(common[:4:4],
+		jfrtest.Field{Name: "path", Class: jfrtest.String},
+		jfrtest.Field{Name: "bytesWritten", Class: jfrtest.Long})...)
+	r.Class(socketRead, "jdk.SocketRead", append(common[:4:4],
+		jfrtest.Field{Name: "host", Class: jfrtest.String},
+		jfrtest.Field{Name: "address", Class: jfrtest.String},
+		jfrtest.Field{Name: "port", Class: jfrtest.Int},
+		jfrtest.Field{Name: "timeout", Class: jfrtest.Long},
+		jfrtest.Field{Name: "bytesRead", Class: jfrtest.Long},
+		jfrtest.Field{Name: "endOfStream", Class: jfrtest.Boolean})...)
+	r.Class(socketWrite, "jdk.SocketWrite", append(common[:4:4],
+		jfrtest.Field{Name: "host", Class: jfrtest.String},
+		jfrtest.Field{Name: "address", Class: jfrtest.String},
+		jfrtest.Field{Name: "port", Class: jfrtest.Int},
+		jfrtest.Field{Name: "bytesWritten", Class: jfrtest.Long})...)
+	for i, name := range []string{"Store", "Client", "sun/rmi/transport/tcp/TCPTransport"} {
+		r.Constant(jfrtest.Symbol, uint64(i+1), name)
+		r.Constant(jfrtest.Class, uint64(i+1), uint64(i+1))
+	}
+	r.Constant(jfrtest.Symbol, 10, "load")
+	r.Constant(jfrtest.Symbol, 11, "call")
+	r.Constant(jfrtest.Symbol, 12, "handleMessages")
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(2), uint64(11))
+	r.Constant(jfrtest.Method, 3, uint64(3), uint64(12))
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.StackTrace(1, 1)
+	r.StackTrace(2, 2)
+	r.StackTrace(3, 3)
+
+	r.Event(fileRead, uint64(1000), uint64(100), uint64(1), uint64(1), "/data/a", uint64(4096), false)
+	r.Event(fileRead, uint64(2000), uint64(50), uint64(1), uint64(1), "/data/a", uint64(0), true)
+	r.Event(fileWrite, uint64(3000), uint64(200), uint64(1), uint64(1), "/data/b", uint64(512))
+	r.Event(socketRead, uint64(4000), uint64(1000), uint64(1), uint64(2), "db", "10.0.0.1", int32(5432), uint64(0), uint64(64), false)
+	r.Event(socketWrite, uint64(5000), uint64(10), uint64(1), uint64(2), "", "10.0.0.2", int32(8080), uint64(32))
+	// JMX clients polling over RMI
+	r.Event(socketRead, uint64(6000), uint64(5000), uint64(1), uint64(3), "jmc", "10.0.0.3", int32(1099), uint64(0), uint64(16), false)
+	jfr := r.Bytes()
+
+	profiles, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, profiles.Profiles)
+
+	profiles, err = ParseJFR(jfr, &ParseInput{IO: true}, nil)
+	require.NoError(t, err)
+	require.Len(t, profiles.Profiles, 2)
+	assert.Equal(t, "file_io", profiles.Profiles[0].Metric)
+	assert.Equal(t, "socket_io", profiles.Profiles[1].Metric)
+	assert.Equal(t, map[string]map[labelledStack][]int64{
+		"file_io_events": {
+			{"Store.load", "path=/data/a,operation=read"}:  {2, 150, 4096},
+			{"Store.load", "path=/data/b,operation=write"}: {1, 200, 512},
+		},
+		"socket_io_events": {
+			{"Client.call", "address=db:5432,operation=read"}:        {1, 1000, 64},
+			{"Client.call", "address=10.0.0.2:8080,operation=write"}: {1, 10, 32},
+		},
+	}, labelledSamples(t, profiles))
+
+	profiles, err = ParseJFR(jfr, &ParseInput{IO: true, IOIncludeRMI: true}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 5000, 16}, labelledSamples(t, profiles)["socket_io_events"][labelledStack{"sun/rmi/transport/tcp/TCPTransport.handleMessages", "address=jmc:1099,operation=read"}])
+}
+
+func TestParseOffCPU(t *testing.T) {
+	const (
+		monitorEnter = 104
+		threadPark   = 105
+		socketRead   = 127
+		threadSleep  = 130
+	)
+	r := jfrtest.New()
+	common := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "duration", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+	}
+	r.Class(monitorEnter, "jdk.JavaMonitorEnter", append(common[:4:4],
+		jfrtest.Field{Name: "monitorClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "previousOwner", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})...)
+	r.Class(threadPark, "jdk.ThreadPark", append(common[:4:4],
+		jfrtest.Field{Name: "parkedClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "timeout", Class: jfrtest.Long},
+		jfrtest.Field{Name: "until", Class: jfrtest.Long},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})...)
+	r.Class(socketRead, "jdk.SocketRead", append(common[:4:4],
+		jfrtest.Field{Name: "host", Class: jfrtest.String},
+		jfrtest.Field{Name: "address", Class: jfrtest.String},
+		jfrtest.Field{Name: "port", Class: jfrtest.Int},
+		jfrtest.Field{Name: "timeout", Class: jfrtest.Long},
+		jfrtest.Field{Name: "bytesRead", Class: jfrtest.Long},
+		jfrtest.Field{Name: "endOfStream", Class: jfrtest.Boolean})...)
+	r.Class(threadSleep, "jdk.ThreadSleep", append(common[:4:4],
+		jfrtest.Field{Name: "time", Class: jfrtest.Long})...)
+	for i, name := range []string{"Handler", "com/foo/Lock"} {
+		r.Constant(jfrtest.Symbol, uint64(i+1), name)
+		r.Constant(jfrtest.Class, uint64(i+1), uint64(i+1))
+	}
+	r.Constant(jfrtest.Symbol, 10, "handle")
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Thread, 1, "worker", uint64(1), "worker", uint64(1), false)
+	r.StackTrace(1, 1)
+
+	r.Event(monitorEnter, uint64(1000), uint64(300), uint64(1), uint64(1), uint64(2), uint64(0), uint64(0))
+	r.Event(threadPark, uint64(2000), uint64(100), uint64(1), uint64(1), uint64(0), uint64(0), uint64(0), uint64(0))
+	r.Event(threadPark, uint64(3000), uint64(200), uint64(1), uint64(1), uint64(0), uint64(0), uint64(0), uint64(0))
+	r.Event(socketRead, uint64(4000), uint64(1000), uint64(1), uint64(1), "db", "10.0.0.1", int32(5432), uint64(0), uint64(64), false)
+	r.Event(threadSleep, uint64(5000), uint64(10), uint64(1), uint64(1), uint64(10))
+	jfr := r.Bytes()
+
+	profiles, err := ParseJFR(jfr, &ParseInput{OffCPU: true}, nil)
+	require.NoError(t, err)
+	actual := labelledSamples(t, profiles)
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Handler.handle;[monitor com.foo.Lock]", ""}: {1, 300},
+		{"Handler.handle;[park]", ""}:                 {2, 300},
+		{"Handler.handle;[socket read db:5432]", ""}:  {1, 1000},
+		{"Handler.handle;[sleep]", ""}:                {1, 10},
+	}, actual["offcpu_events"])
+	var metrics []string
+	for _, p := range profiles.Profiles {
+		metrics = append(metrics, p.Metric)
+	}
+	assert.Equal(t, []string{"mutex", "block", "offcpu"}, metrics)
+}
+
+func TestParseLockPeriod(t *testing.T) {
+	const (
+		activeSetting = 103
+		monitorEnter  = 104
+		threadPark    = 105
+	)
+	r := jfrtest.New()
+	r.Class(activeSetting, "jdk.ActiveSetting",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "id", Class: jfrtest.Long},
+		jfrtest.Field{Name: "name", Class: jfrtest.String},
+		jfrtest.Field{Name: "value", Class: jfrtest.String})
+	common := []jfrtest.Field{
+		{Name: "startTime", Class: jfrtest.Long},
+		{Name: "duration", Class: jfrtest.Long},
+		{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+	}
+	r.Class(monitorEnter, "jdk.JavaMonitorEnter", append(common[:4:4],
+		jfrtest.Field{Name: "monitorClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "previousOwner", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})...)
+	r.Class(threadPark, "jdk.ThreadPark", append(common[:4:4],
+		jfrtest.Field{Name: "parkedClass", Class: jfrtest.Class, CPool: true},
+		jfrtest.Field{Name: "timeout", Class: jfrtest.Long},
+		jfrtest.Field{Name: "until", Class: jfrtest.Long},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})...)
+	r.Constant(jfrtest.Symbol, 1, "Handler")
+	r.Constant(jfrtest.Symbol, 10, "handle")
+	r.Constant(jfrtest.Class, 1, uint64(1))
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Thread, 1, "worker", uint64(1), "worker", uint64(1), false)
+	r.StackTrace(1, 1)
+
+	// --lock 10ms of async-profiler
+	r.Event(activeSetting, uint64(0), uint64(0), "lock", "10000000")
+	r.Event(monitorEnter, uint64(1000), uint64(30_000_000), uint64(1), uint64(1), uint64(1), uint64(0), uint64(0))
+	r.Event(threadPark, uint64(2000), uint64(20_000_000), uint64(1), uint64(1), uint64(0), uint64(0), uint64(0), uint64(0))
+
+	profiles, err := ParseJFR(r.Bytes(), nil, nil)
+	require.NoError(t, err)
+	type period struct {
+		typ, unit string
+		value     int64
+	}
+	periods := map[string]period{}
+	delays := map[string]int64{}
+	for _, p := range profiles.Profiles {
+		periods[p.Metric] = period{p.Profile.StringTable[p.Profile.PeriodType.Type], p.Profile.StringTable[p.Profile.PeriodType.Unit], p.Profile.Period}
+		for _, s := range p.Profile.Sample {
+			delays[p.Metric] += s.Value[1]
+		}
+	}
+	// the period is the threshold of the delays, in the same unit
+	assert.Equal(t, map[string]period{
+		"mutex": {"mutex", "nanoseconds", 10_000_000},
+		"block": {"block", "nanoseconds", 10_000_000},
+	}, periods)
+	assert.Equal(t, map[string]int64{"mutex": 30_000_000, "block": 20_000_000}, delays)
+}
+
+func TestParseVirtualThreads(t *testing.T) {
+	const (
+		executionSample     = 101
+		virtualThreadPinned = 133
+	)
+	r := jfrtest.New()
+	r.Class(executionSample, "jdk.ExecutionSample",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "sampledThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "state", Class: jfrtest.ThreadState, CPool: true})
+	r.Class(virtualThreadPinned, "jdk.VirtualThreadPinned",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "duration", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "blockingOperation", Class: jfrtest.String},
+		jfrtest.Field{Name: "pinnedReason", Class: jfrtest.String},
+		jfrtest.Field{Name: "carrierThread", Class: jfrtest.Thread, CPool: true})
+	r.Constant(jfrtest.Symbol, 1, "Handler")
+	r.Constant(jfrtest.Symbol, 10, "handle")
+	r.Constant(jfrtest.Class, 1, uint64(1))
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Thread, 1, "ForkJoinPool-1-worker-1", uint64(1), "ForkJoinPool-1-worker-1", uint64(1), false)
+	r.Constant(jfrtest.Thread, 2, "", uint64(0), "", uint64(2), true)
+	r.Constant(jfrtest.ThreadState, 1, "STATE_RUNNABLE")
+	r.StackTrace(1, 1)
+
+	r.Event(executionSample, uint64(1000), uint64(1), uint64(1), uint64(1))
+	r.Event(executionSample, uint64(2000), uint64(2), uint64(1), uint64(1))
+	r.Event(executionSample, uint64(3000), uint64(2), uint64(1), uint64(1))
+	// the blocking operation and the reason are only recorded since JDK 24
+	r.Event(virtualThreadPinned, uint64(4000), uint64(300), uint64(2), uint64(1), "Object.wait", "Native or VM frame on stack", uint64(1))
+	r.Event(virtualThreadPinned, uint64(5000), uint64(200), uint64(2), uint64(1), "Object.wait", "Native or VM frame on stack", uint64(1))
+	r.Event(virtualThreadPinned, uint64(6000), uint64(50), uint64(2), uint64(1), "", "", uint64(1))
+
+	profiles, err := ParseJFR(r.Bytes(), nil, nil)
+	require.NoError(t, err)
+	actual := labelledSamples(t, profiles)
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Handler.handle", "thread_kind=platform"}: {defaultCPUPeriod},
+		{"Handler.handle", "thread_kind=virtual"}:  {2 * defaultCPUPeriod},
+	}, actual["cpu"])
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Handler.handle;[pinned Object.wait]", "pinned_reason=Native or VM frame on stack"}: {2, 500},
+		{"Handler.handle;[pinned]", ""}: {1, 50},
+	}, actual["pinned_events"])
+	var metrics []string
+	for _, p := range profiles.Profiles {
+		metrics = append(metrics, p.Metric)
+	}
+	assert.Equal(t, []string{"process_cpu", "pinned"}, metrics)
+}
+
+func TestParseNativeMem(t *testing.T) {
+	const (
+		malloc = 140
+		free   = 141
+	)
+	r := jfrtest.New()
+	r.Class(malloc, "profiler.Malloc",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long},
+		jfrtest.Field{Name: "size", Class: jfrtest.Long})
+	r.Class(free, "profiler.Free",
+		jfrtest.Field{Name: "startTime", Class: jfrtest.Long},
+		jfrtest.Field{Name: "eventThread", Class: jfrtest.Thread, CPool: true},
+		jfrtest.Field{Name: "stackTrace", Class: jfrtest.StackTrace, CPool: true},
+		jfrtest.Field{Name: "address", Class: jfrtest.Long})
+	r.Constant(jfrtest.Symbol, 1, "Zstd")
+	r.Constant(jfrtest.Symbol, 10, "compress")
+	r.Constant(jfrtest.Symbol, 11, "decompress")
+	r.Constant(jfrtest.Class, 1, uint64(1))
+	r.Constant(jfrtest.Method, 1, uint64(1), uint64(10))
+	r.Constant(jfrtest.Method, 2, uint64(1), uint64(11))
+	r.Constant(jfrtest.Thread, 1, "main", uint64(1), "main", uint64(1), false)
+	r.StackTrace(1, 1)
+	r.StackTrace(2, 2)
+
+	r.Event(malloc, uint64(1000), uint64(1), uint64(1), uint64(0x1000), uint64(64))
+	r.Event(malloc, uint64(2000), uint64(1), uint64(1), uint64(0x2000), uint64(128))
+	r.Event(malloc, uint64(3000), uint64(1), uint64(2), uint64(0x3000), uint64(32))
+	// failed allocation
+	r.Event(malloc, uint64(4000), uint64(1), uint64(2), uint64(0), uint64(1<<20))
+	r.Event(free, uint64(5000), uint64(1), uint64(2), uint64(0x1000))
+	r.Event(free, uint64(6000), uint64(1), uint64(2), uint64(0x3000))
+	// allocated before the recording started
+	r.Event(free, uint64(7000), uint64(1), uint64(2), uint64(0x4000))
+	jfr := r.Bytes()
+
+	profiles, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	actual := labelledSamples(t, profiles)
+	assert.Equal(t, map[string]map[labelledStack][]int64{
+		"malloc_objects": {
+			{"Zstd.compress", ""}:   {2, 192},
+			{"Zstd.decompress", ""}: {1, 32},
+		},
+	}, actual)
+	assert.Equal(t, "nativemem", profiles.Profiles[0].Metric)
+
+	profiles, err = ParseJFR(jfr, &ParseInput{NativeMemLeaks: true}, nil)
+	require.NoError(t, err)
+	actual = labelledSamples(t, profiles)
+	assert.Equal(t, map[string]map[labelledStack][]int64{
+		"inuse_objects": {
+			{"Zstd.compress", ""}: {1, 128},
+		},
+	}, actual)
+}
+
+func TestParseLabelExtractor(t *testing.T) {
+	r := datadogRecording()
+	// endpoints are recorded when the root span ends, after its samples
+	r.Event(ddEndpoint, uint64(8000), uint64(1), "GET /users", uint64(3))
+	jfr := r.Bytes()
+
+	profiles, err := ParseJFR(jfr, nil, nil)
+	require.NoError(t, err)
+	actual := labelledSamples(t, profiles)
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Foo.bar", "span_id=5,local_root_span_id=3,trace_endpoint=GET /users"}: {3 * defaultCPUPeriod},
+		{"Foo.bar;Foo.baz", ""}: {defaultCPUPeriod},
+	}, actual["cpu"])
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Foo.bar;Foo.baz", "span_id=7,local_root_span_id=3,trace_endpoint=GET /users"}: {defaultWallPeriod},
+	}, actual["wall"])
+
+	var contexts []SampleContext
+	profiles, err = ParseJFR(jfr, &ParseInput{LabelExtractor: func(ctx SampleContext) Labels {
+		contexts = append(contexts, ctx)
+		return Labels{{Name: "endpoint", Value: ctx.Endpoint}}
+	}}, nil)
+	require.NoError(t, err)
+	actual = labelledSamples(t, profiles)
+	assert.Equal(t, map[labelledStack][]int64{
+		{"Foo.bar", "endpoint=GET /users"}: {3 * defaultCPUPeriod},
+		{"Foo.bar;Foo.baz", ""}:            {defaultCPUPeriod},
+	}, actual["cpu"])
+	assert.ElementsMatch(t, []SampleContext{
+		{SpanID: 5, LocalRootSpanID: 3, Endpoint: "GET /users"},
+		{SpanID: 7, LocalRootSpanID: 3, Endpoint: "GET /users"},
+	}, contexts)
+}
+
+type labelledStack struct {
+	stack  string
+	labels string
+}
+
+// labelledSamples returns the values of the samples of each sample type, by
+// stack and labels.
+func labelledSamples(t *testing.T, profiles *Profiles) map[string]map[labelledStack][]int64 {
+	res := map[string]map[labelledStack][]int64{}
+	for _, p := range profiles.Profiles {
+		typ := sampleTypeName(p.Profile)
+		res[typ] = map[labelledStack][]int64{}
+		collapsed := parseCollapsed(t, stackCollapseProto(p.Profile, false))
+		for _, s := range p.Profile.Sample {
+			var labels []string
+			for _, l := range s.Label {
+				labels = append(labels, p.Profile.StringTable[l.Key]+"="+p.Profile.StringTable[l.Str])
+			}
+			var funcs []string
+			for i := len(s.LocationId) - 1; i >= 0; i-- {
+				loc := p.Profile.Location[s.LocationId[i]-1]
+				funcs = append(funcs, p.Profile.StringTable[p.Profile.Function[loc.Line[0].FunctionId-1].Name])
+			}
+			stack := strings.Join(funcs, ";")
+			require.Contains(t, collapsed, stack)
+			res[typ][labelledStack{stack, strings.Join(labels, ",")}] = s.Value
+		}
+	}
+	return res
+}
@@ -1,8 +1,15 @@
 package pprof
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/grafana/jfr-parser/parser"
 	"github.com/grafana/jfr-parser/parser/types"
+	"github.com/grafana/jfr-parser/parser/types/def"
+	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
 )
 
 const (
@@ -13,61 +20,703 @@ const (
 	sampleTypeLock       = 4
 	sampleTypeThreadPark = 5
 	sampleTypeLiveObject = 6
+	sampleTypeAlloc      = 7
+	sampleTypeLiveHeap   = 8
+	sampleTypeException  = 9
+	sampleTypeFileIO     = 10
+	sampleTypeSocketIO   = 11
+	sampleTypeOffCPU     = 12
+	sampleTypePinned     = 13
+	sampleTypeNativeMem  = 14
 )
 
-func newJfrPprofBuilders(p *parser.Parser, jfrLabels *LabelsSnapshot, piOriginal *ParseInput) *jfrPprofBuilders {
-	st := piOriginal.StartTime.UnixNano()
-	et := piOriginal.EndTime.UnixNano()
-	var period int64
-	if piOriginal.SampleRate == 0 {
-		period = 0
-	} else {
-		period = 1e9 / int64(piOriginal.SampleRate)
+// spanLabelsID sets apart the labels IDs of dd-trace-java spans from the
+// async-profiler context IDs.
+const spanLabelsID = 1 << 63
+
+// throwableLabelsID sets apart the labels IDs of the thrown classes.
+const throwableLabelsID = 1 << 62
+
+// ioLabelsID sets apart the labels IDs of the files and remote addresses.
+const ioLabelsID = 1 << 61
+
+// waitLabelsID sets apart the labels IDs of the wait reasons of the offcpu
+// samples, which share their stack traces but not their leaf frame.
+const waitLabelsID = 1 << 60
+
+// virtualLabelsID marks the labels IDs of the samples of virtual threads, on
+// top of their context ID.
+const virtualLabelsID = 1 << 59
+
+// platformLabelsID marks the labels IDs of the samples of platform threads,
+// on top of their context ID, apart from the samples of unknown threads.
+const platformLabelsID = 1 << 57
+
+// pinnedLabelsID sets apart the labels IDs of the blocking operations and
+// reasons of the pinned samples.
+const pinnedLabelsID = 1 << 58
+
+// span is the dd-trace-java span a sample was taken in.
+type span struct {
+	spanID          uint64
+	localRootSpanID uint64
+}
+
+// ioTarget is the file or the remote address of an I/O event.
+type ioTarget struct {
+	socket bool
+	write  bool
+	// name is the path of files and the host:port of sockets
+	name string
+}
+
+// reason names the I/O as the leaf frame of offcpu samples.
+func (t ioTarget) reason() string {
+	kind, op := "file", "read"
+	if t.socket {
+		kind = "socket"
 	}
+	if t.write {
+		op = "write"
+	}
+	return "[" + kind + " " + op + " " + t.name + "]"
+}
 
+// wait is the reason and the context of an offcpu sample.
+type wait struct {
+	reason    string
+	contextID uint64
+}
+
+// pin is the blocking operation and the reason of a jdk.VirtualThreadPinned
+// event, only recorded since JDK 24.
+type pin struct {
+	operation string
+	reason    string
+}
+
+// leaf names the blocking operation as the leaf frame of pinned samples.
+func (p pin) leaf() string {
+	if p.operation == "" {
+		return "[pinned]"
+	}
+	return "[pinned " + p.operation + "]"
+}
+
+// nativeAlloc is an allocation of the nativemem leak profile not freed yet,
+// the sample it was added to and its size.
+type nativeAlloc struct {
+	sample *profilev1.Sample
+	size   int64
+}
+
+type labelledSample struct {
+	builder  *ProfileBuilder
+	sample   *profilev1.Sample
+	labelsID uint64
+}
+
+const (
+	// defaults of async-profiler when the interval is not recorded
+	defaultCPUPeriod  = 10_000_000
+	defaultWallPeriod = 50_000_000
+)
+
+func newJfrPprofBuilders(p *parser.Parser, jfrLabels *LabelsSnapshot, piOriginal *ParseInput) *jfrPprofBuilders {
 	res := &jfrPprofBuilders{
-		parser:        p,
-		builders:      make(map[int64]*ProfileBuilder),
-		jfrLabels:     jfrLabels,
-		timeNanos:     st,
-		durationNanos: et - st,
-		period:        period,
+		parser:    p,
+		builders:  make(map[builderKey]*ProfileBuilder),
+		jfrLabels: jfrLabels,
+		settings:  make(map[string]string),
+		spans:     make(map[span]uint64),
+		endpoints: make(map[uint64]string),
+
+		throwables:     make(map[string]uint64),
+		ioTargets:      make(map[ioTarget]uint64),
+		waits:          make(map[wait]uint64),
+		pins:           make(map[pin]uint64),
+		allocs:         make(map[uint64]nativeAlloc),
+		datadogPeriods: make(map[int64]int64),
+	}
+	if piOriginal != nil {
+		if !piOriginal.StartTime.IsZero() {
+			res.timeNanos = piOriginal.StartTime.UnixNano()
+		}
+		if !piOriginal.EndTime.IsZero() {
+			res.endNanos = piOriginal.EndTime.UnixNano()
+		}
+		res.sampleRate = piOriginal.SampleRate
+		res.bucketNanos = int64(piOriginal.BucketDuration)
+		res.timestamps = piOriginal.SampleTimestamps
+		res.labelExtractor = piOriginal.LabelExtractor
+		res.io = piOriginal.IO
+		res.ioIncludeRMI = piOriginal.IOIncludeRMI
+		res.offCPU = piOriginal.OffCPU
+		res.nativeMemLeaks = piOriginal.NativeMemLeaks
 	}
 	return res
 }
 
 type jfrPprofBuilders struct {
-	parser        *parser.Parser
-	builders      map[int64]*ProfileBuilder
-	jfrLabels     *LabelsSnapshot
-	timeNanos     int64
-	durationNanos int64
-	period        int64
+	parser    *parser.Parser
+	builders  map[builderKey]*ProfileBuilder
+	jfrLabels *LabelsSnapshot
+	spans     map[span]uint64
+	endpoints map[uint64]string
+	labelled  []labelledSample
+
+	// labels IDs of the thrown classes, by name
+	throwables map[string]uint64
+	// labels IDs of the files and remote addresses
+	ioTargets map[ioTarget]uint64
+	// labels IDs of the wait reasons, by context
+	waits map[wait]uint64
+	// labels IDs of the blocking operations of virtual threads
+	pins map[pin]uint64
+	// native allocations not freed yet, by address
+	allocs map[uint64]nativeAlloc
+
+	labelExtractor LabelExtractor
+
+	bucketNanos    int64
+	timestamps     bool
+	io             bool
+	ioIncludeRMI   bool
+	offCPU         bool
+	nativeMemLeaks bool
+
+	// overrides given by the caller, zero when the recording decides
+	timeNanos  int64
+	endNanos   int64
+	sampleRate int64
+
+	// what the recording tells about itself
+	recordingStart        int64
+	recordingEnd          int64
+	event                 string
+	settings              map[string]string
+	executionSamplePeriod int64
+	datadogPeriods        map[int64]int64
+}
+
+type builderKey struct {
+	sampleType int64
+	bucket     int64
+}
+
+// addChunk widens the time range of the recording to the chunk.
+func (b *jfrPprofBuilders) addChunk(h parser.ChunkHeader) {
+	start := int64(h.StartNanos)
+	end := start + int64(h.DurationNanos)
+	if b.recordingStart == 0 || start < b.recordingStart {
+		b.recordingStart = start
+	}
+	if end > b.recordingEnd {
+		b.recordingEnd = end
+	}
 }
 
-func (b *jfrPprofBuilders) addStacktrace(sampleType int64, contextID uint64, ref types.StackTraceRef, values []int64) {
-	p := b.profileBuilderForSampleType(sampleType)
+// addSetting records an active setting: the ones of async-profiler, such as
+// interval, wall, alloc and lock, the jdk.ExecutionSample period of JDK
+// recordings and the per event intervals of the Datadog profiler.
+func (b *jfrPprofBuilders) addSetting(s *types.ActiveSetting) {
+	if s.Name == "event" {
+		b.event = s.Value
+	}
+	if s.Name == "period" && def.TypeID(s.Id) == b.parser.TypeMap.T_EXECUTION_SAMPLE {
+		if period, ok := parseSettingDuration(s.Value); ok {
+			b.executionSamplePeriod = period
+		}
+	}
+	if sampleType, ok := b.datadogSampleType(def.TypeID(s.Id)); ok {
+		// every Datadog event has its own interval, they must not override
+		// each other in the settings
+		if s.Name == "interval" {
+			if v, err := strconv.ParseInt(s.Value, 10, 64); err == nil && v > 0 {
+				b.datadogPeriods[sampleType] = v
+			}
+		}
+		return
+	}
+	b.settings[s.Name] = s.Value
+}
+
+// datadogSampleType returns the sample type of a Datadog profiler event type.
+func (b *jfrPprofBuilders) datadogSampleType(id def.TypeID) (int64, bool) {
+	if id == 0 {
+		return 0, false
+	}
+	switch id {
+	case b.parser.TypeMap.T_DD_EXECUTION_SAMPLE:
+		return sampleTypeCPU, true
+	case b.parser.TypeMap.T_DD_METHOD_SAMPLE:
+		return sampleTypeWall, true
+	case b.parser.TypeMap.T_DD_OBJECT_SAMPLE:
+		return sampleTypeAlloc, true
+	case b.parser.TypeMap.T_DD_HEAP_LIVE_OBJECT:
+		return sampleTypeLiveHeap, true
+	}
+	return 0, false
+}
+
+// setting returns a positive numeric setting, or 0.
+func (b *jfrPprofBuilders) setting(name string) int64 {
+	v, err := strconv.ParseInt(b.settings[name], 10, 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// intervalIsTime reports whether the interval setting is in nanoseconds, which
+// is not the case for perf events counting cycles, cache misses...
+func (b *jfrPprofBuilders) intervalIsTime() bool {
+	switch b.event {
+	case "", "cpu", "itimer", "ctimer", "wall":
+		return true
+	}
+	return false
+}
+
+func (b *jfrPprofBuilders) period(sampleType int64) int64 {
+	switch sampleType {
+	case sampleTypeCPU:
+		if b.sampleRate > 0 {
+			return 1e9 / b.sampleRate
+		}
+		if b.event == "wall" {
+			// cpu samples are the running wall clock samples
+			return b.period(sampleTypeWall)
+		}
+		return firstPositive(b.datadogPeriods[sampleTypeCPU], b.setting("usedCpuInterval"), b.timeInterval(), b.executionSamplePeriod, defaultCPUPeriod)
+	case sampleTypeWall:
+		if b.sampleRate > 0 {
+			return 1e9 / b.sampleRate
+		}
+		var interval int64
+		if b.event == "wall" {
+			interval = b.timeInterval()
+		}
+		return firstPositive(b.datadogPeriods[sampleTypeWall], b.setting("usedWallInterval"), b.setting("wall"), interval, defaultWallPeriod)
+	case sampleTypeAlloc, sampleTypeLiveHeap:
+		return firstPositive(b.datadogPeriods[sampleType], b.setting("alloc"))
+	case sampleTypeInTLAB, sampleTypeOutTLAB:
+		return b.setting("alloc")
+	case sampleTypeLock, sampleTypeThreadPark:
+		// the lock threshold, in nanoseconds like the delays
+		return b.setting("lock")
+	case sampleTypeNativeMem:
+		return b.setting("nativemem")
+	}
+	return 0
+}
+
+func (b *jfrPprofBuilders) timeInterval() int64 {
+	if !b.intervalIsTime() {
+		return 0
+	}
+	return b.setting("interval")
+}
+
+func firstPositive(values ...int64) int64 {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// parseSettingDuration parses JDK setting durations such as "20 ms".
+func parseSettingDuration(s string) (int64, bool) {
+	n, unit, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(n, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	switch unit {
+	case "ns":
+		return v, true
+	case "us":
+		return v * int64(time.Microsecond), true
+	case "ms":
+		return v * int64(time.Millisecond), true
+	case "s":
+		return v * int64(time.Second), true
+	case "m":
+		return v * int64(time.Minute), true
+	case "h":
+		return v * int64(time.Hour), true
+	case "d":
+		return v * 24 * int64(time.Hour), true
+	}
+	return 0, false
+}
+
+func (b *jfrPprofBuilders) addStacktrace(sampleType int64, contextID uint64, ref types.StackTraceRef, startTicks uint64, values []int64) {
+	b.addSpanStacktrace(sampleType, contextID, span{}, ref, startTicks, values)
+}
+
+// addThreadStacktrace adds a sample labelled with thread_kind=virtual when
+// taken on a virtual thread, and thread_kind=platform on the other threads,
+// carriers included. The samples of unknown threads are left unlabelled.
+func (b *jfrPprofBuilders) addThreadStacktrace(sampleType int64, contextID uint64, thread types.ThreadRef, ref types.StackTraceRef, startTicks uint64, values []int64) {
+	t := b.parser.GetThread(thread)
+	if t == nil {
+		b.addStacktrace(sampleType, contextID, ref, startTicks, values)
+		return
+	}
+	kind, labelsID := "platform", contextID|platformLabelsID
+	if t.Virtual {
+		kind, labelsID = "virtual", contextID|virtualLabelsID
+	}
+	p, sample := b.addSample(sampleType, contextID, labelsID, ref, "", startTicks, values)
+	if sample == nil {
+		return
+	}
+	p.AddStringLabel(sample, "thread_kind", kind)
+	if contextID != 0 {
+		b.labelled = append(b.labelled, labelledSample{builder: p, sample: sample, labelsID: labelsID})
+	}
+}
+
+// addSpanStacktrace adds a sample labelled with the span of dd-trace-java
+// events, when not zero.
+func (b *jfrPprofBuilders) addSpanStacktrace(sampleType int64, contextID uint64, s span, ref types.StackTraceRef, startTicks uint64, values []int64) {
+	labelsID := contextID
+	if s != (span{}) {
+		id, ok := b.spans[s]
+		if !ok {
+			id = spanLabelsID | uint64(len(b.spans)+1)
+			b.spans[s] = id
+		}
+		labelsID = id
+	}
+	p, sample := b.addSample(sampleType, contextID, labelsID, ref, "", startTicks, values)
+	if sample != nil && labelsID != 0 {
+		// the endpoint of a span is only known once it ends, the labels are
+		// extracted in build
+		b.labelled = append(b.labelled, labelledSample{builder: p, sample: sample, labelsID: labelsID})
+	}
+}
+
+// addThrowable adds the throw site of a jdk.JavaExceptionThrow or
+// jdk.JavaErrorThrow event, with the thrown class as leaf frame and as
+// exception label.
+func (b *jfrPprofBuilders) addThrowable(ref types.StackTraceRef, class types.ClassRef, startTicks uint64, isError bool) {
 	st := b.parser.GetStacktrace(ref)
 	if st == nil {
 		return
 	}
+	if !isError && b.constructsError(st) {
+		// errors are recorded twice, as jdk.JavaErrorThrow and as the
+		// jdk.JavaExceptionThrow of the Throwable constructor
+		return
+	}
+	name := b.className(class)
+	labelsID, ok := b.throwables[name]
+	if !ok {
+		labelsID = throwableLabelsID | uint64(len(b.throwables)+1)
+		b.throwables[name] = labelsID
+	}
+	values := [1]int64{1}
+	p, sample := b.addSample(sampleTypeException, 0, labelsID, ref, name, startTicks, values[:])
+	if sample != nil && name != "" {
+		p.AddStringLabel(sample, "exception", strings.ReplaceAll(name, "/", "."))
+	}
+}
 
-	addValues := func(dst []int64) {
-		mul := 1
-		if sampleType == sampleTypeCPU || sampleType == sampleTypeWall {
-			mul = int(b.period)
+// ioAccepted reports whether the I/O of a stack trace goes to the file_io,
+// socket_io and offcpu profiles: they are enabled and, unless asked for, it is
+// not RMI traffic.
+func (b *jfrPprofBuilders) ioAccepted(target ioTarget, ref types.StackTraceRef) bool {
+	if !b.io && !b.offCPU {
+		return false
+	}
+	st := b.parser.GetStacktrace(ref)
+	if st == nil {
+		return false
+	}
+	return !target.socket || b.ioIncludeRMI || !b.rmiTransport(st, target.write)
+}
+
+// addIO adds the call site of a file or socket read or write, weighted by its
+// duration and bytes, with the file or the remote address as label.
+func (b *jfrPprofBuilders) addIO(target ioTarget, ref types.StackTraceRef, startTicks, durationTicks uint64, bytes int64) {
+	if !b.io {
+		return
+	}
+	sampleType := int64(sampleTypeFileIO)
+	if target.socket {
+		sampleType = sampleTypeSocketIO
+	}
+	labelsID, ok := b.ioTargets[target]
+	if !ok {
+		labelsID = ioLabelsID | uint64(len(b.ioTargets)+1)
+		b.ioTargets[target] = labelsID
+	}
+	if bytes < 0 {
+		// reads at the end of a file or a stream
+		bytes = 0
+	}
+	values := [3]int64{1, int64(b.parser.TicksToDuration(durationTicks)), bytes}
+	p, sample := b.addSample(sampleType, 0, labelsID, ref, "", startTicks, values[:])
+	if sample == nil {
+		return
+	}
+	if target.socket {
+		p.AddStringLabel(sample, "address", target.name)
+	} else {
+		p.AddStringLabel(sample, "path", target.name)
+	}
+	if target.write {
+		p.AddStringLabel(sample, "operation", "write")
+	} else {
+		p.AddStringLabel(sample, "operation", "read")
+	}
+}
+
+// addOffCPU adds the time a thread was blocked, weighted by duration, on top
+// of a leaf frame naming the reason.
+func (b *jfrPprofBuilders) addOffCPU(reason string, contextID uint64, ref types.StackTraceRef, startTicks, durationTicks uint64) {
+	if !b.offCPU {
+		return
+	}
+	w := wait{reason: reason, contextID: contextID}
+	labelsID, ok := b.waits[w]
+	if !ok {
+		labelsID = waitLabelsID | uint64(len(b.waits)+1)
+		b.waits[w] = labelsID
+	}
+	values := [2]int64{1, int64(b.parser.TicksToDuration(durationTicks))}
+	b.addSample(sampleTypeOffCPU, contextID, labelsID, ref, reason, startTicks, values[:])
+}
+
+// addPinned adds the time a virtual thread was pinned to its carrier thread,
+// weighted by duration, on top of a leaf frame naming the blocking operation.
+func (b *jfrPprofBuilders) addPinned(e *types.VirtualThreadPinned) {
+	pn := pin{operation: e.BlockingOperation, reason: e.PinnedReason}
+	labelsID, ok := b.pins[pn]
+	if !ok {
+		labelsID = pinnedLabelsID | uint64(len(b.pins)+1)
+		b.pins[pn] = labelsID
+	}
+	values := [2]int64{1, int64(b.parser.TicksToDuration(e.Duration))}
+	p, sample := b.addSample(sampleTypePinned, 0, labelsID, e.StackTrace, pn.leaf(), e.StartTime, values[:])
+	if sample != nil && pn.reason != "" {
+		p.AddStringLabel(sample, "pinned_reason", pn.reason)
+	}
+}
+
+// addMalloc adds a native allocation of async-profiler. In leak mode the
+// allocation is remembered by address, to be taken back from its sample when
+// freed.
+func (b *jfrPprofBuilders) addMalloc(e *types.Malloc) {
+	if e.Address == 0 {
+		// failed allocation
+		return
+	}
+	if b.nativeMemLeaks {
+		// the address was freed in between, but the free was not recorded
+		b.addFree(e.Address)
+	}
+	values := [2]int64{1, int64(e.Size)}
+	p, sample := b.addSample(sampleTypeNativeMem, 0, 0, e.StackTrace, "", e.StartTime, values[:])
+	if !b.nativeMemLeaks {
+		return
+	}
+	if sample == nil {
+		// merged with the sample of an earlier allocation of the same stack
+		sample = p.FindExternalSampleWithLabels(uint64(e.StackTrace), 0)
+	}
+	if sample != nil {
+		b.allocs[e.Address] = nativeAlloc{sample: sample, size: int64(e.Size)}
+	}
+}
+
+// addFree takes a freed allocation back from the nativemem leak profile. The
+// addresses allocated before the recording started are ignored.
+func (b *jfrPprofBuilders) addFree(address uint64) {
+	if !b.nativeMemLeaks {
+		return
+	}
+	alloc, ok := b.allocs[address]
+	if !ok {
+		return
+	}
+	alloc.sample.Value[0]--
+	alloc.sample.Value[1] -= alloc.size
+	delete(b.allocs, address)
+}
+
+// classReason names the class of a monitor or of a loaded class as the leaf
+// frame of offcpu samples, such as [monitor com.foo.Lock].
+func (b *jfrPprofBuilders) classReason(kind string, class types.ClassRef) string {
+	name := b.className(class)
+	if name == "" {
+		return "[" + kind + "]"
+	}
+	return "[" + kind + " " + strings.ReplaceAll(name, "/", ".") + "]"
+}
+
+// rmiFrame is a method of the RMI transport threads.
+type rmiFrame struct {
+	class  string
+	method string
+}
+
+// rmiReads and rmiWrites are the frames the NoRmiSocketRead and
+// NoRmiSocketWrite filters of the common/filters package exclude.
+var (
+	rmiReads = []rmiFrame{
+		{"sun/rmi/transport/tcp/TCPTransport", "handleMessages"},
+		{"javax/management/remote/rmi/RMIConnector$RMINotifClient", "fetchNotifs"},
+	}
+	rmiWrites = []rmiFrame{
+		{"sun/rmi/transport/tcp/TCPTransport$ConnectionHandler", "run"},
+		{"sun/rmi/transport/tcp/TCPTransport$ConnectionHandler", "run0"},
+	}
+)
+
+// rmiTransport reports whether a socket read or write is RMI traffic.
+func (b *jfrPprofBuilders) rmiTransport(st *types.StackTrace, write bool) bool {
+	frames := rmiReads
+	if write {
+		frames = rmiWrites
+	}
+	for _, f := range st.Frames {
+		m := b.parser.GetMethod(f.Method)
+		if m == nil {
+			continue
+		}
+		method := b.parser.GetSymbolString(m.Name)
+		for _, frame := range frames {
+			if method == frame.method && strings.ReplaceAll(b.className(m.Type), ".", "/") == frame.class {
+				return true
+			}
 		}
+	}
+	return false
+}
+
+// constructsError reports whether a stack trace starts in the constructor of
+// java.lang.Error.
+func (b *jfrPprofBuilders) constructsError(st *types.StackTrace) bool {
+	for _, f := range st.Frames {
+		m := b.parser.GetMethod(f.Method)
+		if m == nil || b.parser.GetSymbolString(m.Name) != "<init>" {
+			return false
+		}
+		if b.className(m.Type) == "java/lang/Error" {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *jfrPprofBuilders) className(ref types.ClassRef) string {
+	cls := b.parser.GetClass(ref)
+	if cls == nil {
+		return ""
+	}
+	return b.parser.GetSymbolString(cls.Name)
+}
+
+// addSample adds the values to the sample of a stack trace, on top of a leaf
+// frame when not empty. The sample is returned when it is new.
+func (b *jfrPprofBuilders) addSample(sampleType int64, contextID, labelsID uint64, ref types.StackTraceRef, leaf string, startTicks uint64, values []int64) (*ProfileBuilder, *profilev1.Sample) {
+	var ts, bucket int64
+	if b.bucketNanos > 0 || b.timestamps {
+		ts = b.parser.TicksToTime(startTicks).UnixNano()
+		bucket = b.bucket(ts)
+	}
+	p := b.profileBuilderFor(builderKey{sampleType: sampleType, bucket: bucket})
+	st := b.parser.GetStacktrace(ref)
+	if st == nil {
+		return p, nil
+	}
+
+	// cpu and wall values are counts until build scales them by the period,
+	// which may only be known once all settings are read
+	addValues := func(dst []int64) {
 		for i, value := range values {
-			dst[i] += value * int64(mul)
+			dst[i] += value
 		}
 	}
 
-	sample := p.FindExternalSampleWithLabels(uint64(ref), contextID)
-	if sample != nil {
+	sample := p.FindExternalSampleWithLabels(uint64(ref), labelsID)
+	if sample != nil && !b.timestamps {
 		addValues(sample.Value)
+		return p, nil
+	}
+
+	var locations []uint64
+	if sample != nil {
+		locations = sample.LocationId
+	} else {
+		locations = b.locations(p, st)
+		if leaf != "" {
+			locations = append([]uint64{uint64(p.AddLocation(leaf))}, locations...)
+		}
+	}
+	vs := make([]int64, len(values))
+	addValues(vs)
+	n := len(p.Sample)
+	if b.timestamps {
+		p.AddExternalSampleWithTimestamp(locations, vs, b.contextLabels(contextID), b.jfrLabels, uint64(ref), labelsID, ts)
+	} else {
+		p.AddExternalSampleWithLabels(locations, vs, b.contextLabels(contextID), b.jfrLabels, uint64(ref), labelsID)
+	}
+	if len(p.Sample) > n {
+		return p, p.Sample[n]
+	}
+	return p, nil
+}
+
+func (b *jfrPprofBuilders) addEndpoint(e *types.DatadogEndpoint) {
+	if e.LocalRootSpanId != 0 && e.Endpoint != "" {
+		b.endpoints[e.LocalRootSpanId] = e.Endpoint
+	}
+}
+
+// extractLabels labels the samples taken in a tracing context.
+func (b *jfrPprofBuilders) extractLabels() {
+	if len(b.labelled) == 0 {
 		return
 	}
+	extract := b.labelExtractor
+	if extract == nil {
+		extract = TraceLabels
+	}
+	contexts := make(map[uint64]SampleContext, len(b.spans))
+	for s, id := range b.spans {
+		contexts[id] = SampleContext{
+			SpanID:          s.spanID,
+			LocalRootSpanID: s.localRootSpanID,
+			Endpoint:        b.endpoints[s.localRootSpanID],
+		}
+	}
+	labels := make(map[uint64]Labels)
+	for _, l := range b.labelled {
+		ls, ok := labels[l.labelsID]
+		if !ok {
+			ctx, ok := contexts[l.labelsID]
+			if !ok {
+				ctx = SampleContext{ContextID: l.labelsID &^ (virtualLabelsID | platformLabelsID)}
+			}
+			ls = extract(ctx)
+			labels[l.labelsID] = ls
+		}
+		for _, label := range ls {
+			l.builder.AddStringLabel(l.sample, label.Name, label.Value)
+		}
+	}
+}
 
+func (b *jfrPprofBuilders) locations(p *ProfileBuilder, st *types.StackTrace) []uint64 {
 	locations := make([]uint64, 0, len(st.Frames))
 	for i := 0; i < len(st.Frames); i++ {
 		f := st.Frames[i]
@@ -102,17 +751,42 @@ func (b *jfrPprofBuilders) addStacktrace(sampleType int64, contextID uint64, ref
 			//todo remove Scratch field from the Method
 		}
 	}
-	vs := make([]int64, len(values))
-	addValues(vs)
-	p.AddExternalSampleWithLabels(locations, vs, b.contextLabels(contextID), b.jfrLabels, uint64(ref), contextID)
+	return locations
 }
 
-func (b *jfrPprofBuilders) profileBuilderForSampleType(sampleType int64) *ProfileBuilder {
-	if builder, ok := b.builders[sampleType]; ok {
+// origin is the start of the first window.
+func (b *jfrPprofBuilders) origin() int64 {
+	if b.timeNanos != 0 {
+		return b.timeNanos
+	}
+	return b.recordingStart
+}
+
+func (b *jfrPprofBuilders) bucket(ts int64) int64 {
+	if b.bucketNanos <= 0 {
+		return 0
+	}
+	d := ts - b.origin()
+	bucket := d / b.bucketNanos
+	if d < 0 && d%b.bucketNanos != 0 {
+		bucket--
+	}
+	return bucket
+}
+
+// newChunk drops the caches keyed by constant pool references of the previous chunk.
+func (b *jfrPprofBuilders) newChunk() {
+	for _, builder := range b.builders {
+		builder.ResetExternalIDs()
+	}
+}
+
+func (b *jfrPprofBuilders) profileBuilderFor(key builderKey) *ProfileBuilder {
+	if builder, ok := b.builders[key]; ok {
 		return builder
 	}
-	builder := NewProfileBuilderWithLabels(b.timeNanos)
-	builder.DurationNanos = b.durationNanos
+	sampleType := key.sampleType
+	builder := NewProfileBuilderWithLabels(0)
 	var metric string
 	switch sampleType {
 	case sampleTypeCPU:
@@ -136,23 +810,81 @@ func (b *jfrPprofBuilders) profileBuilderForSampleType(sampleType int64) *Profil
 	case sampleTypeLock:
 		builder.AddSampleType("contentions", "count")
 		builder.AddSampleType("delay", "nanoseconds")
-		builder.PeriodType("mutex", "count")
+		builder.PeriodType("mutex", "nanoseconds")
 		metric = "mutex"
 	case sampleTypeThreadPark:
 		builder.AddSampleType("contentions", "count")
 		builder.AddSampleType("delay", "nanoseconds")
-		builder.PeriodType("block", "count")
+		builder.PeriodType("block", "nanoseconds")
 		metric = "block"
 	case sampleTypeLiveObject:
 		builder.AddSampleType("live", "count")
 		builder.PeriodType("objects", "count")
 		metric = "memory"
+	case sampleTypeAlloc:
+		builder.AddSampleType("alloc_objects", "count")
+		builder.AddSampleType("alloc_space", "bytes")
+		builder.PeriodType("space", "bytes")
+		metric = "memory"
+	case sampleTypeLiveHeap:
+		builder.AddSampleType("inuse_objects", "count")
+		builder.AddSampleType("inuse_space", "bytes")
+		builder.PeriodType("space", "bytes")
+		metric = "memory"
+	case sampleTypeException:
+		builder.AddSampleType("exceptions", "count")
+		builder.PeriodType("exceptions", "count")
+		metric = "exceptions"
+	case sampleTypeFileIO:
+		builder.AddSampleType("file_io_events", "count")
+		builder.AddSampleType("file_io_duration", "nanoseconds")
+		builder.AddSampleType("file_io_bytes", "bytes")
+		builder.PeriodType("file_io", "nanoseconds")
+		metric = "file_io"
+	case sampleTypeSocketIO:
+		builder.AddSampleType("socket_io_events", "count")
+		builder.AddSampleType("socket_io_duration", "nanoseconds")
+		builder.AddSampleType("socket_io_bytes", "bytes")
+		builder.PeriodType("socket_io", "nanoseconds")
+		metric = "socket_io"
+	case sampleTypeOffCPU:
+		builder.AddSampleType("offcpu_events", "count")
+		builder.AddSampleType("offcpu", "nanoseconds")
+		builder.PeriodType("offcpu", "nanoseconds")
+		metric = "offcpu"
+	case sampleTypePinned:
+		builder.AddSampleType("pinned_events", "count")
+		builder.AddSampleType("pinned", "nanoseconds")
+		builder.PeriodType("pinned", "nanoseconds")
+		metric = "pinned"
+	case sampleTypeNativeMem:
+		if b.nativeMemLeaks {
+			builder.AddSampleType("inuse_objects", "count")
+			builder.AddSampleType("inuse_space", "bytes")
+		} else {
+			builder.AddSampleType("malloc_objects", "count")
+			builder.AddSampleType("malloc_space", "bytes")
+		}
+		builder.PeriodType("space", "bytes")
+		metric = "nativemem"
 	}
 	builder.MetricName(metric)
-	b.builders[sampleType] = builder
+	b.builders[key] = builder
 	return builder
 }
 
+// dropFreed removes the samples of the nativemem leak profile whose
+// allocations were all freed.
+func dropFreed(builder *ProfileBuilder) {
+	samples := builder.Sample[:0]
+	for _, sample := range builder.Sample {
+		if sample.Value[0] != 0 {
+			samples = append(samples, sample)
+		}
+	}
+	builder.Sample = samples
+}
+
 func (b *jfrPprofBuilders) contextLabels(contextID uint64) *Context {
 	if b.jfrLabels == nil {
 		return nil
@@ -161,8 +893,42 @@ func (b *jfrPprofBuilders) contextLabels(contextID uint64) *Context {
 }
 
 func (b *jfrPprofBuilders) build(jfrEvent string) *Profiles {
+	b.extractLabels()
+	start, end := b.origin(), b.recordingEnd
+	if b.endNanos != 0 {
+		end = b.endNanos
+	}
+	keys := make([]builderKey, 0, len(b.builders))
+	for key := range b.builders {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].bucket != keys[j].bucket {
+			return keys[i].bucket < keys[j].bucket
+		}
+		return keys[i].sampleType < keys[j].sampleType
+	})
 	profiles := make([]Profile, 0, len(b.builders))
-	for _, builder := range b.builders {
+	for _, key := range keys {
+		builder := b.builders[key]
+		if b.bucketNanos > 0 {
+			builder.TimeNanos = start + key.bucket*b.bucketNanos
+			builder.DurationNanos = b.bucketNanos
+		} else {
+			builder.TimeNanos = start
+			builder.DurationNanos = end - start
+		}
+		builder.Period = b.period(key.sampleType)
+		if key.sampleType == sampleTypeCPU || key.sampleType == sampleTypeWall {
+			for _, sample := range builder.Sample {
+				for i := range sample.Value {
+					sample.Value[i] *= builder.Period
+				}
+			}
+		}
+		if key.sampleType == sampleTypeNativeMem && b.nativeMemLeaks {
+			dropFreed(builder)
+		}
 		profiles = append(profiles, Profile{
 			Profile: builder.Profile,
 			Metric:  builder.metricName,
@@ -1,15 +1,24 @@
 package pprof
 
 import (
+	"encoding/binary"
+
 	profilev1 "github.com/grafana/pyroscope/api/gen/proto/go/google/v1"
 )
 
 type ProfileBuilder struct {
 	*profilev1.Profile
-	strings                       map[string]int
+	strings map[string]int
+
+	// External IDs are constant pool references, only meaningful within a
+	// chunk, see ResetExternalIDs. Functions, locations and samples are
+	// interned by content so that chunks share them.
 	externalLocationID2LocationID map[ExternalLocationID]PPROFLocationID
 	externalFunctionID2FunctionID map[ExternalFunctionID]PPROFFunctionID
 	externalSampleID2SampleIndex  map[sampleID]uint32
+	functions                     map[int64]PPROFFunctionID
+	locations                     map[locationKey]PPROFLocationID
+	samples                       map[sampleKey]uint32
 	metricName                    string
 }
 
@@ -18,6 +27,16 @@ type sampleID struct {
 	labelsID    uint64
 }
 
+type locationKey struct {
+	function PPROFFunctionID
+	line     uint32
+}
+
+type sampleKey struct {
+	locations string
+	labelsID  uint64
+}
+
 // NewProfileBuilderWithLabels creates a new ProfileBuilder with the given nanoseconds timestamp and labels.
 func NewProfileBuilderWithLabels(ts int64) *ProfileBuilder {
 	profile := new(profilev1.Profile)
@@ -30,6 +49,9 @@ func NewProfileBuilderWithLabels(ts int64) *ProfileBuilder {
 		strings:                       map[string]int{},
 		externalFunctionID2FunctionID: map[ExternalFunctionID]PPROFFunctionID{},
 		externalLocationID2LocationID: map[ExternalLocationID]PPROFLocationID{},
+		functions:                     map[int64]PPROFFunctionID{},
+		locations:                     map[locationKey]PPROFLocationID{},
+		samples:                       map[sampleKey]uint32{},
 	}
 	p.addString("")
 	return p
@@ -71,6 +93,15 @@ func (m *ProfileBuilder) addString(s string) int64 {
 	return int64(i)
 }
 
+// ResetExternalIDs forgets the external IDs of functions, locations and
+// samples. It must be called whenever a new chunk starts, as constant pool
+// references of different chunks are unrelated.
+func (m *ProfileBuilder) ResetExternalIDs() {
+	m.externalLocationID2LocationID = map[ExternalLocationID]PPROFLocationID{}
+	m.externalFunctionID2FunctionID = map[ExternalFunctionID]PPROFFunctionID{}
+	m.externalSampleID2SampleIndex = nil
+}
+
 func (m *ProfileBuilder) FindLocationByExternalID(externalLocationID ExternalLocationID) (PPROFLocationID, bool) {
 	loc, ok := m.externalLocationID2LocationID[externalLocationID]
 	return loc, ok
@@ -82,28 +113,52 @@ func (m *ProfileBuilder) FindFunctionByExternalID(externalFunctionID ExternalFun
 }
 
 func (m *ProfileBuilder) AddExternalFunction(frame string, id ExternalFunctionID) PPROFFunctionID {
-	fname := m.addString(frame)
-	funcID := uint64(len(m.Function)) + 1
-	m.Function = append(m.Function, &profilev1.Function{
-		Id:   funcID,
-		Name: fname,
-	})
-	ret := PPROFFunctionID(funcID)
+	ret := m.function(frame)
 	m.externalFunctionID2FunctionID[id] = ret
 	return ret
 }
 
 func (m *ProfileBuilder) AddExternalLocation(id ExternalLocationID, pprofFunctionID PPROFFunctionID) PPROFLocationID {
-	locID := uint64(len(m.Location)) + 1
-	m.Location = append(m.Location, &profilev1.Location{
-		Id:        locID,
-		MappingId: uint64(1),
-		Line:      []*profilev1.Line{{FunctionId: uint64(pprofFunctionID), Line: int64(id.Line)}},
-	})
-	ret := PPROFLocationID(locID)
+	ret := m.location(pprofFunctionID, id.Line)
 	m.externalLocationID2LocationID[id] = ret
 	return ret
+}
+
+// AddLocation returns the location of a frame which is not a method of the
+// recording, such as the class of a thrown exception.
+func (m *ProfileBuilder) AddLocation(frame string) PPROFLocationID {
+	return m.location(m.function(frame), 0)
+}
 
+func (m *ProfileBuilder) function(frame string) PPROFFunctionID {
+	fname := m.addString(frame)
+	ret, ok := m.functions[fname]
+	if !ok {
+		funcID := uint64(len(m.Function)) + 1
+		m.Function = append(m.Function, &profilev1.Function{
+			Id:   funcID,
+			Name: fname,
+		})
+		ret = PPROFFunctionID(funcID)
+		m.functions[fname] = ret
+	}
+	return ret
+}
+
+func (m *ProfileBuilder) location(pprofFunctionID PPROFFunctionID, line uint32) PPROFLocationID {
+	key := locationKey{function: pprofFunctionID, line: line}
+	ret, ok := m.locations[key]
+	if !ok {
+		locID := uint64(len(m.Location)) + 1
+		m.Location = append(m.Location, &profilev1.Location{
+			Id:        locID,
+			MappingId: uint64(1),
+			Line:      []*profilev1.Line{{FunctionId: uint64(pprofFunctionID), Line: int64(line)}},
+		})
+		ret = PPROFLocationID(locID)
+		m.locations[key] = ret
+	}
+	return ret
 }
 
 func (m *ProfileBuilder) AddExternalSample(locs []uint64, values []int64, externalSampleID uint32) {
@@ -114,15 +169,57 @@ func (m *ProfileBuilder) FindExternalSample(externalSampleID uint32) *profilev1.
 	return m.FindExternalSampleWithLabels(uint64(externalSampleID), 0)
 }
 
+// AddExternalSampleWithLabels adds a sample, or adds the values to an existing
+// sample with the same locations and labels.
 func (m *ProfileBuilder) AddExternalSampleWithLabels(locs []uint64, values []int64, labelsCtx *Context, labelsSnapshot *LabelsSnapshot, locationsID, labelsID uint64) {
-	sample := &profilev1.Sample{
-		LocationId: locs,
-		Value:      values,
+	if m.samples == nil {
+		m.samples = map[sampleKey]uint32{}
+	}
+	key := sampleKey{locations: locationsKey(locs), labelsID: labelsID}
+	if sampleIndex, ok := m.samples[key]; ok {
+		sample := m.Profile.Sample[sampleIndex]
+		for i, value := range values {
+			sample.Value[i] += value
+		}
+		m.setExternalSample(locationsID, labelsID, sampleIndex)
+		return
 	}
+	m.samples[key] = uint32(len(m.Profile.Sample))
+	m.addSample(locs, values, labelsCtx, labelsSnapshot, locationsID, labelsID)
+}
+
+// AddExternalSampleWithTimestamp adds a sample which is never merged with
+// others, labelled with the event time in nanoseconds since epoch.
+func (m *ProfileBuilder) AddExternalSampleWithTimestamp(locs []uint64, values []int64, labelsCtx *Context, labelsSnapshot *LabelsSnapshot, locationsID, labelsID uint64, timestampNanos int64) {
+	sample := m.addSample(locs, values, labelsCtx, labelsSnapshot, locationsID, labelsID)
+	sample.Label = append(sample.Label, &profilev1.Label{
+		Key:     m.addString("timestamp"),
+		Num:     timestampNanos,
+		NumUnit: m.addString("nanoseconds"),
+	})
+}
+
+// AddStringLabel labels a sample of the profile.
+func (m *ProfileBuilder) AddStringLabel(sample *profilev1.Sample, key, value string) {
+	sample.Label = append(sample.Label, &profilev1.Label{
+		Key: m.addString(key),
+		Str: m.addString(value),
+	})
+}
+
+func (m *ProfileBuilder) setExternalSample(locationsID, labelsID uint64, sampleIndex uint32) {
 	if m.externalSampleID2SampleIndex == nil {
 		m.externalSampleID2SampleIndex = map[sampleID]uint32{}
 	}
-	m.externalSampleID2SampleIndex[sampleID{locationsID: locationsID, labelsID: labelsID}] = uint32(len(m.Profile.Sample))
+	m.externalSampleID2SampleIndex[sampleID{locationsID: locationsID, labelsID: labelsID}] = sampleIndex
+}
+
+func (m *ProfileBuilder) addSample(locs []uint64, values []int64, labelsCtx *Context, labelsSnapshot *LabelsSnapshot, locationsID, labelsID uint64) *profilev1.Sample {
+	sample := &profilev1.Sample{
+		LocationId: locs,
+		Value:      values,
+	}
+	m.setExternalSample(locationsID, labelsID, uint32(len(m.Profile.Sample)))
 	m.Profile.Sample = append(m.Profile.Sample, sample)
 	if labelsCtx != nil && labelsSnapshot != nil {
 		sample.Label = make([]*profilev1.Label, 0, len(labelsCtx.Labels))
@@ -133,6 +230,15 @@ func (m *ProfileBuilder) AddExternalSampleWithLabels(locs []uint64, values []int
 			})
 		}
 	}
+	return sample
+}
+
+func locationsKey(locs []uint64) string {
+	buf := make([]byte, 0, len(locs)*2)
+	for _, loc := range locs {
+		buf = binary.AppendUvarint(buf, loc)
+	}
+	return string(buf)
 }
 
 func (m *ProfileBuilder) FindExternalSampleWithLabels(locationsID, labelsID uint64) *profilev1.Sample {
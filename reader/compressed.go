@@ -24,7 +24,7 @@ func (c compressed) VarShort() (int16, error) {
 	if err != nil {
 		return 0, err
 	}
-	if (n >> 48) > 0 {
+	if (n >> 16) > 0 {
 		return 0, fmt.Errorf("overflow: %d bigger than 16 bits", n)
 	}
 	return int16(n), nil
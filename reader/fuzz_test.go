@@ -0,0 +1,35 @@
+package reader
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzCompressed(f *testing.F) {
+	f.Add([]byte{0})
+	f.Add([]byte{0x7f})
+	f.Add([]byte{0xff, 0x01})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0x0f})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		long, errLong := NewCompressed(bytes.NewReader(data)).VarLong()
+		i, err := NewCompressed(bytes.NewReader(data)).VarInt()
+		if err == nil && (errLong != nil || int64(uint32(i)) != long) {
+			t.Fatalf("VarInt %d disagrees with VarLong %d (%v)", i, long, errLong)
+		}
+		s, err := NewCompressed(bytes.NewReader(data)).VarShort()
+		if err == nil && (errLong != nil || int64(uint16(s)) != long) {
+			t.Fatalf("VarShort %d disagrees with VarLong %d (%v)", s, long, errLong)
+		}
+	})
+}
+
+func FuzzUncompressed(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 0, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := NewUncompressed(bytes.NewReader(data))
+		_, _ = r.VarShort()
+		_, _ = r.VarInt()
+		_, _ = r.VarLong()
+	})
+}